@@ -0,0 +1,50 @@
+package client
+
+import (
+	"context"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// ActorAttributeKey is the span attribute holding the user or service account that started the trace
+const ActorAttributeKey = attribute.Key("kubetracer.actor")
+
+// actorKey is the context key holding the actor of the current trace
+type actorKey struct{}
+
+// contextWithActorFromObject stores the actor annotation of obj, written at admission, in ctx so that
+// every downstream span is attributed to the same user or service account
+func contextWithActorFromObject(ctx context.Context, obj client.Object, keys *annotationKeys) context.Context {
+	actor := obj.GetAnnotations()[keys.actor]
+	if actor == "" {
+		return ctx
+	}
+	return context.WithValue(ctx, actorKey{}, actor)
+}
+
+// actorSpanOptions returns the span options attributing a span to the actor in ctx, if any
+func actorSpanOptions(ctx context.Context) []trace.SpanStartOption {
+	actor, ok := ctx.Value(actorKey{}).(string)
+	if !ok {
+		return nil
+	}
+	return []trace.SpanStartOption{trace.WithAttributes(ActorAttributeKey.String(actor))}
+}
+
+// addActorAnnotation carries the actor in ctx over to obj, so that the controllers reconciling obj
+// attribute their spans to it as well
+func addActorAnnotation(ctx context.Context, obj client.Object, keys *annotationKeys) {
+	actor, ok := ctx.Value(actorKey{}).(string)
+	if !ok {
+		return
+	}
+
+	if obj.GetAnnotations() == nil {
+		obj.SetAnnotations(map[string]string{})
+	}
+	annotations := obj.GetAnnotations()
+	annotations[keys.actor] = actor
+	obj.SetAnnotations(annotations)
+}
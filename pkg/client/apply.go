@@ -0,0 +1,104 @@
+package client
+
+import (
+	"context"
+	"fmt"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/apiutil"
+)
+
+// TraceFieldManager is the field manager owning the trace annotations written on server-side apply
+const TraceFieldManager = "kubetracer"
+
+const (
+	// ApplyFieldManagerKey is the span attribute holding the field manager of a server-side apply
+	ApplyFieldManagerKey = attribute.Key("kubetracer.apply.field_manager")
+	// ApplyForceKey is the span attribute holding whether a server-side apply forces ownership
+	ApplyForceKey = attribute.Key("kubetracer.apply.force")
+)
+
+// isApply reports whether patch is a server-side apply
+func isApply(patch client.Patch) bool {
+	return patch.Type() == types.ApplyPatchType
+}
+
+// recordApplyOptions sets the field manager and force flag of a server-side apply on the span
+func recordApplyOptions(span trace.Span, opts []client.PatchOption) {
+	patchOpts := (&client.PatchOptions{}).ApplyOptions(opts)
+	if patchOpts.FieldManager != "" {
+		span.SetAttributes(ApplyFieldManagerKey.String(patchOpts.FieldManager))
+	}
+	span.SetAttributes(ApplyForceKey.Bool(patchOpts.Force != nil && *patchOpts.Force))
+}
+
+// applyTraceMetadata applies the trace annotations of the span in ctx to obj as TraceFieldManager.  Writing them
+// into the applied configuration of the caller would make its field manager own them, so that they are removed
+// again by its next apply, or conflict with EndTrace; a dedicated field manager leaves the ownership of the
+// caller's fields untouched.  obj is updated with the annotations and resourceVersion of the response, only the
+// latter with copy-on-write.
+func (tc *tracingClient) applyTraceMetadata(ctx context.Context, obj client.Object, writeOpts writeOptions) error {
+	gvk, err := apiutil.GVKForObject(obj, tc.scheme)
+	if err != nil {
+		return fmt.Errorf("problem getting the scheme: %w", err)
+	}
+
+	applied := &unstructured.Unstructured{}
+	applied.SetGroupVersionKind(gvk)
+	applied.SetNamespace(obj.GetNamespace())
+	applied.SetName(obj.GetName())
+	keys := tc.propagation.annotationKeys()
+	if lastOps, ok := obj.GetAnnotations()[keys.lastOps]; ok {
+		applied.SetAnnotations(map[string]string{keys.lastOps: lastOps})
+	}
+
+	addTraceIDAnnotation(ctx, applied, tc.propagation)
+	if writeOpts.traceLabel {
+		addTraceLabel(ctx, applied)
+	}
+	tc.lastOps.add(ctx, "patch", applied, keys)
+	if len(applied.GetAnnotations()) == 0 && len(applied.GetLabels()) == 0 {
+		return nil
+	}
+
+	if err := tc.Client.Patch(ctx, applied, client.Apply, client.FieldOwner(TraceFieldManager), client.ForceOwnership); err != nil {
+		return fmt.Errorf("problem applying the trace annotations: %w", err)
+	}
+
+	obj.SetResourceVersion(applied.GetResourceVersion())
+	if tc.copyOnWrite {
+		return nil
+	}
+	annotations := obj.GetAnnotations()
+	if annotations == nil {
+		annotations = map[string]string{}
+	}
+	for key, value := range applied.GetAnnotations() {
+		annotations[key] = value
+	}
+	obj.SetAnnotations(annotations)
+	return nil
+}
+
+// apply is patch for a server-side apply.  The applied configuration of the caller is sent as is, and the trace
+// annotations are applied by TraceFieldManager once it succeeded, if the policy writes the trace to obj.
+func (tc *tracingClient) apply(ctx context.Context, parent, span trace.Span, kind string, obj client.Object, patch client.Patch, writeOpts writeOptions, opts ...client.PatchOption) error {
+	recordApplyOptions(span, opts)
+	recordPatch(span, patch, obj)
+
+	tc.Logger.Info("Applying object", "object", obj.GetName())
+	defer keepMetadataGVK(obj)()
+	err := tc.Client.Patch(ctx, obj, patch, opts...)
+	if err == nil && !writeOpts.dryRun && tc.policy.writes(obj) {
+		err = tc.applyTraceMetadata(ctx, obj, writeOpts)
+	}
+	if err != nil {
+		tc.stackTraces.recordError(span, err)
+		tc.recordConflict(ctx, parent, span, "Patch", kind, obj, err)
+	}
+	return err
+}
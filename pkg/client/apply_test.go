@@ -0,0 +1,65 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/go-logr/logr"
+	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/otel/attribute"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/client/interceptor"
+)
+
+func TestServerSideApply(t *testing.T) {
+	type applied struct {
+		fieldManager string
+		force        bool
+		annotations  map[string]string
+	}
+	applies := []applied{}
+	k8sClient := fake.NewClientBuilder().WithInterceptorFuncs(interceptor.Funcs{
+		Patch: func(ctx context.Context, c client.WithWatch, obj client.Object, patch client.Patch, opts ...client.PatchOption) error {
+			assert.Equal(t, types.ApplyPatchType, patch.Type())
+			data, err := patch.Data(obj)
+			assert.NoError(t, err)
+			body := struct {
+				Metadata metav1.ObjectMeta `json:"metadata"`
+			}{}
+			assert.NoError(t, json.Unmarshal(data, &body))
+			patchOpts := (&client.PatchOptions{}).ApplyOptions(opts)
+			applies = append(applies, applied{fieldManager: patchOpts.FieldManager, force: patchOpts.Force != nil && *patchOpts.Force, annotations: body.Metadata.Annotations})
+			return nil
+		},
+	}).Build()
+	recorder := tracetest.NewSpanRecorder()
+	tracer := sdktrace.NewTracerProvider(sdktrace.WithSampler(sdktrace.AlwaysSample()), sdktrace.WithSpanProcessor(recorder)).Tracer("kubetracer")
+	tracingClient := NewTracingClient(k8sClient, k8sClient, tracer, logr.Discard())
+
+	pod := &corev1.Pod{
+		TypeMeta:   metav1.TypeMeta{APIVersion: "v1", Kind: "Pod"},
+		ObjectMeta: metav1.ObjectMeta{Name: "test-pod", Namespace: "default"},
+	}
+	assert.NoError(t, tracingClient.Patch(context.Background(), pod, client.Apply, client.FieldOwner("my-controller")))
+
+	spans := recorder.Ended()
+	assert.Len(t, spans, 1)
+	assert.Contains(t, spans[0].Attributes(), ApplyFieldManagerKey.String("my-controller"))
+	assert.Contains(t, spans[0].Attributes(), attribute.Bool(string(ApplyForceKey), false))
+
+	// The applied configuration of the caller is left without the trace, which is applied by its own field manager
+	assert.Len(t, applies, 2)
+	assert.Equal(t, "my-controller", applies[0].fieldManager)
+	assert.Empty(t, applies[0].annotations)
+	assert.Equal(t, TraceFieldManager, applies[1].fieldManager)
+	assert.True(t, applies[1].force)
+	assert.Equal(t, spans[0].SpanContext().TraceID().String(), applies[1].annotations["kubetracer.io/trace-id"])
+	assert.Equal(t, spans[0].SpanContext().SpanID().String(), pod.Annotations["kubetracer.io/span-id"])
+}
@@ -0,0 +1,108 @@
+package client
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+
+	"go.opentelemetry.io/otel/attribute"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+)
+
+var _ sdktrace.SpanProcessor = &AttributeFilter{}
+
+// AttributePolicy lists the span attribute keys that must not leave the process as is
+type AttributePolicy struct {
+	// Drop lists the attribute keys removed from spans and span events
+	Drop []attribute.Key
+	// Hash lists the attribute keys whose values are replaced by their SHA-256 hash, so spans can still be
+	// correlated by e.g. object name without exporting it
+	Hash []attribute.Key
+}
+
+// AttributeFilter enforces an AttributePolicy on every span before handing it to the next span processor,
+// for compliance environments with strict data-egress rules.  It wraps the processor doing the export:
+//
+//	policy := kubetracer.AttributePolicy{Hash: []attribute.Key{"k8s.namespace.name"}}
+//	tp := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(
+//		kubetracer.NewAttributeFilter(sdktrace.NewBatchSpanProcessor(exporter), policy)))
+type AttributeFilter struct {
+	next sdktrace.SpanProcessor
+	drop map[attribute.Key]bool
+	hash map[attribute.Key]bool
+}
+
+// NewAttributeFilter returns an AttributeFilter applying policy to the spans passed on to next
+func NewAttributeFilter(next sdktrace.SpanProcessor, policy AttributePolicy) *AttributeFilter {
+	f := &AttributeFilter{
+		next: next,
+		drop: map[attribute.Key]bool{},
+		hash: map[attribute.Key]bool{},
+	}
+	for _, key := range policy.Drop {
+		f.drop[key] = true
+	}
+	for _, key := range policy.Hash {
+		f.hash[key] = true
+	}
+	return f
+}
+
+// OnStart implements sdktrace.SpanProcessor
+func (f *AttributeFilter) OnStart(parent context.Context, s sdktrace.ReadWriteSpan) {
+	f.next.OnStart(parent, s)
+}
+
+// OnEnd implements sdktrace.SpanProcessor, passing a filtered copy of s to the next span processor
+func (f *AttributeFilter) OnEnd(s sdktrace.ReadOnlySpan) {
+	events := make([]sdktrace.Event, len(s.Events()))
+	for i, event := range s.Events() {
+		event.Attributes = f.filter(event.Attributes)
+		events[i] = event
+	}
+	f.next.OnEnd(filteredSpan{ReadOnlySpan: s, attributes: f.filter(s.Attributes()), events: events})
+}
+
+// Shutdown implements sdktrace.SpanProcessor
+func (f *AttributeFilter) Shutdown(ctx context.Context) error {
+	return f.next.Shutdown(ctx)
+}
+
+// ForceFlush implements sdktrace.SpanProcessor
+func (f *AttributeFilter) ForceFlush(ctx context.Context) error {
+	return f.next.ForceFlush(ctx)
+}
+
+// filter returns attrs without the dropped attributes and with the hashed attributes hashed
+func (f *AttributeFilter) filter(attrs []attribute.KeyValue) []attribute.KeyValue {
+	filtered := make([]attribute.KeyValue, 0, len(attrs))
+	for _, attr := range attrs {
+		switch {
+		case f.drop[attr.Key]:
+			continue
+		case f.hash[attr.Key]:
+			sum := sha256.Sum256([]byte(attr.Value.Emit()))
+			filtered = append(filtered, attr.Key.String(hex.EncodeToString(sum[:])))
+		default:
+			filtered = append(filtered, attr)
+		}
+	}
+	return filtered
+}
+
+// filteredSpan is a ReadOnlySpan with filtered attributes and events
+type filteredSpan struct {
+	sdktrace.ReadOnlySpan
+	attributes []attribute.KeyValue
+	events     []sdktrace.Event
+}
+
+// Attributes implements sdktrace.ReadOnlySpan
+func (s filteredSpan) Attributes() []attribute.KeyValue {
+	return s.attributes
+}
+
+// Events implements sdktrace.ReadOnlySpan
+func (s filteredSpan) Events() []sdktrace.Event {
+	return s.events
+}
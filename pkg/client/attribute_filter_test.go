@@ -0,0 +1,42 @@
+package client
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/otel/attribute"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func TestAttributeFilter(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	filter := NewAttributeFilter(recorder, AttributePolicy{
+		Drop: []attribute.Key{"object.name"},
+		Hash: []attribute.Key{"object.namespace"},
+	})
+	tracer := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(filter)).Tracer("kubetracer")
+
+	_, span := tracer.Start(context.Background(), "Update Pod", trace.WithAttributes(
+		attribute.String("object.name", "secret-pod"),
+		attribute.String("object.namespace", "tenant-a"),
+		attribute.String("kind", "Pod"),
+	))
+	span.AddEvent("retry", trace.WithAttributes(attribute.String("object.name", "secret-pod")))
+	span.End()
+
+	spans := recorder.Ended()
+	assert.Len(t, spans, 1)
+
+	sum := sha256.Sum256([]byte("tenant-a"))
+	assert.ElementsMatch(t, []attribute.KeyValue{
+		attribute.String("object.namespace", hex.EncodeToString(sum[:])),
+		attribute.String("kind", "Pod"),
+	}, spans[0].Attributes())
+	assert.Len(t, spans[0].Events(), 1)
+	assert.Empty(t, spans[0].Events()[0].Attributes)
+}
@@ -0,0 +1,112 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync/atomic"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	// BatchOperationsKey is the attribute of a batch span holding the number of client operations made under it
+	BatchOperationsKey = attribute.Key("kubetracer.batch.operations")
+	// BatchFailuresKey is the attribute of a batch span holding the number of client operations which failed
+	BatchFailuresKey = attribute.Key("kubetracer.batch.failures")
+)
+
+// EndBatch ends the span opened by StartBatch, recording the number of operations made under it and of those
+// which failed
+type EndBatch func()
+
+type batchKey struct{}
+
+// batch counts the client operations made under a batch span
+type batch struct {
+	operations atomic.Int64
+	failures   atomic.Int64
+
+	// parent is the enclosing batch, if any, which counts the operations as well
+	parent *batch
+}
+
+// batchFromContext returns the innermost batch started in ctx, nil if none
+func batchFromContext(ctx context.Context) *batch {
+	b, _ := ctx.Value(batchKey{}).(*batch)
+	return b
+}
+
+// add counts an operation, failed or not, in b and its enclosing batches
+func (b *batch) add(failed bool) {
+	for ; b != nil; b = b.parent {
+		b.operations.Add(1)
+		if failed {
+			b.failures.Add(1)
+		}
+	}
+}
+
+// StartBatch opens a span named name under which the client operations made with the returned context become
+// children, grouping e.g. the dozens of children created by a reconcile into one logical span.  Calling the
+// EndBatch ends the span, with the number of operations made under it and of those which failed as attributes;
+// failures also set its status to error.  Batches can be nested, the operations counting in all of them.
+func (tc *tracingClient) StartBatch(ctx context.Context, name string) (context.Context, EndBatch) {
+	ctx, span := startSpanFromContext(ctx, tc.Logger, tc.Tracer, nil, tc.scheme, tc.propagation, name)
+	b := &batch{parent: batchFromContext(ctx)}
+	ctx = context.WithValue(ctx, batchKey{}, b)
+
+	return ctx, func() {
+		operations, failures := b.operations.Load(), b.failures.Load()
+		span.SetAttributes(BatchOperationsKey.Int64(operations), BatchFailuresKey.Int64(failures))
+		if failures > 0 {
+			span.SetStatus(codes.Error, fmt.Sprintf("%d of %d operations failed", failures, operations))
+		}
+		span.End()
+	}
+}
+
+// CreateAll creates objs under one parent span, with a child span per object.  Every object is
+// annotated with the same trace.  All objects are attempted even if some fail, and the errors
+// are joined.
+func (tc *tracingClient) CreateAll(ctx context.Context, objs []client.Object, opts ...client.CreateOption) error {
+	ctx, span := startSpanFromContext(ctx, tc.Logger, tc.Tracer, nil, tc.scheme, tc.propagation, fmt.Sprintf("CreateAll %d objects", len(objs)))
+	defer span.End()
+
+	var errs []error
+	for _, obj := range objs {
+		if err := tc.Create(ctx, obj, opts...); err != nil {
+			errs = append(errs, fmt.Errorf("problem creating %s: %w", obj.GetName(), err))
+		}
+	}
+
+	err := errors.Join(errs...)
+	if err != nil {
+		tc.stackTraces.recordError(span, err)
+	}
+	return err
+}
+
+// ApplyAll server-side applies objs under one parent span, with a child span per object.  The
+// objects must have their apiVersion and kind set, and opts should include a client.FieldOwner.
+// Every object is annotated with the same trace.  All objects are attempted even if some fail,
+// and the errors are joined.
+func (tc *tracingClient) ApplyAll(ctx context.Context, objs []client.Object, opts ...client.PatchOption) error {
+	ctx, span := startSpanFromContext(ctx, tc.Logger, tc.Tracer, nil, tc.scheme, tc.propagation, fmt.Sprintf("ApplyAll %d objects", len(objs)))
+	defer span.End()
+
+	var errs []error
+	for _, obj := range objs {
+		if err := tc.Patch(ctx, obj, client.Apply, opts...); err != nil {
+			errs = append(errs, fmt.Errorf("problem applying %s: %w", obj.GetName(), err))
+		}
+	}
+
+	err := errors.Join(errs...)
+	if err != nil {
+		tc.stackTraces.recordError(span, err)
+	}
+	return err
+}
@@ -0,0 +1,87 @@
+package client
+
+import (
+	"context"
+	"testing"
+
+	"github.com/go-logr/logr"
+	"github.com/kubetracer/kubetracer-go/pkg/constants"
+	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestCreateAll(t *testing.T) {
+	// Create a fake Kubernetes client
+	k8sClient := fake.NewClientBuilder().WithObjects(&corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "pre-test-pod",
+			Namespace: "default",
+		},
+	}).Build()
+
+	// Initialize the TracingClient
+	tracingClient := NewTracingClient(k8sClient, k8sClient, initTracer(), logr.Discard())
+
+	ctx := context.Background()
+	ctx, span, err := tracingClient.StartTrace(ctx, client.ObjectKey{Name: "pre-test-pod", Namespace: "default"}, &corev1.Pod{})
+	defer span.End()
+	assert.NoError(t, err)
+	traceID := span.SpanContext().TraceID().String()
+
+	objs := []client.Object{
+		&corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "test-pod-1", Namespace: "default"}},
+		&corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "pre-test-pod", Namespace: "default"}},
+		&corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "test-pod-2", Namespace: "default"}},
+	}
+
+	// The existing Pod fails, the others are still created
+	err = tracingClient.CreateAll(ctx, objs)
+	assert.Error(t, err)
+	assert.True(t, apierrors.IsAlreadyExists(err))
+
+	for _, name := range []string{"test-pod-1", "test-pod-2"} {
+		retrievedPod := &corev1.Pod{}
+		err = k8sClient.Get(ctx, client.ObjectKey{Name: name, Namespace: "default"}, retrievedPod)
+		assert.NoError(t, err)
+		assert.Equal(t, traceID, retrievedPod.Annotations[constants.TraceIDAnnotation])
+	}
+}
+
+func TestStartBatch(t *testing.T) {
+	existing := &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: "existing", Namespace: "default"}}
+	k8sClient := fake.NewClientBuilder().WithObjects(existing).Build()
+	recorder := tracetest.NewSpanRecorder()
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSampler(sdktrace.AlwaysSample()), sdktrace.WithSpanProcessor(recorder))
+	tracingClient := NewTracingClientWithOptions(k8sClient, WithTracerProvider(tp))
+
+	ctx, endBatch := tracingClient.StartBatch(context.Background(), "create children")
+	for _, name := range []string{"child-1", "existing"} {
+		_ = tracingClient.Create(ctx, &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default"}})
+	}
+	nestedCtx, endNested := tracingClient.StartBatch(ctx, "get children")
+	assert.NoError(t, tracingClient.Get(nestedCtx, client.ObjectKeyFromObject(existing), &corev1.ConfigMap{}))
+	endNested()
+	endBatch()
+
+	spans := map[string]sdktrace.ReadOnlySpan{}
+	for _, span := range recorder.Ended() {
+		spans[span.Name()] = span
+	}
+	batchSpan, nestedSpan := spans["create children"], spans["get children"]
+	assert.Contains(t, batchSpan.Attributes(), BatchOperationsKey.Int64(3))
+	assert.Contains(t, batchSpan.Attributes(), BatchFailuresKey.Int64(1))
+	assert.Equal(t, codes.Error, batchSpan.Status().Code)
+	assert.Equal(t, batchSpan.SpanContext().SpanID(), spans["Create ConfigMap child-1"].Parent().SpanID())
+
+	assert.Contains(t, nestedSpan.Attributes(), BatchOperationsKey.Int64(1))
+	assert.Contains(t, nestedSpan.Attributes(), BatchFailuresKey.Int64(0))
+	assert.Equal(t, codes.Unset, nestedSpan.Status().Code)
+	assert.Equal(t, batchSpan.SpanContext().SpanID(), nestedSpan.Parent().SpanID())
+}
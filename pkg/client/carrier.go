@@ -0,0 +1,67 @@
+package client
+
+import (
+	"context"
+	"strings"
+
+	constants "github.com/kubetracer/kubetracer-go/pkg/constants"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+var _ propagation.TextMapCarrier = ObjectCarrier{}
+
+// ObjectCarrier lets an OTel propagator read and write its fields as annotations of an object, e.g. the
+// traceparent field of the W3C propagator is stored as otel.kubetracer.io/traceparent.  Any propagator, such as B3
+// or Jaeger, can carry the trace on objects this way.
+type ObjectCarrier struct {
+	obj client.Object
+
+	// prefix prefixes the fields in the annotations, constants.PropagatorAnnotationPrefix unless configured
+	prefix string
+}
+
+// NewObjectCarrier returns the carrier of the fields of propagators over the annotations of obj, prefixed
+// with constants.PropagatorAnnotationPrefix
+func NewObjectCarrier(obj client.Object) ObjectCarrier {
+	return ObjectCarrier{obj: obj, prefix: constants.PropagatorAnnotationPrefix}
+}
+
+// Get returns the value of the annotation for key
+func (c ObjectCarrier) Get(key string) string {
+	return c.obj.GetAnnotations()[c.prefix+key]
+}
+
+// Set stores the value of key as an annotation
+func (c ObjectCarrier) Set(key, value string) {
+	if c.obj.GetAnnotations() == nil {
+		c.obj.SetAnnotations(map[string]string{})
+	}
+	annotations := c.obj.GetAnnotations()
+	annotations[c.prefix+key] = value
+	c.obj.SetAnnotations(annotations)
+}
+
+// Keys lists the keys stored in the annotations
+func (c ObjectCarrier) Keys() []string {
+	keys := []string{}
+	for annotation := range c.obj.GetAnnotations() {
+		if key, ok := strings.CutPrefix(annotation, c.prefix); ok {
+			keys = append(keys, key)
+		}
+	}
+	return keys
+}
+
+// InjectIntoObject writes the trace and baggage of ctx into the annotations of obj with the globally configured
+// propagator, as the TracingClient does
+func InjectIntoObject(ctx context.Context, obj client.Object) {
+	otel.GetTextMapPropagator().Inject(ctx, NewObjectCarrier(obj))
+}
+
+// ExtractFromObject returns a copy of ctx carrying the trace and baggage written into the annotations of obj
+// with the globally configured propagator, e.g. by InjectIntoObject
+func ExtractFromObject(ctx context.Context, obj client.Object) context.Context {
+	return otel.GetTextMapPropagator().Extract(ctx, NewObjectCarrier(obj))
+}
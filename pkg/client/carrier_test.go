@@ -0,0 +1,41 @@
+package client
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestObjectCarrier(t *testing.T) {
+	previous := otel.GetTextMapPropagator()
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+	t.Cleanup(func() { otel.SetTextMapPropagator(previous) })
+
+	traceID, _ := trace.TraceIDFromHex("0af7651916cd43dd8448eb211c80319c")
+	spanID, _ := trace.SpanIDFromHex("b7ad6b7169203331")
+	sc := trace.NewSpanContext(trace.SpanContextConfig{TraceID: traceID, SpanID: spanID, TraceFlags: trace.FlagsSampled})
+	ctx := trace.ContextWithSpanContext(context.Background(), sc)
+
+	pod := &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "pod", Namespace: "default", Annotations: map[string]string{"app": "web"}}}
+	InjectIntoObject(ctx, pod)
+	assert.NoError(t, InjectSpanContext(sc, pod))
+	assert.Equal(t, "00-0af7651916cd43dd8448eb211c80319c-b7ad6b7169203331-01", pod.Annotations["otel.kubetracer.io/traceparent"])
+	assert.Equal(t, "web", pod.Annotations["app"])
+	// the trace ID and span ID annotations are not fields of the propagator
+	assert.Equal(t, []string{"traceparent"}, NewObjectCarrier(pod).Keys())
+
+	extracted := trace.SpanContextFromContext(ExtractFromObject(context.Background(), pod))
+	assert.Equal(t, traceID, extracted.TraceID())
+	assert.Equal(t, spanID, extracted.SpanID())
+	assert.True(t, extracted.IsRemote())
+
+	empty := &corev1.Pod{}
+	assert.False(t, trace.SpanContextFromContext(ExtractFromObject(context.Background(), empty)).IsValid())
+	assert.Empty(t, NewObjectCarrier(empty).Keys())
+}
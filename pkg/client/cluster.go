@@ -0,0 +1,77 @@
+package client
+
+import (
+	"context"
+	"sync"
+
+	"github.com/go-logr/logr"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+	"sigs.k8s.io/controller-runtime/pkg/cluster"
+)
+
+// ClusterAttributeKey is the span attribute holding the name of the cluster a TracingClient talks to
+const ClusterAttributeKey = attribute.Key("kubetracer.cluster")
+
+// NewClusterTracingClient returns a TracingClient for cl, stamping name on every span it starts.  As with
+// NewTracingClient, the Reader used by StartTrace and EndTrace is the client of the cluster.
+func NewClusterTracingClient(name string, cl cluster.Cluster, t trace.Tracer, l logr.Logger) TracingClient {
+	return NewTracingClient(cl.GetClient(), nil, clusterTracer{Tracer: t, name: name}, l.WithValues("cluster", name), cl.GetScheme())
+}
+
+// clusterTracer adds the ClusterAttributeKey to the spans it starts
+type clusterTracer struct {
+	trace.Tracer
+	name string
+}
+
+// Start implements trace.Tracer
+func (t clusterTracer) Start(ctx context.Context, spanName string, opts ...trace.SpanStartOption) (context.Context, trace.Span) {
+	opts = append(opts, trace.WithAttributes(ClusterAttributeKey.String(t.name)))
+	return t.Tracer.Start(ctx, spanName, opts...)
+}
+
+// FleetTracingClients hands out the TracingClients of the clusters reconciled by a fleet controller, e.g.
+// as clusters are engaged and disengaged by a multi-cluster provider.  All of them share one tracer and
+// logger, and stamp the cluster name on their spans.
+type FleetTracingClients struct {
+	tracer trace.Tracer
+	logger logr.Logger
+
+	mu      sync.RWMutex
+	clients map[string]TracingClient
+}
+
+// NewFleetTracingClients returns a FleetTracingClients creating TracingClients with t and l
+func NewFleetTracingClients(t trace.Tracer, l logr.Logger) *FleetTracingClients {
+	return &FleetTracingClients{
+		tracer:  t,
+		logger:  l,
+		clients: map[string]TracingClient{},
+	}
+}
+
+// Engage creates the TracingClient of the cluster cl named name, replacing any previous one
+func (f *FleetTracingClients) Engage(name string, cl cluster.Cluster) TracingClient {
+	tc := NewClusterTracingClient(name, cl, f.tracer, f.logger)
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.clients[name] = tc
+	return tc
+}
+
+// Disengage forgets the TracingClient of the cluster named name
+func (f *FleetTracingClients) Disengage(name string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	delete(f.clients, name)
+}
+
+// Get returns the TracingClient of the cluster named name, if engaged
+func (f *FleetTracingClients) Get(name string) (TracingClient, bool) {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+	tc, ok := f.clients[name]
+	return tc, ok
+}
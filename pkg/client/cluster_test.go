@@ -0,0 +1,58 @@
+package client
+
+import (
+	"context"
+	"testing"
+
+	"github.com/go-logr/logr"
+	"github.com/stretchr/testify/assert"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/cluster"
+)
+
+// fakeCluster is a cluster.Cluster only providing a client
+type fakeCluster struct {
+	cluster.Cluster
+	client client.Client
+}
+
+func (c fakeCluster) GetClient() client.Client {
+	return c.client
+}
+
+func (c fakeCluster) GetScheme() *runtime.Scheme {
+	return clientgoscheme.Scheme
+}
+
+func TestFleetTracingClients(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	tracer := sdktrace.NewTracerProvider(sdktrace.WithSampler(sdktrace.AlwaysSample()), sdktrace.WithSpanProcessor(recorder)).Tracer("kubetracer")
+	fleet := NewFleetTracingClients(tracer, logr.Discard())
+
+	east := fleet.Engage("east", fakeCluster{client: fake.NewClientBuilder().Build()})
+	west := fleet.Engage("west", fakeCluster{client: fake.NewClientBuilder().Build()})
+
+	pod := &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "fleet-pod", Namespace: "default"}}
+	assert.NoError(t, east.Create(context.Background(), pod.DeepCopy()))
+	assert.NoError(t, west.Create(context.Background(), pod.DeepCopy()))
+
+	spans := recorder.Ended()
+	assert.Len(t, spans, 2)
+	assert.Contains(t, spans[0].Attributes(), ClusterAttributeKey.String("east"))
+	assert.Contains(t, spans[1].Attributes(), ClusterAttributeKey.String("west"))
+
+	got, ok := fleet.Get("east")
+	assert.True(t, ok)
+	assert.Same(t, east, got)
+
+	fleet.Disengage("east")
+	_, ok = fleet.Get("east")
+	assert.False(t, ok)
+}
@@ -0,0 +1,47 @@
+package client
+
+import (
+	"context"
+	"sync"
+
+	"go.opentelemetry.io/otel/trace"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// injectionTrackerKey is the context key holding the injectionTracker of a reconcile
+type injectionTrackerKey struct{}
+
+// injectionTracker records the span IDs injected on each object during a reconcile, so writing the same object
+// several times does not rewrite its trace annotations each time.  The span of the first write remains the
+// parent of the trace on the object.
+type injectionTracker struct {
+	mu       sync.Mutex
+	injected map[objectIdentity]string
+}
+
+// contextWithInjectionTracker starts tracking the injections of a reconcile in ctx
+func contextWithInjectionTracker(ctx context.Context) context.Context {
+	return context.WithValue(ctx, injectionTrackerKey{}, &injectionTracker{injected: map[objectIdentity]string{}})
+}
+
+// alreadyInjected reports whether obj already carries the trace of spanContext as injected earlier in the
+// reconcile.  Otherwise the span ID about to be injected is recorded.
+func alreadyInjected(ctx context.Context, spanContext trace.SpanContext, obj client.Object, keys *annotationKeys) bool {
+	tracker, ok := ctx.Value(injectionTrackerKey{}).(*injectionTracker)
+	if !ok || obj.GetName() == "" {
+		return false
+	}
+	key := identityOf(obj)
+
+	tracker.mu.Lock()
+	defer tracker.mu.Unlock()
+
+	traceID, currentSpanID, _ := traceIDs(obj, keys)
+	if spanID, ok := tracker.injected[key]; ok &&
+		traceID == spanContext.TraceID().String() &&
+		currentSpanID == spanID {
+		return true
+	}
+	tracker.injected[key] = spanContext.SpanID().String()
+	return false
+}
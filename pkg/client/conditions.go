@@ -0,0 +1,122 @@
+package client
+
+import (
+	"errors"
+	"fmt"
+
+	"go.opentelemetry.io/otel/trace"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// TraceConditionReason is the reason of the status conditions storing the trace
+const TraceConditionReason = "TraceContextPropagated"
+
+// getUnstructuredConditions reads .status.conditions from the content of u, without the scheme, so that objects
+// of kinds unknown to the scheme, e.g. CRDs of a dynamic controller, can carry the trace in conditions
+func getUnstructuredConditions(u runtime.Unstructured) ([]metav1.Condition, error) {
+	items, found, err := unstructured.NestedSlice(u.UnstructuredContent(), "status", "conditions")
+	if err != nil {
+		return nil, fmt.Errorf("problem reading the conditions: %w", err)
+	}
+	if !found {
+		return nil, nil
+	}
+
+	conditions := make([]metav1.Condition, 0, len(items))
+	for _, item := range items {
+		content, ok := item.(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("condition %v is not an object", item)
+		}
+		condition := metav1.Condition{}
+		if err := runtime.DefaultUnstructuredConverter.FromUnstructured(content, &condition); err != nil {
+			return nil, fmt.Errorf("problem converting the condition: %w", err)
+		}
+		conditions = append(conditions, condition)
+	}
+	return conditions, nil
+}
+
+// setUnstructuredConditions writes conditions to .status.conditions of u.  Fields of the existing conditions
+// which metav1.Condition does not know about are kept.
+func setUnstructuredConditions(u runtime.Unstructured, conditions []metav1.Condition) error {
+	content := u.UnstructuredContent()
+	existing, _, _ := unstructured.NestedSlice(content, "status", "conditions")
+	byType := map[string]map[string]interface{}{}
+	for _, item := range existing {
+		if condition, ok := item.(map[string]interface{}); ok {
+			if conditionType, ok := condition["type"].(string); ok {
+				byType[conditionType] = condition
+			}
+		}
+	}
+
+	items := make([]interface{}, 0, len(conditions))
+	for i := range conditions {
+		converted, err := runtime.DefaultUnstructuredConverter.ToUnstructured(&conditions[i])
+		if err != nil {
+			return fmt.Errorf("problem converting the condition: %w", err)
+		}
+		item := map[string]interface{}{}
+		for key, value := range byType[conditions[i].Type] {
+			item[key] = value
+		}
+		for key, value := range converted {
+			item[key] = value
+		}
+		items = append(items, item)
+	}
+
+	if err := unstructured.SetNestedSlice(content, items, "status", "conditions"); err != nil {
+		return fmt.Errorf("problem writing the conditions: %w", err)
+	}
+	u.SetUnstructuredContent(content)
+	return nil
+}
+
+// ErrNoConditions is returned when the status of an object has no conditions to store the trace in, e.g. a
+// ConfigMap which has no status at all
+var ErrNoConditions = errors.New("no status conditions")
+
+// MissingConditionsPolicy tells the status writes of a TracingClient what to do with the trace of kinds whose
+// status has no conditions
+type MissingConditionsPolicy int
+
+const (
+	// SkipMissingConditions writes the status without the trace.  It is the default.
+	SkipMissingConditions MissingConditionsPolicy = iota
+	// AnnotateMissingConditions writes the trace ID and span ID annotations on the object instead.  They are
+	// only persisted by kinds without a status subresource, whose status writes update the whole object.
+	AnnotateMissingConditions
+	// FailOnMissingConditions fails the status write with ErrNoConditions before it is sent
+	FailOnMissingConditions
+)
+
+// WithMissingConditionsPolicy applies policy to the status writes of kinds without conditions, so that status
+// tracing works across arbitrary kinds
+func WithMissingConditionsPolicy(policy MissingConditionsPolicy) Option {
+	return func(o *clientOptions) {
+		o.missingConditions = policy
+	}
+}
+
+// setStatusTraceContext stores spanContext in the status of obj, applying the policy if the status has no
+// conditions.  An error is only returned by FailOnMissingConditions, other failures leave the status untraced.
+func (p MissingConditionsPolicy) setStatusTraceContext(spanContext trace.SpanContext, obj client.Object, scheme *runtime.Scheme, prop *tracePropagation) error {
+	err := setStatusTraceContext(spanContext, obj, scheme, prop.conditionFormat())
+	if !errors.Is(err, ErrNoConditions) {
+		return nil
+	}
+
+	switch p {
+	case AnnotateMissingConditions:
+		return injectSpanContext(spanContext, obj, prop.annotationKeys())
+	case FailOnMissingConditions:
+		return err
+	default:
+		return nil
+	}
+}
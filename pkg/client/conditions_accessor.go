@@ -0,0 +1,193 @@
+package client
+
+import (
+	"fmt"
+	"reflect"
+	"sync"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/apiutil"
+)
+
+// ConditionsAccessor reads and writes the status conditions of objects, for types whose conditions are not a
+// slice of structs the reflection fallback can map to metav1.Condition
+type ConditionsAccessor interface {
+	GetConditions(obj client.Object) ([]metav1.Condition, error)
+	SetConditions(obj client.Object, conditions []metav1.Condition) error
+}
+
+// ConditionsObject is implemented by API types giving native access to their conditions.  The TracingClient
+// uses it in preference to any ConditionsAccessor.
+type ConditionsObject interface {
+	client.Object
+	GetConditions() []metav1.Condition
+	SetConditions(conditions []metav1.Condition)
+}
+
+// conditionsAccessors holds the accessors registered with RegisterConditionsAccessor
+var conditionsAccessors = struct {
+	mu        sync.RWMutex
+	accessors map[schema.GroupVersionKind]ConditionsAccessor
+}{accessors: map[schema.GroupVersionKind]ConditionsAccessor{}}
+
+// RegisterConditionsAccessor makes the TracingClient read and write the trace conditions of typed objects of the
+// kind gvk with accessor instead of reflection.  It applies to every TracingClient of the binary.
+func RegisterConditionsAccessor(gvk schema.GroupVersionKind, accessor ConditionsAccessor) {
+	conditionsAccessors.mu.Lock()
+	defer conditionsAccessors.mu.Unlock()
+	conditionsAccessors.accessors[gvk] = accessor
+}
+
+// conditionsAccessorOf returns the accessor of the conditions of obj: native access if it implements
+// ConditionsObject, else the accessor registered for its kind, else the unstructured content or reflection
+func conditionsAccessorOf(obj client.Object, scheme *runtime.Scheme) ConditionsAccessor {
+	if _, ok := obj.(ConditionsObject); ok {
+		return objectConditions{}
+	}
+	if _, ok := obj.(runtime.Unstructured); ok {
+		return unstructuredConditions{}
+	}
+
+	if gvk, err := apiutil.GVKForObject(obj, scheme); err == nil {
+		conditionsAccessors.mu.RLock()
+		accessor, ok := conditionsAccessors.accessors[gvk]
+		conditionsAccessors.mu.RUnlock()
+		if ok {
+			return accessor
+		}
+	}
+	return reflectionConditions{scheme: scheme}
+}
+
+// objectConditions is the ConditionsAccessor of a ConditionsObject
+type objectConditions struct{}
+
+func (objectConditions) GetConditions(obj client.Object) ([]metav1.Condition, error) {
+	return obj.(ConditionsObject).GetConditions(), nil
+}
+
+func (objectConditions) SetConditions(obj client.Object, conditions []metav1.Condition) error {
+	obj.(ConditionsObject).SetConditions(conditions)
+	return nil
+}
+
+// reflectionConditions is the fallback ConditionsAccessor of typed objects, mapping the fields of their
+// Status.Conditions to metav1.Condition by name.  Objects of the Go type registered in the scheme are accessed
+// through the field indices compiled once per GVK, others are converted to it.
+type reflectionConditions struct {
+	scheme *runtime.Scheme
+}
+
+func (a reflectionConditions) GetConditions(obj client.Object) ([]metav1.Condition, error) {
+	if compiled, ok := compiledConditionsFor(obj, a.scheme); ok {
+		return compiled.get(obj)
+	}
+	return a.getConverted(obj)
+}
+
+func (a reflectionConditions) SetConditions(obj client.Object, conditions []metav1.Condition) error {
+	if compiled, ok := compiledConditionsFor(obj, a.scheme); ok {
+		return compiled.set(obj, conditions)
+	}
+	return a.setConverted(obj, conditions)
+}
+
+// getConverted reads the conditions of a copy of obj converted to the type registered in the scheme, for
+// objects of another Go type
+func (a reflectionConditions) getConverted(obj client.Object) ([]metav1.Condition, error) {
+	gvk, err := apiutil.GVKForObject(obj, a.scheme)
+	if err != nil {
+		return nil, fmt.Errorf("problem getting the GVK: %w", err)
+	}
+
+	// Use the scheme to get the specific type of the object.
+	objTyped, err := a.scheme.New(gvk)
+	if err != nil {
+		return nil, fmt.Errorf("problem creating new object of kind %s: %w", gvk.Kind, err)
+	}
+
+	// Cast the object to its specific type.
+	if err := a.scheme.Convert(obj, objTyped, nil); err != nil {
+		return nil, fmt.Errorf("problem converting object to kind %s: %w", gvk.Kind, err)
+	}
+
+	// Use reflection to access the conditions field.
+	val := reflect.ValueOf(objTyped)
+	statusField := val.Elem().FieldByName("Status")
+	if !statusField.IsValid() {
+		return nil, fmt.Errorf("status field not found in kind %s: %w", gvk.Kind, ErrNoConditions)
+	}
+
+	conditionsField := statusField.FieldByName("Conditions")
+	if !conditionsField.IsValid() {
+		return nil, fmt.Errorf("conditions field not found in kind %s: %w", gvk.Kind, ErrNoConditions)
+	}
+
+	conditionsValue := conditionsField.Interface()
+	conditions, err := convertToMetaV1Conditions(conditionsValue)
+	if err != nil {
+		return nil, fmt.Errorf("error converting conditions for kind %s: %w", gvk.Kind, err)
+	}
+
+	return conditions, nil
+}
+
+// setConverted writes the conditions to a copy of obj converted to the type registered in the scheme, and
+// converts it back to obj
+func (a reflectionConditions) setConverted(obj client.Object, conditions []metav1.Condition) error {
+	gvk, err := apiutil.GVKForObject(obj, a.scheme)
+	if err != nil {
+		return fmt.Errorf("problem getting the GVK: %w", err)
+	}
+
+	// Use the scheme to get the specific type of the object.
+	objTyped, err := a.scheme.New(gvk)
+	if err != nil {
+		return fmt.Errorf("problem creating new object of kind %s: %w", gvk.Kind, err)
+	}
+
+	// Cast the object to its specific type.
+	if err := a.scheme.Convert(obj, objTyped, nil); err != nil {
+		return fmt.Errorf("problem converting object to kind %s: %w", gvk.Kind, err)
+	}
+
+	// Use reflection to set the conditions field.
+	val := reflect.ValueOf(objTyped)
+	statusField := val.Elem().FieldByName("Status")
+	if !statusField.IsValid() {
+		return fmt.Errorf("status field not found in kind %s: %w", gvk.Kind, ErrNoConditions)
+	}
+
+	conditionsField := statusField.FieldByName("Conditions")
+	if !conditionsField.IsValid() {
+		return fmt.Errorf("conditions field not found in kind %s: %w", gvk.Kind, ErrNoConditions)
+	}
+
+	convertedConditions, err := convertFromMetaV1(conditions, conditionsField.Type())
+	if err != nil {
+		return fmt.Errorf("error converting conditions for kind %s: %w", gvk.Kind, err)
+	}
+
+	conditionsField.Set(reflect.ValueOf(convertedConditions))
+
+	// Convert the typed object back to the unstructured object.
+	if err := a.scheme.Convert(objTyped, obj, nil); err != nil {
+		return fmt.Errorf("problem converting object back to unstructured: %w", err)
+	}
+
+	return nil
+}
+
+// unstructuredConditions is the ConditionsAccessor of unstructured objects, see getUnstructuredConditions
+type unstructuredConditions struct{}
+
+func (unstructuredConditions) GetConditions(obj client.Object) ([]metav1.Condition, error) {
+	return getUnstructuredConditions(obj.(runtime.Unstructured))
+}
+
+func (unstructuredConditions) SetConditions(obj client.Object, conditions []metav1.Condition) error {
+	return setUnstructuredConditions(obj.(runtime.Unstructured), conditions)
+}
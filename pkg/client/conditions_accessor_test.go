@@ -0,0 +1,73 @@
+package client
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// conditionedPod gives native access to conditions kept apart from the pod conditions
+type conditionedPod struct {
+	corev1.Pod
+	conditions []metav1.Condition
+}
+
+func (p *conditionedPod) GetConditions() []metav1.Condition { return p.conditions }
+
+func (p *conditionedPod) SetConditions(conditions []metav1.Condition) { p.conditions = conditions }
+
+// annotationConditions stores the conditions of nodes as JSON in an annotation
+type annotationConditions struct{}
+
+func (annotationConditions) GetConditions(obj client.Object) ([]metav1.Condition, error) {
+	var conditions []metav1.Condition
+	if value, ok := obj.GetAnnotations()["example.com/conditions"]; ok {
+		if err := json.Unmarshal([]byte(value), &conditions); err != nil {
+			return nil, err
+		}
+	}
+	return conditions, nil
+}
+
+func (annotationConditions) SetConditions(obj client.Object, conditions []metav1.Condition) error {
+	value, err := json.Marshal(conditions)
+	if err != nil {
+		return err
+	}
+	obj.SetAnnotations(map[string]string{"example.com/conditions": string(value)})
+	return nil
+}
+
+func TestConditionsObject(t *testing.T) {
+	pod := &conditionedPod{}
+	assert.NoError(t, setConditionMessage("TraceID", "f620f5cad0af940c294f980c5366a6a1", pod, clientgoscheme.Scheme))
+
+	message, err := getConditionMessage("TraceID", pod, clientgoscheme.Scheme)
+	assert.NoError(t, err)
+	assert.Equal(t, "f620f5cad0af940c294f980c5366a6a1", message)
+	assert.Empty(t, pod.Status.Conditions)
+}
+
+func TestRegisterConditionsAccessor(t *testing.T) {
+	gvk := corev1.SchemeGroupVersion.WithKind("Node")
+	RegisterConditionsAccessor(gvk, annotationConditions{})
+	t.Cleanup(func() {
+		conditionsAccessors.mu.Lock()
+		defer conditionsAccessors.mu.Unlock()
+		delete(conditionsAccessors.accessors, gvk)
+	})
+
+	node := &corev1.Node{}
+	assert.NoError(t, setConditionMessage("TraceID", "f620f5cad0af940c294f980c5366a6a1", node, clientgoscheme.Scheme))
+
+	message, err := getConditionMessage("TraceID", node, clientgoscheme.Scheme)
+	assert.NoError(t, err)
+	assert.Equal(t, "f620f5cad0af940c294f980c5366a6a1", message)
+	assert.Contains(t, node.Annotations, "example.com/conditions")
+	assert.Empty(t, node.Status.Conditions)
+}
@@ -0,0 +1,192 @@
+package client
+
+import (
+	"fmt"
+	"reflect"
+	"sync"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/apiutil"
+)
+
+// compiledConditionsCache holds the compiledConditions of each GVK, built on the first access
+var compiledConditionsCache sync.Map // map[schema.GroupVersionKind]*compiledConditions
+
+var metav1TimeType = reflect.TypeOf(metav1.Time{})
+
+// compiledConditions reads and writes Status.Conditions of the Go type registered for a GVK directly, through
+// field indices resolved once by reflection, instead of allocating and converting a typed object on every access
+type compiledConditions struct {
+	// objType is the pointer type the field indices apply to
+	objType reflect.Type
+
+	// err wraps ErrNoConditions when the type has no Status.Conditions
+	err error
+
+	conditions   []int
+	sliceType    reflect.Type
+	elemType     reflect.Type
+	pointerElems bool
+
+	// the indices of the fields of a condition, nil when the condition type does not have them
+	conditionType      []int
+	status             []int
+	reason             []int
+	message            []int
+	lastTransitionTime []int
+	observedGeneration []int
+}
+
+// compiledConditionsFor returns the compiledConditions of the kind of obj, if obj is of the Go type
+// registered for it in scheme
+func compiledConditionsFor(obj client.Object, scheme *runtime.Scheme) (*compiledConditions, bool) {
+	gvk, err := apiutil.GVKForObject(obj, scheme)
+	if err != nil {
+		return nil, false
+	}
+
+	cached, ok := compiledConditionsCache.Load(gvk)
+	if !ok {
+		typed, err := scheme.New(gvk)
+		if err != nil {
+			return nil, false
+		}
+		cached, _ = compiledConditionsCache.LoadOrStore(gvk, compileConditions(gvk, reflect.TypeOf(typed)))
+	}
+
+	compiled := cached.(*compiledConditions)
+	return compiled, reflect.TypeOf(obj) == compiled.objType
+}
+
+// compileConditions resolves the field indices of the conditions of objType, a pointer to a struct
+func compileConditions(gvk schema.GroupVersionKind, objType reflect.Type) *compiledConditions {
+	compiled := &compiledConditions{objType: objType}
+	if objType.Kind() != reflect.Pointer || objType.Elem().Kind() != reflect.Struct {
+		compiled.err = fmt.Errorf("kind %s is not a struct", gvk.Kind)
+		return compiled
+	}
+
+	statusField, ok := objType.Elem().FieldByName("Status")
+	if !ok || statusField.Type.Kind() != reflect.Struct {
+		compiled.err = fmt.Errorf("status field not found in kind %s: %w", gvk.Kind, ErrNoConditions)
+		return compiled
+	}
+	conditionsField, ok := statusField.Type.FieldByName("Conditions")
+	if !ok || conditionsField.Type.Kind() != reflect.Slice {
+		compiled.err = fmt.Errorf("conditions field not found in kind %s: %w", gvk.Kind, ErrNoConditions)
+		return compiled
+	}
+
+	compiled.conditions = append(append([]int{}, statusField.Index...), conditionsField.Index...)
+	compiled.sliceType = conditionsField.Type
+	compiled.elemType = conditionsField.Type.Elem()
+	if compiled.elemType.Kind() == reflect.Pointer {
+		compiled.pointerElems = true
+		compiled.elemType = compiled.elemType.Elem()
+	}
+	if compiled.elemType.Kind() != reflect.Struct {
+		compiled.err = fmt.Errorf("conditions of kind %s are not structs", gvk.Kind)
+		return compiled
+	}
+
+	for _, field := range reflect.VisibleFields(compiled.elemType) {
+		switch {
+		case field.Name == "Type" && field.Type.Kind() == reflect.String:
+			compiled.conditionType = field.Index
+		case field.Name == "Status" && field.Type.Kind() == reflect.String:
+			compiled.status = field.Index
+		case field.Name == "Reason" && field.Type.Kind() == reflect.String:
+			compiled.reason = field.Index
+		case field.Name == "Message" && field.Type.Kind() == reflect.String:
+			compiled.message = field.Index
+		case field.Name == "LastTransitionTime" && field.Type == metav1TimeType:
+			compiled.lastTransitionTime = field.Index
+		case field.Name == "ObservedGeneration" && field.Type.Kind() == reflect.Int64:
+			compiled.observedGeneration = field.Index
+		}
+	}
+	return compiled
+}
+
+// get returns the conditions of obj, which must be of objType
+func (c *compiledConditions) get(obj client.Object) ([]metav1.Condition, error) {
+	if c.err != nil {
+		return nil, c.err
+	}
+
+	items := reflect.ValueOf(obj).Elem().FieldByIndex(c.conditions)
+	var conditions []metav1.Condition
+	for i := 0; i < items.Len(); i++ {
+		item := items.Index(i)
+		if c.pointerElems {
+			if item.IsNil() {
+				continue
+			}
+			item = item.Elem()
+		}
+
+		condition := metav1.Condition{}
+		if c.conditionType != nil {
+			condition.Type = item.FieldByIndex(c.conditionType).String()
+		}
+		if c.status != nil {
+			condition.Status = metav1.ConditionStatus(item.FieldByIndex(c.status).String())
+		}
+		if c.reason != nil {
+			condition.Reason = item.FieldByIndex(c.reason).String()
+		}
+		if c.message != nil {
+			condition.Message = item.FieldByIndex(c.message).String()
+		}
+		if c.lastTransitionTime != nil {
+			condition.LastTransitionTime = item.FieldByIndex(c.lastTransitionTime).Interface().(metav1.Time)
+		}
+		if c.observedGeneration != nil {
+			condition.ObservedGeneration = item.FieldByIndex(c.observedGeneration).Int()
+		}
+		conditions = append(conditions, condition)
+	}
+	return conditions, nil
+}
+
+// set writes conditions to obj, which must be of objType
+func (c *compiledConditions) set(obj client.Object, conditions []metav1.Condition) error {
+	if c.err != nil {
+		return c.err
+	}
+
+	items := reflect.MakeSlice(c.sliceType, len(conditions), len(conditions))
+	for i, condition := range conditions {
+		item := reflect.New(c.elemType).Elem()
+		if c.conditionType != nil {
+			item.FieldByIndex(c.conditionType).SetString(condition.Type)
+		}
+		if c.status != nil {
+			item.FieldByIndex(c.status).SetString(string(condition.Status))
+		}
+		if c.reason != nil {
+			item.FieldByIndex(c.reason).SetString(condition.Reason)
+		}
+		if c.message != nil {
+			item.FieldByIndex(c.message).SetString(condition.Message)
+		}
+		if c.lastTransitionTime != nil {
+			item.FieldByIndex(c.lastTransitionTime).Set(reflect.ValueOf(condition.LastTransitionTime))
+		}
+		if c.observedGeneration != nil {
+			item.FieldByIndex(c.observedGeneration).SetInt(condition.ObservedGeneration)
+		}
+
+		if c.pointerElems {
+			items.Index(i).Set(item.Addr())
+		} else {
+			items.Index(i).Set(item)
+		}
+	}
+
+	reflect.ValueOf(obj).Elem().FieldByIndex(c.conditions).Set(items)
+	return nil
+}
@@ -0,0 +1,71 @@
+package client
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/otel/trace"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+)
+
+func TestCompiledConditions(t *testing.T) {
+	now := metav1.Now().Rfc3339Copy()
+	conditions := []metav1.Condition{
+		{Type: "Ready", Status: metav1.ConditionTrue, Reason: "Started", Message: "ready", LastTransitionTime: now},
+		{Type: "TraceID", Status: metav1.ConditionUnknown, Message: "f620f5cad0af940c294f980c5366a6a1", LastTransitionTime: now},
+	}
+	accessor := reflectionConditions{scheme: clientgoscheme.Scheme}
+
+	compiledPod := &corev1.Pod{}
+	assert.NoError(t, accessor.SetConditions(compiledPod, conditions))
+	convertedPod := &corev1.Pod{}
+	assert.NoError(t, accessor.setConverted(convertedPod, conditions))
+	assert.Equal(t, convertedPod, compiledPod)
+
+	compiled, err := accessor.GetConditions(compiledPod)
+	assert.NoError(t, err)
+	converted, err := accessor.getConverted(convertedPod)
+	assert.NoError(t, err)
+	assert.Equal(t, converted, compiled)
+	assert.Equal(t, conditions, compiled)
+
+	_, err = accessor.GetConditions(&corev1.ConfigMap{})
+	assert.ErrorIs(t, err, ErrNoConditions)
+}
+
+func benchmarkSetTraceConditions(b *testing.B, set func(*corev1.Pod, []metav1.Condition) error) {
+	traceID, _ := trace.TraceIDFromHex("f620f5cad0af940c294f980c5366a6a1")
+	spanID, _ := trace.SpanIDFromHex("45f359cdc1c8ab06")
+	spanContext := trace.NewSpanContext(trace.SpanContextConfig{TraceID: traceID, SpanID: spanID})
+	pod := &corev1.Pod{Status: corev1.PodStatus{Conditions: []corev1.PodCondition{{Type: corev1.PodReady, Status: corev1.ConditionTrue}}}}
+	accessor := reflectionConditions{scheme: clientgoscheme.Scheme}
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		conditions, _ := accessor.GetConditions(pod)
+		conditions = append(removeConditions(conditions, "TraceID", "SpanID"),
+			metav1.Condition{Type: "TraceID", Status: metav1.ConditionUnknown, Message: spanContext.TraceID().String()},
+			metav1.Condition{Type: "SpanID", Status: metav1.ConditionUnknown, Message: spanContext.SpanID().String()})
+		set(pod, conditions)
+	}
+}
+
+func BenchmarkSetTraceConditionsCompiled(b *testing.B) {
+	accessor := reflectionConditions{scheme: clientgoscheme.Scheme}
+	benchmarkSetTraceConditions(b, func(pod *corev1.Pod, conditions []metav1.Condition) error {
+		return accessor.SetConditions(pod, conditions)
+	})
+}
+
+func BenchmarkSetTraceConditionsConverted(b *testing.B) {
+	accessor := reflectionConditions{scheme: clientgoscheme.Scheme}
+	benchmarkSetTraceConditions(b, func(pod *corev1.Pod, conditions []metav1.Condition) error {
+		if _, err := accessor.getConverted(pod); err != nil {
+			return err
+		}
+		return accessor.setConverted(pod, conditions)
+	})
+}
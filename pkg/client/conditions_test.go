@@ -0,0 +1,197 @@
+package client
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	constants "github.com/kubetracer/kubetracer-go/pkg/constants"
+	"github.com/stretchr/testify/assert"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/trace"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/client/interceptor"
+)
+
+func TestUnstructuredConditions(t *testing.T) {
+	// Widget is not registered in the scheme
+	widget := &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "example.com/v1",
+		"kind":       "Widget",
+		"metadata":   map[string]interface{}{"name": "test-widget", "namespace": "default"},
+		"status": map[string]interface{}{
+			"conditions": []interface{}{
+				map[string]interface{}{
+					"type":               "Ready",
+					"status":             "True",
+					"reason":             "Ready",
+					"message":            "",
+					"lastTransitionTime": "2024-01-01T00:00:00Z",
+					"severity":           "Info",
+				},
+			},
+		},
+	}}
+
+	traceID, _ := trace.TraceIDFromHex("f620f5cad0af940c294f980c5366a6a1")
+	spanID, _ := trace.SpanIDFromHex("45f359cdc1c8ab06")
+	spanContext := trace.NewSpanContext(trace.SpanContextConfig{TraceID: traceID, SpanID: spanID})
+	assert.NoError(t, setTraceConditions(spanContext, widget, clientgoscheme.Scheme, TraceAndSpanIDConditions))
+
+	got, err := TraceContextFromObject(widget, clientgoscheme.Scheme)
+	assert.NoError(t, err)
+	assert.Equal(t, traceID, got.TraceID())
+	assert.Equal(t, spanID, got.SpanID())
+
+	assert.NoError(t, deleteConditions(widget, clientgoscheme.Scheme, "TraceID", "SpanID"))
+	conditions, _, _ := unstructured.NestedSlice(widget.Object, "status", "conditions")
+	assert.Len(t, conditions, 1)
+	// fields unknown to metav1.Condition are kept
+	assert.Equal(t, "Info", conditions[0].(map[string]interface{})["severity"])
+}
+
+func TestWithMissingConditionsPolicy(t *testing.T) {
+	tests := []struct {
+		name      string
+		policy    MissingConditionsPolicy
+		err       error
+		annotated bool
+	}{
+		{name: "skip", policy: SkipMissingConditions},
+		{name: "annotate", policy: AnnotateMissingConditions, annotated: true},
+		{name: "fail", policy: FailOnMissingConditions, err: ErrNoConditions},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			writes := 0
+			k8sClient := fake.NewClientBuilder().WithInterceptorFuncs(interceptor.Funcs{
+				SubResourceUpdate: func(ctx context.Context, c client.Client, subResourceName string, obj client.Object, opts ...client.SubResourceUpdateOption) error {
+					writes++
+					return nil
+				},
+			}).Build()
+			tp := sdktrace.NewTracerProvider(sdktrace.WithSampler(sdktrace.AlwaysSample()))
+			tracingClient := NewTracingClientWithOptions(k8sClient, WithTracerProvider(tp), WithMissingConditionsPolicy(tt.policy))
+
+			configMap := &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: "test-configmap", Namespace: "default"}}
+			err := tracingClient.Status().Update(context.Background(), configMap)
+			if tt.err != nil {
+				assert.ErrorIs(t, err, tt.err)
+				assert.Equal(t, 0, writes)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, 1, writes)
+			assert.Equal(t, tt.annotated, configMap.Annotations[constants.TraceIDAnnotation] != "")
+		})
+	}
+}
+
+func TestTraceConditionSemantics(t *testing.T) {
+	transitioned := metav1.NewTime(metav1.Now().Add(-time.Hour).Truncate(time.Second))
+	service := &corev1.Service{
+		ObjectMeta: metav1.ObjectMeta{Name: "test-service", Namespace: "default", Generation: 3},
+		Status: corev1.ServiceStatus{Conditions: []metav1.Condition{
+			{Type: "Ready", Status: metav1.ConditionTrue, Reason: "Started", ObservedGeneration: 2, LastTransitionTime: transitioned},
+			{Type: "SpanID", Status: metav1.ConditionTrue, Reason: TraceConditionReason, Message: "45f359cdc1c8ab06", ObservedGeneration: 2, LastTransitionTime: transitioned},
+			{Type: "SpanID", Status: metav1.ConditionTrue, Message: "duplicate"},
+		}},
+	}
+
+	traceID, _ := trace.TraceIDFromHex("f620f5cad0af940c294f980c5366a6a1")
+	spanID, _ := trace.SpanIDFromHex("b7ad6b7169203331")
+	spanContext := trace.NewSpanContext(trace.SpanContextConfig{TraceID: traceID, SpanID: spanID})
+	assert.NoError(t, setTraceConditions(spanContext, service, clientgoscheme.Scheme, TraceAndSpanIDConditions))
+
+	conditions := service.Status.Conditions
+	assert.Len(t, conditions, 3)
+	assert.Equal(t, int64(2), conditions[0].ObservedGeneration)
+	for _, condition := range conditions[1:] {
+		assert.Equal(t, metav1.ConditionTrue, condition.Status)
+		assert.Equal(t, TraceConditionReason, condition.Reason)
+		assert.Equal(t, int64(3), condition.ObservedGeneration)
+		assert.False(t, condition.LastTransitionTime.IsZero())
+	}
+	spanIDCondition := meta.FindStatusCondition(conditions, "SpanID")
+	assert.Equal(t, "b7ad6b7169203331", spanIDCondition.Message)
+	assert.Equal(t, transitioned, spanIDCondition.LastTransitionTime)
+	assert.Equal(t, "f620f5cad0af940c294f980c5366a6a1", meta.FindStatusCondition(conditions, "TraceID").Message)
+}
+
+func TestDeleteConditionsWithMultipleConditions(t *testing.T) {
+	newPod := func() *corev1.Pod {
+		return &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: "test-pod", Namespace: "default"},
+			Status: corev1.PodStatus{Conditions: []corev1.PodCondition{
+				{Type: corev1.PodScheduled, Status: corev1.ConditionTrue},
+				{Type: "TraceID", Status: corev1.ConditionTrue, Message: "trace"},
+				{Type: corev1.PodReady, Status: corev1.ConditionFalse},
+				{Type: "SpanID", Status: corev1.ConditionTrue, Message: "span"},
+				{Type: "TraceID", Status: corev1.ConditionTrue, Message: "duplicate"},
+				{Type: corev1.ContainersReady, Status: corev1.ConditionTrue},
+			}},
+		}
+	}
+	types := func(pod *corev1.Pod) []string {
+		var out []string
+		for _, c := range pod.Status.Conditions {
+			out = append(out, string(c.Type))
+		}
+		return out
+	}
+
+	t.Run("single type", func(t *testing.T) {
+		pod := newPod()
+		assert.NoError(t, deleteCondition("TraceID", pod, clientgoscheme.Scheme))
+		assert.Equal(t, []string{"PodScheduled", "Ready", "SpanID", "ContainersReady"}, types(pod))
+		assert.Equal(t, corev1.ConditionFalse, pod.Status.Conditions[1].Status)
+	})
+
+	t.Run("several types", func(t *testing.T) {
+		pod := newPod()
+		assert.NoError(t, deleteConditions(pod, clientgoscheme.Scheme, "TraceID", "SpanID"))
+		assert.Equal(t, []string{"PodScheduled", "Ready", "ContainersReady"}, types(pod))
+	})
+
+	t.Run("missing type", func(t *testing.T) {
+		pod := newPod()
+		assert.NoError(t, deleteCondition("Missing", pod, clientgoscheme.Scheme))
+		assert.Equal(t, types(newPod()), types(pod))
+	})
+
+	t.Run("input is not modified", func(t *testing.T) {
+		conditions := []metav1.Condition{{Type: "A"}, {Type: "TraceID"}, {Type: "B"}, {Type: "C"}}
+		out := removeConditions(conditions, "TraceID")
+		assert.Equal(t, []metav1.Condition{{Type: "A"}, {Type: "B"}, {Type: "C"}}, out)
+		assert.Equal(t, []metav1.Condition{{Type: "A"}, {Type: "TraceID"}, {Type: "B"}, {Type: "C"}}, conditions)
+	})
+
+	t.Run("unstructured", func(t *testing.T) {
+		widget := &unstructured.Unstructured{Object: map[string]interface{}{
+			"apiVersion": "example.com/v1",
+			"kind":       "Widget",
+			"metadata":   map[string]interface{}{"name": "test-widget", "namespace": "default"},
+		}}
+		conditions := []metav1.Condition{
+			{Type: "Ready", Status: metav1.ConditionTrue, Reason: "Ready"},
+			{Type: "TraceID", Status: metav1.ConditionTrue, Reason: TraceConditionReason, Message: "trace"},
+			{Type: "Synced", Status: metav1.ConditionFalse, Reason: "Pending"},
+		}
+		assert.NoError(t, setConditions(widget, conditions, clientgoscheme.Scheme))
+		assert.NoError(t, deleteCondition("TraceID", widget, clientgoscheme.Scheme))
+
+		got, err := getConditions(widget, clientgoscheme.Scheme)
+		assert.NoError(t, err)
+		assert.Len(t, got, 2)
+		assert.NotNil(t, meta.FindStatusCondition(got, "Ready"))
+		assert.NotNil(t, meta.FindStatusCondition(got, "Synced"))
+		_, err = getConditionMessage("TraceID", widget, clientgoscheme.Scheme)
+		assert.Error(t, err)
+	})
+}
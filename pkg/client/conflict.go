@@ -0,0 +1,39 @@
+package client
+
+import (
+	"context"
+	"fmt"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	// ConflictResourceVersionKey is the span event attribute holding the resourceVersion a write was attempted with
+	ConflictResourceVersionKey = attribute.Key("kubetracer.conflict.resource_version")
+	// ConflictCurrentResourceVersionKey is the span event attribute holding the resourceVersion stored in the API server
+	ConflictCurrentResourceVersionKey = attribute.Key("kubetracer.conflict.current_resource_version")
+)
+
+// recordConflict adds a span event with the conflicting resourceVersions to the span of the write and to its
+// parent span when err is a Conflict.  Since every retry of the write ends up as an event on the parent span,
+// the attempts of an optimistic-concurrency retry loop can be told apart from the reconcile span alone.
+func (tc *tracingClient) recordConflict(ctx context.Context, parent, span trace.Span, verb, kind string, obj client.Object, err error) {
+	if !apierrors.IsConflict(err) {
+		return
+	}
+
+	attrs := []attribute.KeyValue{ConflictResourceVersionKey.String(obj.GetResourceVersion())}
+	current := tc.newObjectLike(obj)
+	if getErr := tc.Reader.Get(ctx, client.ObjectKeyFromObject(obj), current); getErr == nil {
+		attrs = append(attrs, ConflictCurrentResourceVersionKey.String(current.GetResourceVersion()))
+	}
+
+	name := fmt.Sprintf("Conflict %s %s %s", verb, kind, obj.GetName())
+	span.AddEvent(name, trace.WithAttributes(attrs...))
+	if parent.SpanContext().IsValid() {
+		parent.AddEvent(name, trace.WithAttributes(attrs...))
+	}
+}
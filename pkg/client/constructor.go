@@ -0,0 +1,283 @@
+package client
+
+import (
+	"slices"
+	"time"
+
+	"github.com/go-logr/logr"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/trace"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/discovery"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// TracerName is the instrumentation name of the tracer NewTracingClientWithOptions takes from the TracerProvider
+const TracerName = "github.com/kubetracer/kubetracer-go"
+
+// Option configures the TracingClient built by NewTracingClientWithOptions
+type Option func(*clientOptions)
+
+type clientOptions struct {
+	reader           client.Reader
+	apiReader        client.Reader
+	scheme           *runtime.Scheme
+	logger           logr.Logger
+	tracerProvider   trace.TracerProvider
+	annotationPrefix string
+	traceIDKey       string
+	spanIDKey        string
+	readOnly         bool
+	allowNamespaces  []string
+	denyNamespaces   []string
+	kindPredicate    func(schema.GroupVersionKind) bool
+	sampler          SamplerFunc
+	spanNames        SpanNameFormatter
+	labels           LabelPropagation
+	copyOnWrite      bool
+	maxListLinks     int
+	conditions       ConditionFormat
+	noConditions     bool
+	traceTTL         time.Duration
+	format           PropagationFormat
+	compatible       bool
+
+	onSpanEnd         OnSpanEndFunc
+	lastOps           *lastOps
+	stackTraces       *stackTraces
+	statusDiscovery   discovery.ServerResourcesInterface
+	missingConditions MissingConditionsPolicy
+	endTraceRetry     *wait.Backoff
+}
+
+// WithReader sets the reader used for Get and List, e.g. the API reader of the manager to bypass the cache.
+// The wrapped client is used when not set.
+func WithReader(r client.Reader) Option {
+	return func(o *clientOptions) {
+		o.reader = r
+	}
+}
+
+// WithAPIReader sets the uncached reader serving the Gets and Lists given WithLiveRead, see
+// NewTracingClientWithAPIReader
+func WithAPIReader(r client.Reader) Option {
+	return func(o *clientOptions) {
+		o.apiReader = r
+	}
+}
+
+// WithScheme sets the scheme used to look up the kind of objects, the client-go scheme when not set
+func WithScheme(scheme *runtime.Scheme) Option {
+	return func(o *clientOptions) {
+		o.scheme = scheme
+	}
+}
+
+// WithLogger sets the logger of the TracingClient, nothing is logged when not set
+func WithLogger(l logr.Logger) Option {
+	return func(o *clientOptions) {
+		o.logger = l
+	}
+}
+
+// WithTracerProvider sets the TracerProvider the tracer is taken from, the global one when not set
+func WithTracerProvider(tp trace.TracerProvider) Option {
+	return func(o *clientOptions) {
+		o.tracerProvider = tp
+	}
+}
+
+// WithAnnotationPrefix stores the trace in annotations under prefix instead of kubetracer.io/, e.g.
+// "example.com/" writes example.com/trace-id and example.com/span-id.  Both controllers of a trace must use
+// the same prefix, and the predicates are unaware of it.
+func WithAnnotationPrefix(prefix string) Option {
+	return func(o *clientOptions) {
+		o.annotationPrefix = prefix
+	}
+}
+
+// WithTraceIDAnnotation stores the trace ID in the key annotation instead of kubetracer.io/trace-id, so
+// controllers tracing the same objects independently do not clobber each other's trace.  It takes precedence
+// over WithAnnotationPrefix.
+func WithTraceIDAnnotation(key string) Option {
+	return func(o *clientOptions) {
+		o.traceIDKey = key
+	}
+}
+
+// WithSpanIDAnnotation stores the span ID in the key annotation instead of kubetracer.io/span-id.  It takes
+// precedence over WithAnnotationPrefix.
+func WithSpanIDAnnotation(key string) Option {
+	return func(o *clientOptions) {
+		o.spanIDKey = key
+	}
+}
+
+// WithReadOnlyTracing records spans and continues the traces found on objects, but never writes the trace to
+// objects: no annotations, labels or status conditions, and EndTrace removes nothing.  Audit-style controllers
+// get trace correlation without writing to cluster objects.
+func WithReadOnlyTracing() Option {
+	return func(o *clientOptions) {
+		o.readOnly = true
+	}
+}
+
+// WithCopyOnWrite makes Create, Update and Patch write the trace to a deep copy of the object and send the copy,
+// leaving the caller's object untouched but for the resourceVersion and UID of the response.  Use it when the
+// objects are shared, e.g. taken from an informer cache, where modifying them in place causes surprising diffs
+// and data races.
+func WithCopyOnWrite() Option {
+	return func(o *clientOptions) {
+		o.copyOnWrite = true
+	}
+}
+
+// annotationKeys returns the annotations configured by the options, nil for the defaults
+func (o clientOptions) annotationKeys() *annotationKeys {
+	if o.annotationPrefix == "" && o.traceIDKey == "" && o.spanIDKey == "" {
+		return nil
+	}
+
+	keys := *defaultAnnotationKeys
+	if o.annotationPrefix != "" {
+		keys = *annotationKeysWithPrefix(o.annotationPrefix)
+	}
+	if o.traceIDKey != "" {
+		keys.traceID = o.traceIDKey
+	}
+	if o.spanIDKey != "" {
+		keys.spanID = o.spanIDKey
+	}
+	return &keys
+}
+
+// WithNamespaceFilter restricts the namespaces the trace is written to objects in: only in allow if it is not
+// empty, and never in deny, e.g. kube-system.  Spans are still recorded for the objects of all namespaces, and
+// cluster-scoped objects are not filtered.
+func WithNamespaceFilter(allow, deny []string) Option {
+	return func(o *clientOptions) {
+		o.allowNamespaces = allow
+		o.denyNamespaces = deny
+	}
+}
+
+// WithKindFilter restricts the trace to be written to objects of the kinds of gvks only, their version is
+// ignored.  Spans are still recorded for the objects of all kinds.  It replaces any WithKindPredicate.
+func WithKindFilter(gvks ...schema.GroupVersionKind) Option {
+	groupKinds := make([]schema.GroupKind, 0, len(gvks))
+	for _, gvk := range gvks {
+		groupKinds = append(groupKinds, gvk.GroupKind())
+	}
+	return WithKindPredicate(func(gvk schema.GroupVersionKind) bool {
+		return slices.Contains(groupKinds, gvk.GroupKind())
+	})
+}
+
+// WithKindPredicate restricts the trace to be written to objects of the kinds for which predicate returns true,
+// e.g. to never annotate high-churn kinds such as Events and Leases.  Spans are still recorded for the objects
+// of all kinds.  It replaces any WithKindFilter.
+func WithKindPredicate(predicate func(gvk schema.GroupVersionKind) bool) Option {
+	return func(o *clientOptions) {
+		o.kindPredicate = predicate
+	}
+}
+
+// WithSampler decides per operation, before its span is started, whether it is traced, e.g. to sample out
+// status updates of Pods while always tracing Deletes.  Operations sampled out are passed straight to the
+// underlying Client as with WithoutTracing: no span is recorded and the trace is not written to the object.
+func WithSampler(sampler SamplerFunc) Option {
+	return func(o *clientOptions) {
+		o.sampler = sampler
+	}
+}
+
+// WithSpanNameFormatter names the spans of operations with format instead of "Update Deployment deploy01", e.g.
+// to standardize span names across an organization with lowercase verbs or group-qualified kinds
+func WithSpanNameFormatter(format SpanNameFormatter) Option {
+	return func(o *clientOptions) {
+		o.spanNames = format
+	}
+}
+
+// WithLabelPropagation writes the trace and span IDs to labels as well as, or instead of, the annotations, for
+// GitOps tooling which strips unknown annotations but preserves labels.  The IDs are read from either, but the
+// predicates only consider the annotations.
+func WithLabelPropagation(mode LabelPropagation) Option {
+	return func(o *clientOptions) {
+		o.labels = mode
+	}
+}
+
+// policy returns the trace policy configured by the options, nil to write the trace to every object
+func (o clientOptions) policy() *tracePolicy {
+	if !o.readOnly && len(o.allowNamespaces) == 0 && len(o.denyNamespaces) == 0 && o.kindPredicate == nil {
+		return nil
+	}
+	return &tracePolicy{
+		readOnly:        o.readOnly,
+		allowNamespaces: o.allowNamespaces,
+		denyNamespaces:  o.denyNamespaces,
+		kindPredicate:   o.kindPredicate,
+		scheme:          o.scheme,
+	}
+}
+
+// NewTracingClientWithOptions wraps c in a TracingClient configured by opts.  Features are added as new
+// options, so unlike NewTracingClient its signature stays stable.
+func NewTracingClientWithOptions(c client.Client, opts ...Option) TracingClient {
+	options := clientOptions{
+		scheme:         clientgoscheme.Scheme,
+		logger:         logr.Discard(),
+		tracerProvider: otel.GetTracerProvider(),
+	}
+	for _, opt := range opts {
+		opt(&options)
+	}
+	if options.reader == nil {
+		options.reader = c
+	}
+
+	tc := &tracingClient{
+		scheme: options.scheme,
+		Client: c,
+		Reader: options.reader,
+		Tracer: options.tracerProvider.Tracer(TracerName),
+		Logger: options.logger,
+
+		apiReader:   options.apiReader,
+		reconciles:  newReconcileTimes(),
+		policy:      options.policy(),
+		copyOnWrite: options.copyOnWrite,
+		sampler:     options.sampler,
+		spanNames:   options.spanNames,
+
+		maxListLinks: options.maxListLinks,
+
+		onSpanEnd:         options.onSpanEnd,
+		lastOps:           options.lastOps,
+		stackTraces:       options.stackTraces,
+		missingConditions: options.missingConditions,
+		endTraceRetry:     options.endTraceRetry,
+	}
+	if options.statusDiscovery != nil {
+		tc.statusSubresources = &statusSubresources{
+			discovery: options.statusDiscovery,
+			known:     map[schema.GroupVersionKind]bool{},
+		}
+	}
+	if keys := options.annotationKeys(); keys != nil || options.labels != NoLabels || options.conditions != TraceAndSpanIDConditions || options.noConditions || options.traceTTL > 0 || options.format != AllFormats || options.compatible {
+		tc.propagation = &tracePropagation{
+			format:       options.format,
+			compatible:   options.compatible,
+			keys:         keys,
+			labels:       options.labels,
+			conditions:   options.conditions,
+			noConditions: options.noConditions,
+			ttl:          options.traceTTL,
+		}
+	}
+	return tc
+}
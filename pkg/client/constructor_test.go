@@ -0,0 +1,234 @@
+package client
+
+import (
+	"context"
+	"testing"
+
+	constants "github.com/kubetracer/kubetracer-go/pkg/constants"
+	"github.com/stretchr/testify/assert"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestNewTracingClientWithOptionsAnnotationPrefix(t *testing.T) {
+	pod := &corev1.Pod{ObjectMeta: metav1.ObjectMeta{
+		Name:      "test-pod",
+		Namespace: "default",
+		Annotations: map[string]string{
+			"example.com/trace-id": "f620f5cad0af940c294f980c5366a6a1",
+			"example.com/span-id":  "45f359cdc1c8ab06",
+		},
+	}}
+	k8sClient := fake.NewClientBuilder().WithObjects(pod).Build()
+	recorder := tracetest.NewSpanRecorder()
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSampler(sdktrace.AlwaysSample()), sdktrace.WithSpanProcessor(recorder))
+	tracingClient := NewTracingClientWithOptions(k8sClient, WithTracerProvider(tp), WithAnnotationPrefix("example.com/"))
+
+	assert.NoError(t, tracingClient.Update(context.Background(), pod))
+
+	spans := recorder.Ended()
+	assert.Len(t, spans, 1)
+	assert.Equal(t, "f620f5cad0af940c294f980c5366a6a1", spans[0].SpanContext().TraceID().String())
+	assert.Equal(t, spans[0].SpanContext().SpanID().String(), pod.Annotations["example.com/span-id"])
+	assert.NotContains(t, pod.Annotations, constants.TraceIDAnnotation)
+	assert.NotContains(t, pod.Annotations, constants.SpanIDAnnotation)
+}
+
+func TestNewTracingClientWithOptionsAnnotationKeys(t *testing.T) {
+	pod := &corev1.Pod{ObjectMeta: metav1.ObjectMeta{
+		Name:      "test-pod",
+		Namespace: "default",
+		Annotations: map[string]string{
+			constants.TraceIDAnnotation: "0af7651916cd43dd8448eb211c80319c",
+			constants.SpanIDAnnotation:  "b7ad6b7169203331",
+			"team-a/trace":              "f620f5cad0af940c294f980c5366a6a1",
+			"team-a/span":               "45f359cdc1c8ab06",
+		},
+	}}
+	k8sClient := fake.NewClientBuilder().WithObjects(pod).Build()
+	recorder := tracetest.NewSpanRecorder()
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSampler(sdktrace.AlwaysSample()), sdktrace.WithSpanProcessor(recorder))
+	tracingClient := NewTracingClientWithOptions(k8sClient, WithTracerProvider(tp),
+		WithTraceIDAnnotation("team-a/trace"), WithSpanIDAnnotation("team-a/span"))
+
+	assert.NoError(t, tracingClient.Update(context.Background(), pod))
+
+	spans := recorder.Ended()
+	assert.Len(t, spans, 1)
+	assert.Equal(t, "f620f5cad0af940c294f980c5366a6a1", spans[0].SpanContext().TraceID().String())
+	assert.Equal(t, spans[0].SpanContext().SpanID().String(), pod.Annotations["team-a/span"])
+
+	// The trace of the other controller is left untouched
+	assert.Equal(t, "0af7651916cd43dd8448eb211c80319c", pod.Annotations[constants.TraceIDAnnotation])
+	assert.Equal(t, "b7ad6b7169203331", pod.Annotations[constants.SpanIDAnnotation])
+}
+
+func TestNewTracingClientWithOptionsReadOnlyTracing(t *testing.T) {
+	pod := &corev1.Pod{ObjectMeta: metav1.ObjectMeta{
+		Name:      "test-pod",
+		Namespace: "default",
+		Annotations: map[string]string{
+			constants.TraceIDAnnotation: "f620f5cad0af940c294f980c5366a6a1",
+			constants.SpanIDAnnotation:  "45f359cdc1c8ab06",
+		},
+	}}
+	k8sClient := fake.NewClientBuilder().WithObjects(pod).Build()
+	recorder := tracetest.NewSpanRecorder()
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSampler(sdktrace.AlwaysSample()), sdktrace.WithSpanProcessor(recorder))
+	tracingClient := NewTracingClientWithOptions(k8sClient, WithTracerProvider(tp), WithReadOnlyTracing())
+
+	// the trace on the object is continued, but the request's trace is not left on it
+	key := client.ObjectKey{Name: "0af7651916cd43dd8448eb211c80319c;b7ad6b7169203331;ConfigMap;test-configmap;test-pod", Namespace: "default"}
+	retrievedPod := &corev1.Pod{}
+	ctx, span, err := tracingClient.StartTrace(context.Background(), key, retrievedPod)
+	assert.NoError(t, err)
+	assert.Equal(t, "0af7651916cd43dd8448eb211c80319c", span.SpanContext().TraceID().String())
+	assert.Equal(t, "45f359cdc1c8ab06", retrievedPod.Annotations[constants.SpanIDAnnotation])
+
+	retrievedPod.Labels = map[string]string{"audited": "true"}
+	assert.NoError(t, tracingClient.Update(ctx, retrievedPod))
+	assert.NoError(t, tracingClient.Status().Update(ctx, retrievedPod))
+	configMap := &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: "audit", Namespace: "default"}}
+	assert.NoError(t, tracingClient.Create(ctx, configMap))
+	_, err = tracingClient.EndTrace(ctx, retrievedPod)
+	assert.NoError(t, err)
+	span.End()
+
+	stored := &corev1.Pod{}
+	assert.NoError(t, k8sClient.Get(context.Background(), client.ObjectKeyFromObject(pod), stored))
+	assert.Equal(t, pod.Annotations, stored.Annotations)
+	assert.Empty(t, stored.Status.Conditions)
+	storedConfigMap := &corev1.ConfigMap{}
+	assert.NoError(t, k8sClient.Get(context.Background(), client.ObjectKeyFromObject(configMap), storedConfigMap))
+	assert.Empty(t, storedConfigMap.Annotations)
+
+	spans := recorder.Ended()
+	assert.Len(t, spans, 5)
+	for _, span := range spans {
+		assert.Equal(t, "0af7651916cd43dd8448eb211c80319c", span.SpanContext().TraceID().String())
+	}
+}
+
+func TestNewTracingClientWithOptionsCopyOnWrite(t *testing.T) {
+	pod := &corev1.Pod{ObjectMeta: metav1.ObjectMeta{
+		Name:        "test-pod",
+		Namespace:   "default",
+		Annotations: map[string]string{"owner": "team-a"},
+	}}
+	k8sClient := fake.NewClientBuilder().WithObjects(pod).Build()
+	recorder := tracetest.NewSpanRecorder()
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSampler(sdktrace.AlwaysSample()), sdktrace.WithSpanProcessor(recorder))
+	tracingClient := NewTracingClientWithOptions(k8sClient, WithTracerProvider(tp), WithCopyOnWrite())
+
+	// e.g. an object of an informer cache
+	cached := &corev1.Pod{}
+	assert.NoError(t, k8sClient.Get(context.Background(), client.ObjectKeyFromObject(pod), cached))
+	resourceVersion := cached.ResourceVersion
+
+	assert.NoError(t, tracingClient.Update(context.Background(), cached))
+	assert.Equal(t, map[string]string{"owner": "team-a"}, cached.Annotations)
+	assert.NotEqual(t, resourceVersion, cached.ResourceVersion)
+
+	stored := &corev1.Pod{}
+	assert.NoError(t, k8sClient.Get(context.Background(), client.ObjectKeyFromObject(pod), stored))
+	assert.Equal(t, stored.ResourceVersion, cached.ResourceVersion)
+	assert.NotEmpty(t, stored.Annotations[constants.TraceIDAnnotation])
+
+	generated := &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{GenerateName: "test-", Namespace: "default"}}
+	assert.NoError(t, tracingClient.Create(context.Background(), generated))
+	assert.Empty(t, generated.Annotations)
+	assert.NotEmpty(t, generated.Name)
+	assert.NotEmpty(t, generated.ResourceVersion)
+}
+
+func TestNewTracingClientWithOptionsNamespaceFilter(t *testing.T) {
+	systemPod := &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "system-pod", Namespace: "kube-system"}}
+	teamPod := &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "team-pod", Namespace: "team-a"}}
+	defaultPod := &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "default-pod", Namespace: "default"}}
+	node := &corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: "node"}}
+	k8sClient := fake.NewClientBuilder().WithObjects(systemPod, teamPod, defaultPod, node).Build()
+	recorder := tracetest.NewSpanRecorder()
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSampler(sdktrace.AlwaysSample()), sdktrace.WithSpanProcessor(recorder))
+
+	denying := NewTracingClientWithOptions(k8sClient, WithTracerProvider(tp), WithNamespaceFilter(nil, []string{"kube-system"}))
+	assert.NoError(t, denying.Update(context.Background(), systemPod))
+	assert.NoError(t, denying.Update(context.Background(), defaultPod))
+	assert.NotContains(t, systemPod.Annotations, constants.TraceIDAnnotation)
+	assert.Contains(t, defaultPod.Annotations, constants.TraceIDAnnotation)
+
+	allowing := NewTracingClientWithOptions(k8sClient, WithTracerProvider(tp), WithNamespaceFilter([]string{"team-a"}, nil))
+	assert.NoError(t, allowing.Update(context.Background(), teamPod))
+	assert.NoError(t, allowing.Status().Update(context.Background(), systemPod))
+	assert.NoError(t, allowing.Update(context.Background(), node))
+	assert.Contains(t, teamPod.Annotations, constants.TraceIDAnnotation)
+	assert.Empty(t, systemPod.Status.Conditions)
+	assert.Contains(t, node.Annotations, constants.TraceIDAnnotation)
+
+	// spans are recorded regardless
+	assert.Len(t, recorder.Ended(), 5)
+}
+
+func TestNewTracingClientWithOptionsKindFilter(t *testing.T) {
+	pod := &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "test-pod", Namespace: "default"}}
+	event := &corev1.Event{ObjectMeta: metav1.ObjectMeta{Name: "test-event", Namespace: "default"}}
+	k8sClient := fake.NewClientBuilder().WithObjects(pod, event).Build()
+	recorder := tracetest.NewSpanRecorder()
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSampler(sdktrace.AlwaysSample()), sdktrace.WithSpanProcessor(recorder))
+
+	filtering := NewTracingClientWithOptions(k8sClient, WithTracerProvider(tp), WithKindFilter(corev1.SchemeGroupVersion.WithKind("Pod")))
+	assert.NoError(t, filtering.Update(context.Background(), pod))
+	assert.NoError(t, filtering.Update(context.Background(), event))
+	assert.Contains(t, pod.Annotations, constants.TraceIDAnnotation)
+	assert.NotContains(t, event.Annotations, constants.TraceIDAnnotation)
+
+	pod.Annotations, event.Annotations = nil, nil
+	excluding := NewTracingClientWithOptions(k8sClient, WithTracerProvider(tp), WithKindPredicate(func(gvk schema.GroupVersionKind) bool {
+		return gvk.Kind != "Event" && gvk.Kind != "Lease"
+	}))
+	assert.NoError(t, excluding.Update(context.Background(), pod))
+	assert.NoError(t, excluding.Update(context.Background(), event))
+	assert.Contains(t, pod.Annotations, constants.TraceIDAnnotation)
+	assert.NotContains(t, event.Annotations, constants.TraceIDAnnotation)
+
+	// spans are recorded regardless
+	assert.Len(t, recorder.Ended(), 4)
+}
+
+func TestNewTracingClientWithOptionsLabelPropagation(t *testing.T) {
+	pod := &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "test-pod", Namespace: "default"}}
+	k8sClient := fake.NewClientBuilder().WithObjects(pod).Build()
+	recorder := tracetest.NewSpanRecorder()
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSampler(sdktrace.AlwaysSample()), sdktrace.WithSpanProcessor(recorder))
+
+	both := NewTracingClientWithOptions(k8sClient, WithTracerProvider(tp), WithLabelPropagation(LabelsAndAnnotations))
+	assert.NoError(t, both.Update(context.Background(), pod))
+	assert.Equal(t, pod.Annotations[constants.TraceIDAnnotation], pod.Labels[constants.TraceIDAnnotation])
+	assert.Equal(t, pod.Annotations[constants.SpanIDAnnotation], pod.Labels[constants.SpanIDAnnotation])
+
+	pod.Annotations, pod.Labels = nil, nil
+	labelsOnly := NewTracingClientWithOptions(k8sClient, WithTracerProvider(tp), WithLabelPropagation(LabelsOnly))
+	assert.NoError(t, labelsOnly.Update(context.Background(), pod))
+	assert.Empty(t, pod.Annotations[constants.TraceIDAnnotation])
+	traceID := pod.Labels[constants.TraceIDAnnotation]
+	assert.Len(t, traceID, 32)
+	assert.Len(t, pod.Labels[constants.SpanIDAnnotation], 16)
+
+	// the controller reconciling the object continues the trace from the labels
+	retrievedPod := &corev1.Pod{}
+	_, span, err := labelsOnly.StartTrace(context.Background(), client.ObjectKeyFromObject(pod), retrievedPod)
+	assert.NoError(t, err)
+	assert.Equal(t, traceID, span.SpanContext().TraceID().String())
+	span.End()
+
+	_, err = labelsOnly.EndTrace(context.Background(), retrievedPod)
+	assert.NoError(t, err)
+	stored := &corev1.Pod{}
+	assert.NoError(t, k8sClient.Get(context.Background(), client.ObjectKeyFromObject(pod), stored))
+	assert.NotContains(t, stored.Labels, constants.TraceIDAnnotation)
+	assert.NotContains(t, stored.Labels, constants.SpanIDAnnotation)
+}
@@ -0,0 +1,82 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	constants "github.com/kubetracer/kubetracer-go/pkg/constants"
+	"go.opentelemetry.io/otel/trace"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/apiutil"
+)
+
+// ConvertWithTrace preserves the trace of src across a CRD conversion.  It runs convert, the body of a
+// ConvertTo or ConvertFrom implementation, in a conversion span joining the trace of src, then copies the
+// kubetracer.io annotations and the trace conditions of src to dst.  Conversions that rebuild
+// the metadata would otherwise drop the trace context mid-chain:
+//
+//	func (src *CronJob) ConvertTo(dstRaw conversion.Hub) error {
+//		dst := dstRaw.(*v1.CronJob)
+//		return kubetracer.ConvertWithTrace(otel.Tracer("conversion"), scheme, src, dst, func() error {
+//			...
+//		})
+//	}
+//
+// scheme must know both versions.  Conditions are only copied if scheme is not nil.
+func ConvertWithTrace(tracer trace.Tracer, scheme *runtime.Scheme, src, dst client.Object, convert func() error) error {
+	ctx := context.Background()
+	if spanContext, err := TraceContextFromObject(src, scheme); err == nil {
+		ctx = trace.ContextWithRemoteSpanContext(ctx, spanContext)
+	}
+
+	_, span := tracer.Start(ctx, fmt.Sprintf("Convert %s %s", conversionVersions(scheme, src, dst), src.GetName()))
+	defer span.End()
+
+	if err := convert(); err != nil {
+		span.RecordError(err)
+		return err
+	}
+
+	copyTraceAnnotations(src, dst)
+	if scheme == nil {
+		return nil
+	}
+	// kinds without conditions in the target version still convert
+	if err := copyTraceConditions(src, dst, scheme); err != nil {
+		span.RecordError(err)
+	}
+	return nil
+}
+
+// conversionVersions describes a conversion as Kind version->version for the span name
+func conversionVersions(scheme *runtime.Scheme, src, dst client.Object) string {
+	if scheme == nil {
+		return ""
+	}
+	srcGVK, err := apiutil.GVKForObject(src, scheme)
+	if err != nil {
+		return ""
+	}
+	dstGVK, err := apiutil.GVKForObject(dst, scheme)
+	if err != nil {
+		return srcGVK.Kind
+	}
+	return fmt.Sprintf("%s %s->%s", srcGVK.Kind, srcGVK.GroupVersion(), dstGVK.GroupVersion())
+}
+
+// copyTraceAnnotations copies every kubetracer.io and otel.kubetracer.io annotation of src to dst
+func copyTraceAnnotations(src, dst client.Object) {
+	for key, value := range src.GetAnnotations() {
+		if !strings.HasPrefix(key, constants.AnnotationPrefix) && !strings.HasPrefix(key, constants.PropagatorAnnotationPrefix) {
+			continue
+		}
+		if dst.GetAnnotations() == nil {
+			dst.SetAnnotations(map[string]string{})
+		}
+		annotations := dst.GetAnnotations()
+		annotations[key] = value
+		dst.SetAnnotations(annotations)
+	}
+}
@@ -0,0 +1,63 @@
+package client
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/kubetracer/kubetracer-go/pkg/constants"
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+func TestConvertWithTrace(t *testing.T) {
+	// Create a scheme
+	scheme := runtime.NewScheme()
+	corev1.AddToScheme(scheme)
+
+	src := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "test-pod",
+			Annotations: map[string]string{
+				constants.TraceIDAnnotation: "f620f5cad0af940c294f980c5366a6a1",
+				constants.SpanIDAnnotation:  "45f359cdc1c8ab06",
+				constants.ActorAnnotation:   "admin",
+				"example.com/other":         "value",
+			},
+		},
+		Status: corev1.PodStatus{
+			Conditions: []corev1.PodCondition{
+				{Type: "TraceID", Message: "f620f5cad0af940c294f980c5366a6a1"},
+				{Type: "SpanID", Message: "45f359cdc1c8ab06"},
+			},
+		},
+	}
+
+	t.Run("trace is preserved", func(t *testing.T) {
+		// The conversion rebuilds the metadata, dropping the annotations
+		dst := &corev1.Pod{}
+		err := ConvertWithTrace(initTracer(), scheme, src, dst, func() error {
+			dst.ObjectMeta = metav1.ObjectMeta{Name: src.Name}
+			return nil
+		})
+		assert.NoError(t, err)
+		assert.Equal(t, "f620f5cad0af940c294f980c5366a6a1", dst.Annotations[constants.TraceIDAnnotation])
+		assert.Equal(t, "45f359cdc1c8ab06", dst.Annotations[constants.SpanIDAnnotation])
+		assert.Equal(t, "admin", dst.Annotations[constants.ActorAnnotation])
+		assert.NotContains(t, dst.Annotations, "example.com/other")
+
+		message, err := getConditionMessage("TraceID", dst, scheme)
+		assert.NoError(t, err)
+		assert.Equal(t, "f620f5cad0af940c294f980c5366a6a1", message)
+	})
+
+	t.Run("conversion error", func(t *testing.T) {
+		dst := &corev1.Pod{}
+		err := ConvertWithTrace(initTracer(), scheme, src, dst, func() error {
+			return errors.New("conversion failed")
+		})
+		assert.Error(t, err)
+		assert.Empty(t, dst.Annotations)
+	})
+}
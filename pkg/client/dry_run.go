@@ -0,0 +1,62 @@
+package client
+
+import (
+	"go.opentelemetry.io/otel/trace"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// isDryRun reports whether opts make the write a dry run, e.g. given client.DryRunAll
+func isDryRun[O any](opts []O) bool {
+	for _, opt := range opts {
+		var dryRun []string
+		switch o := any(opt).(type) {
+		case client.CreateOption:
+			options := &client.CreateOptions{}
+			o.ApplyToCreate(options)
+			dryRun = options.DryRun
+		case client.UpdateOption:
+			options := &client.UpdateOptions{}
+			o.ApplyToUpdate(options)
+			dryRun = options.DryRun
+		case client.PatchOption:
+			options := &client.PatchOptions{}
+			o.ApplyToPatch(options)
+			dryRun = options.DryRun
+		case client.DeleteOption:
+			options := &client.DeleteOptions{}
+			o.ApplyToDelete(options)
+			dryRun = options.DryRun
+		case client.DeleteAllOfOption:
+			options := &client.DeleteAllOfOptions{}
+			o.ApplyToDeleteAllOf(options)
+			dryRun = options.DryRun
+		case client.SubResourceCreateOption:
+			options := &client.SubResourceCreateOptions{}
+			o.ApplyToSubResourceCreate(options)
+			dryRun = options.DryRun
+		case client.SubResourceUpdateOption:
+			options := &client.SubResourceUpdateOptions{}
+			o.ApplyToSubResourceUpdate(options)
+			dryRun = options.DryRun
+		case client.SubResourcePatchOption:
+			options := &client.SubResourcePatchOptions{}
+			o.ApplyToSubResourcePatch(options)
+			dryRun = options.DryRun
+		}
+		if len(dryRun) > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// recordDryRun tags span with k8s.dry_run=true if opts make the write a dry run, and reports whether they do.
+// The trace is not written to the objects of dry runs, which are not persisted anyway, so that dry-run
+// reconciles leave the propagation of real traces alone.
+func recordDryRun[O any](span trace.Span, opts []O) bool {
+	if !isDryRun(opts) {
+		return false
+	}
+	span.SetAttributes(DryRunKey.Bool(true))
+	return true
+}
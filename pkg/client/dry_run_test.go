@@ -0,0 +1,43 @@
+package client
+
+import (
+	"context"
+	"testing"
+
+	constants "github.com/kubetracer/kubetracer-go/pkg/constants"
+	"github.com/stretchr/testify/assert"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestDryRun(t *testing.T) {
+	pod := &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "test-pod", Namespace: "default"}}
+	k8sClient := fake.NewClientBuilder().WithObjects(pod).WithStatusSubresource(pod).Build()
+	recorder := tracetest.NewSpanRecorder()
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSampler(sdktrace.AlwaysSample()), sdktrace.WithSpanProcessor(recorder))
+	tracingClient := NewTracingClientWithOptions(k8sClient, WithTracerProvider(tp))
+
+	created := &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "dry-pod", Namespace: "default"}}
+	assert.NoError(t, tracingClient.Create(context.Background(), created, client.DryRunAll))
+	assert.Empty(t, created.Annotations[constants.TraceIDAnnotation])
+
+	updated := &corev1.Pod{}
+	assert.NoError(t, k8sClient.Get(context.Background(), client.ObjectKeyFromObject(pod), updated))
+	assert.NoError(t, tracingClient.Update(context.Background(), updated, client.DryRunAll))
+	assert.Empty(t, updated.Annotations[constants.TraceIDAnnotation])
+
+	assert.NoError(t, tracingClient.Status().Update(context.Background(), updated, client.DryRunAll))
+	assert.Empty(t, updated.Status.Conditions)
+
+	for _, span := range recorder.Ended() {
+		assert.Contains(t, span.Attributes(), DryRunKey.Bool(true), span.Name())
+	}
+
+	assert.NoError(t, tracingClient.Update(context.Background(), updated))
+	assert.NotEmpty(t, updated.Annotations[constants.TraceIDAnnotation])
+	assert.NotContains(t, recorder.Ended()[3].Attributes(), DryRunKey.Bool(true))
+}
@@ -0,0 +1,43 @@
+package client
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"go.opentelemetry.io/otel/attribute"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// WithEndTraceRetry retries EndTrace with backoff when a cleanup patch fails with a Conflict, e.g.
+// retry.DefaultRetry.  Each retry reads the object again, leaves it alone if it carries another trace by now,
+// and reapplies the cleanup.
+func WithEndTraceRetry(backoff wait.Backoff) Option {
+	return func(o *clientOptions) {
+		o.endTraceRetry = &backoff
+	}
+}
+
+// EndTraceStatusInPatchKey is the attribute of the "Patch metadata" event of EndTrace telling whether the status
+// was cleaned up by the same patch, for kinds without a status subresource
+const EndTraceStatusInPatchKey = attribute.Key("kubetracer.end_trace.status_in_patch")
+
+// mergeStatusRemoval adds the changes from obj to cleaned, which differs by the trace removed from its status, to
+// the patch content
+func mergeStatusRemoval(content map[string]interface{}, obj, cleaned client.Object) error {
+	data, err := client.MergeFrom(obj).Data(cleaned)
+	if err != nil {
+		return fmt.Errorf("problem building the status patch: %w", err)
+	}
+	statusContent := map[string]interface{}{}
+	if err := json.Unmarshal(data, &statusContent); err != nil {
+		return fmt.Errorf("problem building the status patch: %w", err)
+	}
+
+	for key, value := range statusContent {
+		if key != "metadata" {
+			content[key] = value
+		}
+	}
+	return nil
+}
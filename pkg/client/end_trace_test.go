@@ -0,0 +1,332 @@
+package client
+
+import (
+	"context"
+	"testing"
+
+	"github.com/go-logr/logr"
+	constants "github.com/kubetracer/kubetracer-go/pkg/constants"
+	"github.com/stretchr/testify/assert"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/util/retry"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/client/interceptor"
+)
+
+func TestWithEndTraceRetry(t *testing.T) {
+	tests := []struct {
+		name string
+		// concurrentTraceID is written by another controller along with the conflict
+		concurrentTraceID string
+		wantTraceID       string
+		wantPatches       int
+	}{
+		{name: "cleanup is reapplied", wantPatches: 2},
+		{name: "newer trace is kept", concurrentTraceID: "0af7651916cd43dd8448eb211c80319c", wantTraceID: "0af7651916cd43dd8448eb211c80319c", wantPatches: 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pod := &corev1.Pod{ObjectMeta: metav1.ObjectMeta{
+				Name:      "test-pod",
+				Namespace: "default",
+				Annotations: map[string]string{
+					constants.TraceIDAnnotation: "f620f5cad0af940c294f980c5366a6a1",
+					constants.SpanIDAnnotation:  "45f359cdc1c8ab06",
+				},
+			}}
+			patches := 0
+			k8sClient := fake.NewClientBuilder().WithObjects(pod).WithInterceptorFuncs(interceptor.Funcs{
+				Patch: func(ctx context.Context, c client.WithWatch, obj client.Object, patch client.Patch, opts ...client.PatchOption) error {
+					patches++
+					if patches == 1 {
+						if tt.concurrentTraceID != "" {
+							current := &corev1.Pod{}
+							assert.NoError(t, c.Get(ctx, client.ObjectKeyFromObject(obj), current))
+							current.Annotations[constants.TraceIDAnnotation] = tt.concurrentTraceID
+							assert.NoError(t, c.Update(ctx, current))
+						}
+						return apierrors.NewConflict(schema.GroupResource{Resource: "pods"}, obj.GetName(), nil)
+					}
+					return c.Patch(ctx, obj, patch, opts...)
+				},
+			}).Build()
+			ctx := context.Background()
+
+			tracedPod := &corev1.Pod{}
+			assert.NoError(t, k8sClient.Get(ctx, client.ObjectKeyFromObject(pod), tracedPod))
+			tracingClient := NewTracingClientWithOptions(k8sClient, WithTracerProvider(sdktrace.NewTracerProvider()), WithEndTraceRetry(retry.DefaultRetry))
+			_, err := tracingClient.EndTrace(ctx, tracedPod)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.wantPatches, patches)
+
+			current := &corev1.Pod{}
+			assert.NoError(t, k8sClient.Get(ctx, client.ObjectKeyFromObject(pod), current))
+			assert.Equal(t, tt.wantTraceID, current.Annotations[constants.TraceIDAnnotation])
+		})
+	}
+}
+
+func TestEndTraceConflictWithoutRetry(t *testing.T) {
+	pod := &corev1.Pod{ObjectMeta: metav1.ObjectMeta{
+		Name:        "test-pod",
+		Namespace:   "default",
+		Annotations: map[string]string{constants.TraceIDAnnotation: "f620f5cad0af940c294f980c5366a6a1"},
+	}}
+	k8sClient := fake.NewClientBuilder().WithObjects(pod).WithInterceptorFuncs(interceptor.Funcs{
+		Patch: func(ctx context.Context, c client.WithWatch, obj client.Object, patch client.Patch, opts ...client.PatchOption) error {
+			return apierrors.NewConflict(schema.GroupResource{Resource: "pods"}, obj.GetName(), nil)
+		},
+	}).Build()
+	tracingClient := NewTracingClient(k8sClient, k8sClient, sdktrace.NewTracerProvider().Tracer("kubetracer"), logr.Discard())
+
+	tracedPod := &corev1.Pod{}
+	assert.NoError(t, k8sClient.Get(context.Background(), client.ObjectKeyFromObject(pod), tracedPod))
+	_, err := tracingClient.EndTrace(context.Background(), tracedPod)
+	assert.True(t, apierrors.IsConflict(err))
+}
+
+func TestEndTracePatches(t *testing.T) {
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "test-pod",
+			Namespace: "default",
+			Annotations: map[string]string{
+				constants.TraceIDAnnotation: "f620f5cad0af940c294f980c5366a6a1",
+				constants.SpanIDAnnotation:  "45f359cdc1c8ab06",
+			},
+		},
+		Status: corev1.PodStatus{
+			Conditions: []corev1.PodCondition{
+				{Type: "TraceID", Message: "f620f5cad0af940c294f980c5366a6a1"},
+				{Type: "SpanID", Message: "45f359cdc1c8ab06"},
+			},
+		},
+	}
+	var patches, statusPatches []string
+	k8sClient := fake.NewClientBuilder().WithObjects(pod).WithInterceptorFuncs(interceptor.Funcs{
+		Patch: func(ctx context.Context, c client.WithWatch, obj client.Object, patch client.Patch, opts ...client.PatchOption) error {
+			data, _ := patch.Data(obj)
+			patches = append(patches, string(data))
+			return c.Patch(ctx, obj, patch, opts...)
+		},
+		SubResourcePatch: func(ctx context.Context, c client.Client, subResourceName string, obj client.Object, patch client.Patch, opts ...client.SubResourcePatchOption) error {
+			data, _ := patch.Data(obj)
+			statusPatches = append(statusPatches, string(data))
+			return c.SubResource(subResourceName).Patch(ctx, obj, patch, opts...)
+		},
+	}).Build()
+	recorder := tracetest.NewSpanRecorder()
+	tracer := sdktrace.NewTracerProvider(sdktrace.WithSampler(sdktrace.AlwaysSample()), sdktrace.WithSpanProcessor(recorder)).Tracer("kubetracer")
+	tracingClient := NewTracingClient(k8sClient, k8sClient, tracer, logr.Discard())
+
+	tracedPod := &corev1.Pod{}
+	assert.NoError(t, k8sClient.Get(context.Background(), client.ObjectKeyFromObject(pod), tracedPod))
+	_, err := tracingClient.EndTrace(context.Background(), tracedPod)
+	assert.NoError(t, err)
+
+	// Pods have a status subresource, the status patch is guarded by the resourceVersion of the first one
+	assert.Len(t, patches, 1)
+	assert.NotContains(t, patches[0], "status")
+	assert.Len(t, statusPatches, 1)
+	assert.Contains(t, statusPatches[0], `"resourceVersion"`)
+
+	// Both patches are recorded on the span of EndTrace
+	spans := recorder.Ended()
+	assert.Len(t, spans, 1)
+	var events []string
+	for _, event := range spans[0].Events() {
+		events = append(events, event.Name)
+	}
+	assert.Equal(t, []string{"Patch metadata", "Patch status"}, events)
+}
+
+func TestEndTraceChangedSpanID(t *testing.T) {
+	pod := &corev1.Pod{ObjectMeta: metav1.ObjectMeta{
+		Name:      "test-pod",
+		Namespace: "default",
+		Annotations: map[string]string{
+			constants.TraceIDAnnotation: "f620f5cad0af940c294f980c5366a6a1",
+			constants.SpanIDAnnotation:  "b7ad6b7169203331",
+		},
+	}}
+	k8sClient := fake.NewClientBuilder().WithObjects(pod).Build()
+	tracingClient := NewTracingClient(k8sClient, k8sClient, sdktrace.NewTracerProvider().Tracer("kubetracer"), logr.Discard())
+
+	// a later span of the same trace was written after obj was read
+	stale := pod.DeepCopy()
+	stale.Annotations[constants.SpanIDAnnotation] = "45f359cdc1c8ab06"
+	_, err := tracingClient.EndTrace(context.Background(), stale)
+	assert.NoError(t, err)
+
+	current := &corev1.Pod{}
+	assert.NoError(t, k8sClient.Get(context.Background(), client.ObjectKeyFromObject(pod), current))
+	assert.Equal(t, "b7ad6b7169203331", current.Annotations[constants.SpanIDAnnotation])
+}
+
+func TestEndTraceResourceVersionPrecondition(t *testing.T) {
+	pod := &corev1.Pod{ObjectMeta: metav1.ObjectMeta{
+		Name:      "test-pod",
+		Namespace: "default",
+		Annotations: map[string]string{
+			constants.TraceIDAnnotation: "f620f5cad0af940c294f980c5366a6a1",
+			constants.SpanIDAnnotation:  "45f359cdc1c8ab06",
+		},
+	}}
+	k8sClient := fake.NewClientBuilder().WithObjects(pod).Build()
+	// another controller writes the object right after the trace was validated
+	reader := fake.NewClientBuilder().WithObjects(pod).WithInterceptorFuncs(interceptor.Funcs{
+		Get: func(ctx context.Context, c client.WithWatch, key client.ObjectKey, obj client.Object, opts ...client.GetOption) error {
+			if err := k8sClient.Get(ctx, key, obj, opts...); err != nil {
+				return err
+			}
+			concurrent := &corev1.Pod{}
+			assert.NoError(t, k8sClient.Get(ctx, key, concurrent))
+			concurrent.Labels = map[string]string{"app": "concurrent"}
+			return k8sClient.Update(ctx, concurrent)
+		},
+	}).Build()
+	tracingClient := NewTracingClient(k8sClient, reader, sdktrace.NewTracerProvider().Tracer("kubetracer"), logr.Discard())
+
+	tracedPod := &corev1.Pod{}
+	assert.NoError(t, k8sClient.Get(context.Background(), client.ObjectKeyFromObject(pod), tracedPod))
+	_, err := tracingClient.EndTrace(context.Background(), tracedPod)
+	assert.True(t, apierrors.IsConflict(err))
+}
+
+func TestEndTraceStaleReader(t *testing.T) {
+	pod := &corev1.Pod{ObjectMeta: metav1.ObjectMeta{
+		Name:      "test-pod",
+		Namespace: "default",
+		Annotations: map[string]string{
+			constants.TraceIDAnnotation: "f620f5cad0af940c294f980c5366a6a1",
+			constants.SpanIDAnnotation:  "45f359cdc1c8ab06",
+		},
+	}}
+	ctx := context.Background()
+
+	// newerPod returns a client holding pod written once more after it was traced, and the object read from it
+	newerPod := func(t *testing.T) (client.Client, *corev1.Pod) {
+		k8sClient := fake.NewClientBuilder().WithObjects(pod).Build()
+		current := &corev1.Pod{}
+		assert.NoError(t, k8sClient.Get(ctx, client.ObjectKeyFromObject(pod), current))
+		current.Labels = map[string]string{"app": "test"}
+		assert.NoError(t, k8sClient.Update(ctx, current))
+		return k8sClient, current
+	}
+
+	t.Run("older copy with the trace", func(t *testing.T) {
+		k8sClient, tracedPod := newerPod(t)
+		// the cache has not seen the last write yet
+		cache := fake.NewClientBuilder().WithObjects(pod).Build()
+		tracingClient := NewTracingClientWithOptions(k8sClient, WithReader(cache))
+
+		_, err := tracingClient.EndTrace(ctx, tracedPod)
+		assert.NoError(t, err)
+
+		current := &corev1.Pod{}
+		assert.NoError(t, k8sClient.Get(ctx, client.ObjectKeyFromObject(pod), current))
+		assert.NotContains(t, current.Annotations, constants.TraceIDAnnotation)
+	})
+
+	t.Run("older copy without the trace", func(t *testing.T) {
+		k8sClient, tracedPod := newerPod(t)
+		// the cache has not seen the trace yet, the API reader has
+		cache := fake.NewClientBuilder().WithObjects(&corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "test-pod", Namespace: "default"}}).Build()
+		tracingClient := NewTracingClientWithOptions(k8sClient, WithReader(cache), WithAPIReader(k8sClient))
+
+		_, err := tracingClient.EndTrace(ctx, tracedPod)
+		assert.NoError(t, err)
+
+		current := &corev1.Pod{}
+		assert.NoError(t, k8sClient.Get(ctx, client.ObjectKeyFromObject(pod), current))
+		assert.NotContains(t, current.Annotations, constants.TraceIDAnnotation)
+	})
+}
+
+func TestEndTraceOptions(t *testing.T) {
+	tests := []struct {
+		name              string
+		opts              []client.PatchOption
+		wantAnnotations   []string
+		wantConditions    []corev1.PodConditionType
+		wantStatusPatches int
+	}{
+		{
+			name:              "everything",
+			wantStatusPatches: 1,
+		},
+		{
+			name:           "only annotations",
+			opts:           []client.PatchOption{OnlyAnnotations()},
+			wantConditions: []corev1.PodConditionType{"TraceID", "SpanID"},
+		},
+		{
+			name:              "only conditions",
+			opts:              []client.PatchOption{OnlyConditions()},
+			wantAnnotations:   []string{constants.TraceIDAnnotation, constants.SpanIDAnnotation},
+			wantStatusPatches: 1,
+		},
+		{
+			name:              "keep trace ID",
+			opts:              []client.PatchOption{KeepTraceID()},
+			wantAnnotations:   []string{constants.TraceIDAnnotation},
+			wantConditions:    []corev1.PodConditionType{"TraceID"},
+			wantStatusPatches: 1,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pod := &corev1.Pod{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "test-pod",
+					Namespace: "default",
+					Annotations: map[string]string{
+						constants.TraceIDAnnotation: "f620f5cad0af940c294f980c5366a6a1",
+						constants.SpanIDAnnotation:  "45f359cdc1c8ab06",
+					},
+				},
+				Status: corev1.PodStatus{
+					Conditions: []corev1.PodCondition{
+						{Type: "TraceID", Message: "f620f5cad0af940c294f980c5366a6a1"},
+						{Type: "SpanID", Message: "45f359cdc1c8ab06"},
+					},
+				},
+			}
+			statusPatches := 0
+			k8sClient := fake.NewClientBuilder().WithObjects(pod).WithInterceptorFuncs(interceptor.Funcs{
+				SubResourcePatch: func(ctx context.Context, c client.Client, subResourceName string, obj client.Object, patch client.Patch, opts ...client.SubResourcePatchOption) error {
+					statusPatches++
+					return c.SubResource(subResourceName).Patch(ctx, obj, patch, opts...)
+				},
+			}).Build()
+			tracingClient := NewTracingClient(k8sClient, k8sClient, sdktrace.NewTracerProvider().Tracer("kubetracer"), logr.Discard())
+
+			tracedPod := &corev1.Pod{}
+			assert.NoError(t, k8sClient.Get(context.Background(), client.ObjectKeyFromObject(pod), tracedPod))
+			_, err := tracingClient.EndTrace(context.Background(), tracedPod, tt.opts...)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.wantStatusPatches, statusPatches)
+
+			current := &corev1.Pod{}
+			assert.NoError(t, k8sClient.Get(context.Background(), client.ObjectKeyFromObject(pod), current))
+			var annotations []string
+			for _, key := range []string{constants.TraceIDAnnotation, constants.SpanIDAnnotation} {
+				if _, ok := current.Annotations[key]; ok {
+					annotations = append(annotations, key)
+				}
+			}
+			assert.Equal(t, tt.wantAnnotations, annotations)
+			var conditions []corev1.PodConditionType
+			for _, condition := range current.Status.Conditions {
+				conditions = append(conditions, condition.Type)
+			}
+			assert.Equal(t, tt.wantConditions, conditions)
+		})
+	}
+}
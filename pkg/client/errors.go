@@ -0,0 +1,31 @@
+package client
+
+import (
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+)
+
+// IgnoreNotFound returns nil on NotFound errors and err otherwise, so that reconcilers using the TracingClient
+// don't need to import the controller-runtime client for it.
+func IgnoreNotFound(err error) error {
+	return ignore(err, apierrors.IsNotFound)
+}
+
+// IgnoreAlreadyExists returns nil on AlreadyExists errors and err otherwise, for creates which may race with
+// another reconcile of the same object.
+func IgnoreAlreadyExists(err error) error {
+	return ignore(err, apierrors.IsAlreadyExists)
+}
+
+// IgnoreConflict returns nil on Conflict errors and err otherwise, for writes which are retried by the next
+// reconcile with a fresh copy of the object.
+func IgnoreConflict(err error) error {
+	return ignore(err, apierrors.IsConflict)
+}
+
+// ignore returns nil when err matches is and err otherwise
+func ignore(err error, is func(error) bool) error {
+	if is(err) {
+		return nil
+	}
+	return err
+}
@@ -0,0 +1,35 @@
+package client
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+func TestIgnoreErrors(t *testing.T) {
+	gr := schema.GroupResource{Resource: "pods"}
+	notFound := apierrors.NewNotFound(gr, "test-pod")
+	alreadyExists := apierrors.NewAlreadyExists(gr, "test-pod")
+	conflict := apierrors.NewConflict(gr, "test-pod", errors.New("the object has been modified"))
+	other := errors.New("boom")
+
+	assert.NoError(t, IgnoreNotFound(nil))
+	assert.NoError(t, IgnoreNotFound(notFound))
+	assert.NoError(t, IgnoreNotFound(fmt.Errorf("get: %w", notFound)))
+	assert.Equal(t, alreadyExists, IgnoreNotFound(alreadyExists))
+	assert.Equal(t, other, IgnoreNotFound(other))
+
+	assert.NoError(t, IgnoreAlreadyExists(nil))
+	assert.NoError(t, IgnoreAlreadyExists(alreadyExists))
+	assert.Equal(t, notFound, IgnoreAlreadyExists(notFound))
+	assert.Equal(t, other, IgnoreAlreadyExists(other))
+
+	assert.NoError(t, IgnoreConflict(nil))
+	assert.NoError(t, IgnoreConflict(conflict))
+	assert.Equal(t, notFound, IgnoreConflict(notFound))
+	assert.Equal(t, other, IgnoreConflict(other))
+}
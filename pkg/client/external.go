@@ -0,0 +1,29 @@
+package client
+
+import (
+	"context"
+
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// StartExternalSpan starts a client span for a call to an external dependency during a reconcile, such as a
+// cloud API or a database, with the peer.service attribute set to peerService.  The span is a child of the span
+// in ctx or else continues the trace of obj, if any, so the full picture of a reconcile isn't limited to API
+// server calls.  The caller must end the span.
+func (tc *tracingClient) StartExternalSpan(ctx context.Context, peerService, operationName string, obj client.Object, opts ...trace.SpanStartOption) (context.Context, trace.Span) {
+	if !trace.SpanContextFromContext(ctx).IsValid() && obj != nil {
+		if spanContext, err := TraceContextFromObject(obj, tc.scheme); err == nil {
+			ctx = trace.ContextWithRemoteSpanContext(ctx, spanContext)
+		}
+	}
+
+	spanOpts := []trace.SpanStartOption{
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(semconv.PeerService(peerService)),
+	}
+	spanOpts = append(spanOpts, actorSpanOptions(ctx)...)
+	spanOpts = append(spanOpts, opts...)
+	return tc.Tracer.Start(ctx, operationName, spanOpts...)
+}
@@ -0,0 +1,54 @@
+package client
+
+import (
+	"context"
+	"testing"
+
+	"github.com/go-logr/logr"
+	constants "github.com/kubetracer/kubetracer-go/pkg/constants"
+	"github.com/stretchr/testify/assert"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestStartExternalSpan(t *testing.T) {
+	k8sClient := fake.NewClientBuilder().Build()
+	recorder := tracetest.NewSpanRecorder()
+	tracer := sdktrace.NewTracerProvider(sdktrace.WithSampler(sdktrace.AlwaysSample()), sdktrace.WithSpanProcessor(recorder)).Tracer("kubetracer")
+	tracingClient := NewTracingClient(k8sClient, k8sClient, tracer, logr.Discard())
+
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "external-pod",
+			Namespace: "default",
+			Annotations: map[string]string{
+				constants.TraceIDAnnotation: "f620f5cad0af940c294f980c5366a6a1",
+				constants.SpanIDAnnotation:  "45f359cdc1c8ab06",
+			},
+		},
+	}
+
+	// Without a span in the context the trace of the object is continued
+	_, span := tracingClient.StartExternalSpan(context.Background(), "s3", "PutObject", pod)
+	span.End()
+
+	spans := recorder.Ended()
+	assert.Len(t, spans, 1)
+	assert.Equal(t, "PutObject", spans[0].Name())
+	assert.Equal(t, trace.SpanKindClient, spans[0].SpanKind())
+	assert.Contains(t, spans[0].Attributes(), semconv.PeerService("s3"))
+	assert.Equal(t, "f620f5cad0af940c294f980c5366a6a1", spans[0].SpanContext().TraceID().String())
+	assert.Equal(t, "45f359cdc1c8ab06", spans[0].Parent().SpanID().String())
+
+	// The span in the context takes precedence
+	ctx, parent := tracer.Start(context.Background(), "Reconcile")
+	_, span = tracingClient.StartExternalSpan(ctx, "postgres", "SELECT", pod)
+	span.End()
+	parent.End()
+	assert.Equal(t, parent.SpanContext().SpanID(), recorder.Ended()[1].Parent().SpanID())
+}
@@ -0,0 +1,119 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/go-logr/logr"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/trace"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// PropagationFormat selects how the trace is written on the objects
+type PropagationFormat int
+
+const (
+	// AllFormats writes both the kubetracer.io/trace-id and span-id annotations and the fields of the global
+	// OTel propagator, and reads either.  It is the default.
+	AllFormats PropagationFormat = iota
+	// IDAnnotationsFormat writes the kubetracer.io/trace-id and span-id annotations
+	IDAnnotationsFormat
+	// PropagatorFormat writes the fields of the global OTel propagator, e.g. otel.kubetracer.io/traceparent and
+	// otel.kubetracer.io/baggage
+	PropagatorFormat
+)
+
+// WithPropagationFormat writes the trace on objects in format only.  When compatible is set, the trace is read
+// from either format, so that during a migration the chains started by operators still writing the other
+// format are continued.  Otherwise only format is read.
+func WithPropagationFormat(format PropagationFormat, compatible bool) Option {
+	return func(o *clientOptions) {
+		o.format = format
+		o.compatible = compatible
+	}
+}
+
+// tracePropagation configures the format of the trace on objects, all formats are used when nil
+type tracePropagation struct {
+	format     PropagationFormat
+	compatible bool
+
+	// keys are the annotations of the trace, the kubetracer.io annotations when nil
+	keys *annotationKeys
+
+	// labels selects whether the IDs are written to labels
+	labels LabelPropagation
+
+	// conditions selects the status conditions the trace is stored in
+	conditions ConditionFormat
+
+	// noConditions never stores the trace in the status
+	noConditions bool
+
+	// ttl if positive is the age past which the trace of an object is not resumed
+	ttl time.Duration
+}
+
+// LabelPropagation selects whether the trace and span IDs are written to labels, which unlike unknown
+// annotations are preserved by some GitOps tooling.  The labels have the keys of the ID annotations.
+type LabelPropagation int
+
+const (
+	// NoLabels writes the IDs to the annotations only.  It is the default.
+	NoLabels LabelPropagation = iota
+	// LabelsAndAnnotations writes the IDs to labels in addition to the annotations
+	LabelsAndAnnotations
+	// LabelsOnly writes the IDs to labels instead of the annotations
+	LabelsOnly
+)
+
+// labelPropagation returns whether the IDs are written to labels, never when p is nil
+func (p *tracePropagation) labelPropagation() LabelPropagation {
+	if p == nil {
+		return NoLabels
+	}
+	return p.labels
+}
+
+// writes reports whether format is written
+func (p *tracePropagation) writes(format PropagationFormat) bool {
+	return p == nil || p.format == AllFormats || p.format == format
+}
+
+// reads reports whether format is read
+func (p *tracePropagation) reads(format PropagationFormat) bool {
+	return p.writes(format) || p.compatible
+}
+
+// extract returns ctx with the trace carried by obj as remote span context, if any.  The conditions and ID
+// annotations take precedence over the fields of the propagator, unless the propagator is the configured format.
+func (p *tracePropagation) extract(ctx context.Context, logger logr.Logger, obj client.Object, scheme *runtime.Scheme) context.Context {
+	keys := p.annotationKeys()
+	if p.stale(obj) {
+		logger.V(1).Info("Not resuming stale trace", "object", obj.GetName(), "attached", obj.GetAnnotations()[keys.traceTime])
+		return ctx
+	}
+	if p != nil && p.format == PropagatorFormat {
+		if extracted := otel.GetTextMapPropagator().Extract(ctx, ObjectCarrier{obj: obj, prefix: keys.propagatorPrefix}); trace.SpanContextFromContext(extracted).IsValid() || !p.compatible {
+			return extracted
+		}
+	}
+
+	if !p.reads(IDAnnotationsFormat) {
+		return ctx
+	}
+	spanContext, err := traceContextFromObject(obj, p.conditionsScheme(scheme), keys)
+	switch {
+	case err == nil:
+		return trace.ContextWithRemoteSpanContext(ctx, spanContext)
+	case errors.Is(err, ErrNoTraceContext) && p.reads(PropagatorFormat):
+		// fall back to the fields of the propagator configured by the binary, if any
+		return otel.GetTextMapPropagator().Extract(ctx, ObjectCarrier{obj: obj, prefix: keys.propagatorPrefix})
+	case !errors.Is(err, ErrNoTraceContext):
+		logger.Error(err, "Invalid trace context", "object", obj.GetName())
+	}
+	return ctx
+}
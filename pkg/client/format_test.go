@@ -0,0 +1,102 @@
+package client
+
+import (
+	"context"
+	"testing"
+
+	constants "github.com/kubetracer/kubetracer-go/pkg/constants"
+	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestWithPropagationFormat(t *testing.T) {
+	previous := otel.GetTextMapPropagator()
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+	t.Cleanup(func() { otel.SetTextMapPropagator(previous) })
+
+	legacyPod := func() *corev1.Pod {
+		return &corev1.Pod{ObjectMeta: metav1.ObjectMeta{
+			Name:      "legacy-pod",
+			Namespace: "default",
+			Annotations: map[string]string{
+				constants.TraceIDAnnotation: "f620f5cad0af940c294f980c5366a6a1",
+				constants.SpanIDAnnotation:  "45f359cdc1c8ab06",
+			},
+		}}
+	}
+	w3cPod := func() *corev1.Pod {
+		return &corev1.Pod{ObjectMeta: metav1.ObjectMeta{
+			Name:      "w3c-pod",
+			Namespace: "default",
+			Annotations: map[string]string{
+				"otel.kubetracer.io/traceparent": "00-0af7651916cd43dd8448eb211c80319c-b7ad6b7169203331-01",
+			},
+		}}
+	}
+
+	tests := []struct {
+		name       string
+		format     PropagationFormat
+		compatible bool
+		pod        *corev1.Pod
+		continued  bool
+	}{
+		{name: "propagator reads legacy in compatibility mode", format: PropagatorFormat, compatible: true, pod: legacyPod(), continued: true},
+		{name: "propagator ignores legacy", format: PropagatorFormat, pod: legacyPod()},
+		{name: "legacy reads propagator in compatibility mode", format: IDAnnotationsFormat, compatible: true, pod: w3cPod(), continued: true},
+		{name: "legacy ignores propagator", format: IDAnnotationsFormat, pod: w3cPod()},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			k8sClient := fake.NewClientBuilder().WithObjects(tt.pod).Build()
+			recorder := tracetest.NewSpanRecorder()
+			tp := sdktrace.NewTracerProvider(sdktrace.WithSampler(sdktrace.AlwaysSample()), sdktrace.WithSpanProcessor(recorder))
+			tracingClient := NewTracingClientWithOptions(k8sClient, WithTracerProvider(tp), WithPropagationFormat(tt.format, tt.compatible))
+
+			original := tt.pod.DeepCopy()
+			assert.NoError(t, tracingClient.Update(context.Background(), tt.pod))
+
+			spans := recorder.Ended()
+			assert.Len(t, spans, 1)
+			parentTraceID := original.Annotations[constants.TraceIDAnnotation]
+			if parentTraceID == "" {
+				parentTraceID = "0af7651916cd43dd8448eb211c80319c"
+			}
+			assert.Equal(t, tt.continued, spans[0].SpanContext().TraceID().String() == parentTraceID)
+
+			// Only the configured format is written
+			traceID := spans[0].SpanContext().TraceID().String()
+			if tt.format == PropagatorFormat {
+				assert.Contains(t, tt.pod.Annotations["otel.kubetracer.io/traceparent"], traceID)
+				assert.Equal(t, original.Annotations[constants.TraceIDAnnotation], tt.pod.Annotations[constants.TraceIDAnnotation])
+			} else {
+				assert.Equal(t, traceID, tt.pod.Annotations[constants.TraceIDAnnotation])
+				assert.Equal(t, original.Annotations["otel.kubetracer.io/traceparent"], tt.pod.Annotations["otel.kubetracer.io/traceparent"])
+			}
+		})
+	}
+}
+
+func TestWithPropagationFormatAnnotationPrefix(t *testing.T) {
+	k8sClient := fake.NewClientBuilder().Build()
+	recorder := tracetest.NewSpanRecorder()
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSampler(sdktrace.AlwaysSample()), sdktrace.WithSpanProcessor(recorder))
+
+	// the format keeps the annotations configured by the other options, whatever their order
+	tracingClient := NewTracingClientWithOptions(k8sClient, WithTracerProvider(tp),
+		WithPropagationFormat(IDAnnotationsFormat, false), WithAnnotationPrefix("example.com/"))
+
+	pod := &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "test-pod", Namespace: "default"}}
+	assert.NoError(t, tracingClient.Create(context.Background(), pod))
+
+	spans := recorder.Ended()
+	assert.Len(t, spans, 1)
+	assert.Equal(t, spans[0].SpanContext().TraceID().String(), pod.Annotations["example.com/trace-id"])
+	assert.NotContains(t, pod.Annotations, constants.TraceIDAnnotation)
+}
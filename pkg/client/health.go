@@ -0,0 +1,132 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sync"
+	"sync/atomic"
+
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+)
+
+// DefaultMaxFailedExports is the number of consecutive failed exports after which ExportHealth reports unhealthy
+const DefaultMaxFailedExports = 3
+
+var _ sdktrace.SpanExporter = &healthExporter{}
+var _ sdktrace.SpanProcessor = &healthProcessor{}
+
+// ExportHealth tracks the backpressure of a batched span exporter, so degraded tracing can be surfaced by
+// readiness or liveness probes instead of silently losing spans.  Its Exporter wraps the exporter given to
+// the batcher and its Processor counts the spans waiting in the queue of the batcher:
+//
+//	health := kubetracer.NewExportHealth(exporter, sdktrace.DefaultMaxQueueSize)
+//	tp := sdktrace.NewTracerProvider(sdktrace.WithBatcher(health.Exporter()), sdktrace.WithSpanProcessor(health.Processor()))
+//	mgr.AddReadyzCheck("tracing", health.Check)
+type ExportHealth struct {
+	// exporter is the wrapped exporter
+	exporter sdktrace.SpanExporter
+
+	// maxQueueSize is the queue size of the batcher, spans are dropped when it is reached
+	maxQueueSize int64
+
+	// queued counts the spans ended and not yet exported
+	queued atomic.Int64
+
+	mu            sync.Mutex
+	failedExports int
+	lastExportErr error
+}
+
+// NewExportHealth wraps exporter.  maxQueueSize must match the queue size of the batcher.
+func NewExportHealth(exporter sdktrace.SpanExporter, maxQueueSize int) *ExportHealth {
+	return &ExportHealth{
+		exporter:     exporter,
+		maxQueueSize: int64(maxQueueSize),
+	}
+}
+
+// Exporter returns the exporter to give to the batcher.  It shuts the wrapped exporter down with the batcher.
+func (h *ExportHealth) Exporter() sdktrace.SpanExporter {
+	return &healthExporter{SpanExporter: h.exporter, health: h}
+}
+
+// Processor returns the span processor counting the spans queued in the batcher.  Its Shutdown does nothing,
+// the wrapped exporter is shut down by the batcher.
+func (h *ExportHealth) Processor() sdktrace.SpanProcessor {
+	return &healthProcessor{health: h}
+}
+
+// Check implements healthz.Checker.  It fails when the queue of the batcher is full, which means spans
+// are dropped, or when the last DefaultMaxFailedExports exports failed.
+func (h *ExportHealth) Check(_ *http.Request) error {
+	if queued := h.queued.Load(); queued >= h.maxQueueSize {
+		return fmt.Errorf("span export queue is saturated: %d spans queued, spans are dropped", queued)
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.failedExports >= DefaultMaxFailedExports {
+		return fmt.Errorf("the last %d span exports failed: %w", h.failedExports, h.lastExportErr)
+	}
+	return nil
+}
+
+// healthExporter is the exporter of an ExportHealth
+type healthExporter struct {
+	sdktrace.SpanExporter
+
+	health *ExportHealth
+}
+
+// ExportSpans implements sdktrace.SpanExporter
+func (e *healthExporter) ExportSpans(ctx context.Context, spans []sdktrace.ReadOnlySpan) error {
+	h := e.health
+
+	// the batcher never holds more than maxQueueSize spans, the ones above were dropped
+	for {
+		queued := h.queued.Load()
+		dequeued := min(queued, h.maxQueueSize) - int64(len(spans))
+		if h.queued.CompareAndSwap(queued, max(dequeued, 0)) {
+			break
+		}
+	}
+
+	err := e.SpanExporter.ExportSpans(ctx, spans)
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if err != nil {
+		h.failedExports++
+		h.lastExportErr = err
+	} else {
+		h.failedExports = 0
+		h.lastExportErr = nil
+	}
+	return err
+}
+
+// healthProcessor is the span processor of an ExportHealth
+type healthProcessor struct {
+	health *ExportHealth
+}
+
+// OnStart implements sdktrace.SpanProcessor
+func (p *healthProcessor) OnStart(context.Context, sdktrace.ReadWriteSpan) {}
+
+// OnEnd implements sdktrace.SpanProcessor.  Like the batcher it only queues sampled spans.
+func (p *healthProcessor) OnEnd(s sdktrace.ReadOnlySpan) {
+	if s.SpanContext().IsSampled() {
+		p.health.queued.Add(1)
+	}
+}
+
+// Shutdown implements sdktrace.SpanProcessor
+func (p *healthProcessor) Shutdown(context.Context) error {
+	return nil
+}
+
+// ForceFlush implements sdktrace.SpanProcessor
+func (p *healthProcessor) ForceFlush(context.Context) error {
+	return nil
+}
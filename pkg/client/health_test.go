@@ -0,0 +1,68 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+)
+
+// failingExporter fails every export while err is set
+type failingExporter struct {
+	err       error
+	shutdowns int
+}
+
+func (e *failingExporter) ExportSpans(context.Context, []sdktrace.ReadOnlySpan) error {
+	return e.err
+}
+
+func (e *failingExporter) Shutdown(context.Context) error {
+	e.shutdowns++
+	return nil
+}
+
+func TestExportHealth(t *testing.T) {
+	exporter := &failingExporter{}
+	health := NewExportHealth(exporter, 4)
+	tp := sdktrace.NewTracerProvider(
+		sdktrace.WithSpanProcessor(health.Processor()),
+		sdktrace.WithBatcher(health.Exporter(), sdktrace.WithMaxQueueSize(4), sdktrace.WithMaxExportBatchSize(4)),
+	)
+	tracer := tp.Tracer("kubetracer")
+
+	endSpans := func(n int) {
+		for i := 0; i < n; i++ {
+			_, span := tracer.Start(context.Background(), "span")
+			span.End()
+		}
+	}
+
+	t.Run("healthy", func(t *testing.T) {
+		endSpans(2)
+		assert.NoError(t, health.Check(nil))
+		assert.NoError(t, tp.ForceFlush(context.Background()))
+		assert.NoError(t, health.Check(nil))
+	})
+
+	t.Run("failing exports", func(t *testing.T) {
+		exporter.err = errors.New("collector unavailable")
+		for i := 0; i < DefaultMaxFailedExports; i++ {
+			endSpans(1)
+			tp.ForceFlush(context.Background())
+		}
+		assert.ErrorContains(t, health.Check(nil), "collector unavailable")
+
+		exporter.err = nil
+		endSpans(1)
+		assert.NoError(t, tp.ForceFlush(context.Background()))
+		assert.NoError(t, health.Check(nil))
+	})
+
+	t.Run("shutdown", func(t *testing.T) {
+		assert.NoError(t, tp.Shutdown(context.Background()))
+		assert.Equal(t, 1, exporter.shutdowns)
+	})
+}
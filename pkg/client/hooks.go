@@ -0,0 +1,38 @@
+package client
+
+import (
+	"context"
+
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/trace"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// OnSpanEndFunc is called when the span of a client operation ends.  verb is the operation, e.g. "create",
+// "update", "patch", "delete", "deleteallof", "get", "list", "endtrace", "status-update", "status-patch"
+// or "status-create", and obj the object it was called with, nil for "list".  It is called synchronously
+// from the operation and should be cheap.
+type OnSpanEndFunc func(span sdktrace.ReadOnlySpan, verb string, obj client.Object)
+
+// WithOnSpanEnd calls hook when the span of a client operation ends, enabling custom accounting such as
+// per-team API write counts or SLO tracking without writing a full SpanProcessor.  The hook is only called for
+// spans created by an OTel SDK tracer.
+func WithOnSpanEnd(hook OnSpanEndFunc) Option {
+	return func(o *clientOptions) {
+		o.onSpanEnd = hook
+	}
+}
+
+// endSpan sets the attributes of the operation on span, ends it, counts it in the batch of ctx and calls the
+// OnSpanEndFunc, if any
+func endSpan(ctx context.Context, span trace.Span, hook OnSpanEndFunc, verb, kind string, obj client.Object) {
+	span.SetAttributes(objectAttributes(verb, kind, obj)...)
+	span.End()
+	readOnlySpan, ok := span.(sdktrace.ReadOnlySpan)
+	batchFromContext(ctx).add(ok && readOnlySpan.Status().Code == codes.Error)
+	if hook == nil || !ok {
+		return
+	}
+	hook(readOnlySpan, verb, obj)
+}
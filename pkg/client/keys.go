@@ -0,0 +1,60 @@
+package client
+
+import (
+	constants "github.com/kubetracer/kubetracer-go/pkg/constants"
+)
+
+// annotationKeys are the annotations a TracingClient stores the trace in
+type annotationKeys struct {
+	traceID       string
+	spanID        string
+	triggeredBy   string
+	traceRoot     string
+	traceRootKind string
+	traceRootName string
+	actor         string
+	lastOps       string
+	traceTime     string
+
+	// propagatorPrefix prefixes the fields of the OTel propagator
+	propagatorPrefix string
+}
+
+// defaultAnnotationKeys are the kubetracer.io annotations of pkg/constants
+var defaultAnnotationKeys = &annotationKeys{
+	traceID:          constants.TraceIDAnnotation,
+	spanID:           constants.SpanIDAnnotation,
+	triggeredBy:      constants.TriggeredByAnnotation,
+	traceRoot:        constants.TraceRootAnnotation,
+	traceRootKind:    constants.TraceRootKindAnnotation,
+	traceRootName:    constants.TraceRootNameAnnotation,
+	actor:            constants.ActorAnnotation,
+	lastOps:          constants.LastOpsAnnotation,
+	traceTime:        constants.TraceTimeAnnotation,
+	propagatorPrefix: constants.PropagatorAnnotationPrefix,
+}
+
+// annotationKeysWithPrefix returns the annotations with prefix in place of kubetracer.io/, and otel.prefix in
+// place of otel.kubetracer.io/ for the fields of the propagator
+func annotationKeysWithPrefix(prefix string) *annotationKeys {
+	return &annotationKeys{
+		traceID:          prefix + "trace-id",
+		spanID:           prefix + "span-id",
+		triggeredBy:      prefix + "triggered-by",
+		traceRoot:        prefix + "trace-root",
+		traceRootKind:    prefix + "trace-root-kind",
+		traceRootName:    prefix + "trace-root-name",
+		actor:            prefix + "actor",
+		lastOps:          prefix + "last-ops",
+		traceTime:        prefix + "trace-time",
+		propagatorPrefix: "otel." + prefix,
+	}
+}
+
+// annotationKeys returns the annotations the trace is stored in
+func (p *tracePropagation) annotationKeys() *annotationKeys {
+	if p == nil || p.keys == nil {
+		return defaultAnnotationKeys
+	}
+	return p.keys
+}
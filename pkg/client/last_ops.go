@@ -0,0 +1,104 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	constants "github.com/kubetracer/kubetracer-go/pkg/constants"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/trace"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// DefaultLastOps is the number of operations kept in the last-ops annotation
+const DefaultLastOps = 5
+
+// WithLastOpsAnnotation maintains the kubetracer.io/last-ops annotation on the objects the client creates,
+// updates and patches: the last n operations of controller, oldest first, each with the verb, controller,
+// timestamp and span ID.  It gives kubectl-level visibility into recent operations when no tracing backend
+// is reachable.  n defaults to DefaultLastOps when not positive.
+func WithLastOpsAnnotation(controller string, n int) Option {
+	if n <= 0 {
+		n = DefaultLastOps
+	}
+	return func(o *clientOptions) {
+		o.lastOps = &lastOps{controller: controller, size: n}
+	}
+}
+
+// lastOps configures the last-ops annotation
+type lastOps struct {
+	controller string
+	size       int
+}
+
+// LastOp is an entry of the last-ops annotation, with short keys to keep the annotation compact
+type LastOp struct {
+	Verb       string `json:"v"`
+	Controller string `json:"c"`
+	// Time is when the operation was sent, in RFC 3339 format
+	Time string `json:"t"`
+	// TraceID and SpanID identify the span of the operation, ParentSpanID its parent if it was recorded
+	TraceID      string `json:"tr,omitempty"`
+	SpanID       string `json:"s,omitempty"`
+	ParentSpanID string `json:"p,omitempty"`
+}
+
+// LastOpsFromObject returns the operations recorded in the kubetracer.io/last-ops annotation of obj, oldest first,
+// none if it has no such annotation
+func LastOpsFromObject(obj client.Object) ([]LastOp, error) {
+	value, ok := obj.GetAnnotations()[constants.LastOpsAnnotation]
+	if !ok {
+		return nil, nil
+	}
+	return ParseLastOps(value)
+}
+
+// ParseLastOps returns the operations recorded in value, the value of a last-ops annotation, oldest first.  Use it
+// to read the annotation of TracingClients configured with WithAnnotationPrefix.
+func ParseLastOps(value string) ([]LastOp, error) {
+	var ops []LastOp
+	if err := json.Unmarshal([]byte(value), &ops); err != nil {
+		return nil, fmt.Errorf("problem parsing the last-ops annotation: %w", err)
+	}
+	return ops, nil
+}
+
+// add records verb on obj with the span in ctx, dropping the oldest operations beyond the size of the ring
+func (l *lastOps) add(ctx context.Context, verb string, obj client.Object, keys *annotationKeys) {
+	if l == nil {
+		return
+	}
+
+	var ops []LastOp
+	annotations := obj.GetAnnotations()
+	if existing, ok := annotations[keys.lastOps]; ok {
+		// start over when the annotation was tampered with
+		_ = json.Unmarshal([]byte(existing), &ops)
+	}
+
+	op := LastOp{Verb: verb, Controller: l.controller, Time: time.Now().UTC().Format(time.RFC3339)}
+	if spanContext := trace.SpanContextFromContext(ctx); spanContext.IsValid() {
+		op.TraceID = spanContext.TraceID().String()
+		op.SpanID = spanContext.SpanID().String()
+	}
+	if span, ok := trace.SpanFromContext(ctx).(sdktrace.ReadOnlySpan); ok && span.Parent().IsValid() {
+		op.ParentSpanID = span.Parent().SpanID().String()
+	}
+	ops = append(ops, op)
+	if len(ops) > l.size {
+		ops = ops[len(ops)-l.size:]
+	}
+
+	value, err := json.Marshal(ops)
+	if err != nil {
+		return
+	}
+	if annotations == nil {
+		annotations = map[string]string{}
+	}
+	annotations[keys.lastOps] = string(value)
+	obj.SetAnnotations(annotations)
+}
@@ -0,0 +1,53 @@
+package client
+
+import (
+	"context"
+	"testing"
+
+	"github.com/go-logr/logr"
+	constants "github.com/kubetracer/kubetracer-go/pkg/constants"
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestWithLastOpsAnnotation(t *testing.T) {
+	// Create a fake Kubernetes client
+	k8sClient := fake.NewClientBuilder().Build()
+	initTracer()
+	tracingClient := NewTracingClientWithOptions(k8sClient, WithLastOpsAnnotation("pod-controller", 2))
+
+	ctx, span := tracingClient.StartSpan(context.Background(), "TestWithLastOpsAnnotation")
+	defer span.End()
+
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "last-ops-pod",
+			Namespace: "default",
+		},
+	}
+	assert.NoError(t, tracingClient.Create(ctx, pod))
+	assert.NoError(t, tracingClient.Update(ctx, pod))
+	assert.NoError(t, tracingClient.Patch(ctx, pod, client.MergeFrom(pod.DeepCopy())))
+
+	// Only the last two operations are kept
+	stored := &corev1.Pod{}
+	assert.NoError(t, k8sClient.Get(ctx, client.ObjectKeyFromObject(pod), stored))
+	ops, err := LastOpsFromObject(stored)
+	assert.NoError(t, err)
+	assert.Len(t, ops, 2)
+	assert.Equal(t, "update", ops[0].Verb)
+	assert.Equal(t, "patch", ops[1].Verb)
+	assert.Equal(t, "pod-controller", ops[1].Controller)
+	assert.Equal(t, stored.Annotations[constants.TraceIDAnnotation], ops[1].TraceID)
+	assert.Equal(t, stored.Annotations[constants.SpanIDAnnotation], ops[1].SpanID)
+	assert.Equal(t, span.SpanContext().SpanID().String(), ops[1].ParentSpanID)
+	assert.NotEmpty(t, ops[1].Time)
+
+	// Without the option no annotation is written
+	plain := &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "plain-pod", Namespace: "default"}}
+	assert.NoError(t, NewTracingClient(k8sClient, k8sClient, initTracer(), logr.Discard()).Create(ctx, plain))
+	assert.NotContains(t, plain.Annotations, constants.LastOpsAnnotation)
+}
@@ -0,0 +1,40 @@
+package client
+
+import (
+	"context"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	// ListItemTraceIDKey is the attribute of the span of a listed item holding the trace ID the item carries
+	ListItemTraceIDKey = attribute.Key("kubetracer.list_item.trace_id")
+	// ListItemSpanIDKey is the attribute of the span of a listed item holding the span ID the item carries
+	ListItemSpanIDKey = attribute.Key("kubetracer.list_item.span_id")
+)
+
+// recordListItemSpans records a span per item of list, of the kind gvk, as children of the List span in ctx.  The
+// spans are named as the operation "ListItem", e.g. "ListItem Pod pod01".
+func (tc *tracingClient) recordListItemSpans(ctx context.Context, gvk schema.GroupVersionKind, list client.ObjectList) {
+	items, err := meta.ExtractList(list)
+	if err != nil {
+		return
+	}
+
+	for _, item := range items {
+		obj, ok := item.(client.Object)
+		if !ok {
+			continue
+		}
+		attrs := objectAttributes("listitem", gvk.Kind, obj)
+		if spanContext := tc.itemSpanContext(obj); spanContext.IsValid() {
+			attrs = append(attrs, ListItemTraceIDKey.String(spanContext.TraceID().String()), ListItemSpanIDKey.String(spanContext.SpanID().String()))
+		}
+		_, span := tc.Tracer.Start(ctx, tc.spanNames.spanName("ListItem", gvk, client.ObjectKeyFromObject(obj)), trace.WithAttributes(attrs...))
+		span.End()
+	}
+}
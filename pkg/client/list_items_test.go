@@ -0,0 +1,52 @@
+package client
+
+import (
+	"context"
+	"testing"
+
+	constants "github.com/kubetracer/kubetracer-go/pkg/constants"
+	"github.com/stretchr/testify/assert"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestWithPerItemSpans(t *testing.T) {
+	tracedPod := &corev1.Pod{ObjectMeta: metav1.ObjectMeta{
+		Name:      "traced-pod",
+		Namespace: "default",
+		Annotations: map[string]string{
+			constants.TraceIDAnnotation: "0af7651916cd43dd8448eb211c80319c",
+			constants.SpanIDAnnotation:  "b7ad6b7169203331",
+		},
+	}}
+	pod := &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "pod", Namespace: "default"}}
+	k8sClient := fake.NewClientBuilder().WithObjects(tracedPod, pod).Build()
+	recorder := tracetest.NewSpanRecorder()
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSampler(sdktrace.AlwaysSample()), sdktrace.WithSpanProcessor(recorder))
+	tracingClient := NewTracingClientWithOptions(k8sClient, WithTracerProvider(tp))
+
+	assert.NoError(t, tracingClient.List(context.Background(), &corev1.PodList{}))
+	assert.Len(t, recorder.Ended(), 1)
+
+	pods := &corev1.PodList{}
+	assert.NoError(t, tracingClient.List(context.Background(), pods, WithPerItemSpans(), client.InNamespace("default")))
+	assert.Len(t, pods.Items, 2)
+
+	spans := recorder.Ended()[1:]
+	assert.Len(t, spans, 3)
+	listSpan := spans[2]
+	itemSpans := map[string]sdktrace.ReadOnlySpan{}
+	for _, span := range spans[:2] {
+		assert.Equal(t, listSpan.SpanContext().SpanID(), span.Parent().SpanID())
+		itemSpans[span.Name()] = span
+	}
+
+	assert.Contains(t, itemSpans["ListItem Pod traced-pod"].Attributes(), ListItemTraceIDKey.String("0af7651916cd43dd8448eb211c80319c"))
+	assert.Contains(t, itemSpans["ListItem Pod traced-pod"].Attributes(), K8sNamespaceNameKey.String("default"))
+	assert.NotContains(t, itemSpans["ListItem Pod pod"].Attributes(), ListItemTraceIDKey.String("0af7651916cd43dd8448eb211c80319c"))
+	assert.Contains(t, itemSpans["ListItem Pod pod"].Attributes(), K8sObjectNameKey.String("pod"))
+}
@@ -0,0 +1,79 @@
+package client
+
+import (
+	"context"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// DefaultMaxListLinks is the number of items of a List whose trace is linked from the List span unless
+// configured by WithMaxListLinks
+const DefaultMaxListLinks = 32
+
+// WithMaxListLinks bounds to max the items of a List whose trace is linked from the List span, so that large
+// Lists do not produce huge spans.  Items sharing a span are linked once.  A max of zero or less disables the
+// links.
+func WithMaxListLinks(max int) Option {
+	return func(o *clientOptions) {
+		o.maxListLinks = max
+		if max <= 0 {
+			o.maxListLinks = -1
+		}
+	}
+}
+
+// listLinkLimit returns the number of items whose trace is linked from a List span, zero if disabled
+func (tc *tracingClient) listLinkLimit() int {
+	switch {
+	case tc.maxListLinks == 0:
+		return DefaultMaxListLinks
+	case tc.maxListLinks < 0:
+		return 0
+	}
+	return tc.maxListLinks
+}
+
+// linkListItems links span to the trace carried by each item of list, up to the listLinkLimit
+func (tc *tracingClient) linkListItems(span trace.Span, list client.ObjectList) {
+	limit := tc.listLinkLimit()
+	if limit == 0 || !span.IsRecording() {
+		return
+	}
+	items, err := meta.ExtractList(list)
+	if err != nil {
+		return
+	}
+
+	type spanIDs struct {
+		traceID trace.TraceID
+		spanID  trace.SpanID
+	}
+	linked := map[spanIDs]bool{}
+	for _, item := range items {
+		if len(linked) == limit {
+			return
+		}
+		obj, ok := item.(client.Object)
+		if !ok {
+			continue
+		}
+		spanContext := tc.itemSpanContext(obj)
+		ids := spanIDs{traceID: spanContext.TraceID(), spanID: spanContext.SpanID()}
+		if !spanContext.IsValid() || linked[ids] {
+			continue
+		}
+		linked[ids] = true
+		span.AddLink(trace.Link{SpanContext: spanContext, Attributes: []attribute.KeyValue{
+			K8sNamespaceNameKey.String(obj.GetNamespace()),
+			K8sObjectNameKey.String(obj.GetName()),
+		}})
+	}
+}
+
+// itemSpanContext returns the span context of the trace carried by obj, invalid if it carries none
+func (tc *tracingClient) itemSpanContext(obj client.Object) trace.SpanContext {
+	return trace.SpanContextFromContext(tc.propagation.extract(context.Background(), tc.Logger, obj, tc.scheme))
+}
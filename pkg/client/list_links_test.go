@@ -0,0 +1,65 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	constants "github.com/kubetracer/kubetracer-go/pkg/constants"
+	"github.com/stretchr/testify/assert"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestListLinks(t *testing.T) {
+	tracedPod := func(name, traceID, spanID string) client.Object {
+		return &corev1.Pod{ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: "default",
+			Annotations: map[string]string{
+				constants.TraceIDAnnotation: traceID,
+				constants.SpanIDAnnotation:  spanID,
+			},
+		}}
+	}
+	pods := []client.Object{
+		tracedPod("pod-1", "0af7651916cd43dd8448eb211c80319c", "b7ad6b7169203331"),
+		tracedPod("pod-2", "0af7651916cd43dd8448eb211c80319c", "b7ad6b7169203331"),
+		tracedPod("pod-3", "f620f5cad0af940c294f980c5366a6a1", "45f359cdc1c8ab06"),
+		tracedPod("pod-4", "4bf92f3577b34da6a3ce929d0e0e4736", "00f067aa0ba902b7"),
+		&corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "pod-5", Namespace: "default"}},
+	}
+
+	tests := []struct {
+		opts  []Option
+		links int
+	}{
+		{links: 3},
+		{opts: []Option{WithMaxListLinks(2)}, links: 2},
+		{opts: []Option{WithMaxListLinks(0)}, links: 0},
+	}
+	for _, tt := range tests {
+		t.Run(fmt.Sprintf("%d links", tt.links), func(t *testing.T) {
+			k8sClient := fake.NewClientBuilder().WithObjects(pods...).Build()
+			recorder := tracetest.NewSpanRecorder()
+			tp := sdktrace.NewTracerProvider(sdktrace.WithSampler(sdktrace.AlwaysSample()), sdktrace.WithSpanProcessor(recorder))
+			tracingClient := NewTracingClientWithOptions(k8sClient, append(tt.opts, WithTracerProvider(tp))...)
+
+			assert.NoError(t, tracingClient.List(context.Background(), &corev1.PodList{}, client.InNamespace("default")))
+
+			spans := recorder.Ended()
+			assert.Len(t, spans, 1)
+			links := spans[0].Links()
+			assert.Len(t, links, tt.links)
+			if tt.links > 0 {
+				assert.Equal(t, "0af7651916cd43dd8448eb211c80319c", links[0].SpanContext.TraceID().String())
+				assert.True(t, links[0].SpanContext.IsRemote())
+				assert.Contains(t, links[0].Attributes, K8sObjectNameKey.String("pod-1"))
+			}
+		})
+	}
+}
@@ -0,0 +1,150 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/go-logr/logr"
+	otellog "go.opentelemetry.io/otel/log"
+	"go.opentelemetry.io/otel/trace"
+)
+
+var _ logr.LogSink = &otelLogSink{}
+
+// NewOTelLogger returns a logger emitting its records through the OTel logs bridge of provider, e.g. the
+// LoggerProvider of the OTel logs SDK exporting via OTLP, so the logs of the clients and controllers reach the
+// tracing backend correlated to their spans without a separate log pipeline.
+//
+// A record is emitted with the span context of the "traceID" and "spanID" values of the logger, as added by
+// TracedLogConstructor, or of a context.Context given as a value, which takes precedence:
+//
+//	logger := kubetracer.NewOTelLogger(loggerProvider)
+//	ctrl.NewControllerManagedBy(mgr).For(&appsv1.Deployment{}).WithOptions(controller.Options{
+//		LogConstructor: kubetracer.TracedLogConstructor(logger.WithValues("controller", "deployment"), "Deployment"),
+//	})
+//
+// Info records of V-level n have the severity INFO minus n, Error records the severity ERROR.
+func NewOTelLogger(provider otellog.LoggerProvider, opts ...otellog.LoggerOption) logr.Logger {
+	return logr.New(&otelLogSink{logger: provider.Logger(TracerName, opts...)})
+}
+
+// otelLogSink is the logr.LogSink of NewOTelLogger
+type otelLogSink struct {
+	logger otellog.Logger
+
+	// name is the name of the logr logger, recorded in the logger attribute
+	name string
+
+	// values are the key-values added by WithValues
+	values []interface{}
+}
+
+func (s *otelLogSink) Init(logr.RuntimeInfo) {}
+
+func (s *otelLogSink) Enabled(int) bool {
+	return s.logger.Enabled(context.Background(), otellog.EnabledParameters{})
+}
+
+func (s *otelLogSink) Info(level int, msg string, keysAndValues ...interface{}) {
+	severity := otellog.SeverityInfo - otellog.Severity(level)
+	if severity < otellog.SeverityTrace1 {
+		severity = otellog.SeverityTrace1
+	}
+	s.emit(severity, msg, nil, keysAndValues)
+}
+
+func (s *otelLogSink) Error(err error, msg string, keysAndValues ...interface{}) {
+	s.emit(otellog.SeverityError, msg, err, keysAndValues)
+}
+
+func (s *otelLogSink) WithValues(keysAndValues ...interface{}) logr.LogSink {
+	values := make([]interface{}, 0, len(s.values)+len(keysAndValues))
+	values = append(values, s.values...)
+	values = append(values, keysAndValues...)
+	return &otelLogSink{logger: s.logger, name: s.name, values: values}
+}
+
+func (s *otelLogSink) WithName(name string) logr.LogSink {
+	if s.name != "" {
+		name = s.name + "/" + name
+	}
+	return &otelLogSink{logger: s.logger, name: name, values: s.values}
+}
+
+// emit emits the record of msg with the key-values of the sink and keysAndValues
+func (s *otelLogSink) emit(severity otellog.Severity, msg string, err error, keysAndValues []interface{}) {
+	var record otellog.Record
+	record.SetTimestamp(time.Now())
+	record.SetSeverity(severity)
+	record.SetSeverityText(severity.String())
+	record.SetBody(otellog.StringValue(msg))
+	if s.name != "" {
+		record.AddAttributes(otellog.String("logger", s.name))
+	}
+	if err != nil {
+		record.AddAttributes(otellog.String("error", err.Error()))
+	}
+
+	ctx := context.Background()
+	var traceID, spanID string
+	for _, values := range [][]interface{}{s.values, keysAndValues} {
+		for i := 0; i+1 < len(values); i += 2 {
+			key := fmt.Sprint(values[i])
+			// a context is only used for the span context of the record, never recorded as an attribute
+			if valueCtx, ok := values[i+1].(context.Context); ok {
+				ctx = valueCtx
+				continue
+			}
+			switch key {
+			case "traceID":
+				traceID = fmt.Sprint(values[i+1])
+			case "spanID":
+				spanID = fmt.Sprint(values[i+1])
+			}
+			record.AddAttributes(logKeyValue(key, values[i+1]))
+		}
+	}
+
+	if !trace.SpanContextFromContext(ctx).IsValid() {
+		if spanContext, ok := remoteSpanContext(traceID, spanID); ok {
+			ctx = trace.ContextWithRemoteSpanContext(ctx, spanContext)
+		}
+	}
+	s.logger.Emit(ctx, record)
+}
+
+// remoteSpanContext returns the span context of the hex traceID and spanID, false if they are invalid
+func remoteSpanContext(traceID, spanID string) (trace.SpanContext, bool) {
+	tid, err := trace.TraceIDFromHex(traceID)
+	if err != nil {
+		return trace.SpanContext{}, false
+	}
+	sid, err := trace.SpanIDFromHex(spanID)
+	if err != nil {
+		return trace.SpanContext{}, false
+	}
+	return trace.NewSpanContext(trace.SpanContextConfig{TraceID: tid, SpanID: sid, TraceFlags: trace.FlagsSampled, Remote: true}), true
+}
+
+// logKeyValue returns the log attribute of a logr key-value
+func logKeyValue(key string, value interface{}) otellog.KeyValue {
+	switch v := value.(type) {
+	case string:
+		return otellog.String(key, v)
+	case bool:
+		return otellog.Bool(key, v)
+	case int:
+		return otellog.Int(key, v)
+	case int32:
+		return otellog.Int64(key, int64(v))
+	case int64:
+		return otellog.Int64(key, v)
+	case float64:
+		return otellog.Float64(key, v)
+	case error:
+		return otellog.String(key, v.Error())
+	default:
+		return otellog.String(key, fmt.Sprint(v))
+	}
+}
@@ -0,0 +1,115 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	otellog "go.opentelemetry.io/otel/log"
+	"go.opentelemetry.io/otel/log/embedded"
+	"go.opentelemetry.io/otel/trace"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+// emitted is a record emitted by a recordingLogger along with its context
+type emitted struct {
+	ctx    context.Context
+	record otellog.Record
+}
+
+// recordingLoggerProvider returns a recordingLogger for every name
+type recordingLoggerProvider struct {
+	embedded.LoggerProvider
+	logger *recordingLogger
+}
+
+func (p *recordingLoggerProvider) Logger(string, ...otellog.LoggerOption) otellog.Logger {
+	return p.logger
+}
+
+// recordingLogger records the emitted records
+type recordingLogger struct {
+	embedded.Logger
+	disabled bool
+	records  []emitted
+}
+
+func (l *recordingLogger) Emit(ctx context.Context, record otellog.Record) {
+	l.records = append(l.records, emitted{ctx: ctx, record: record})
+}
+
+func (l *recordingLogger) Enabled(context.Context, otellog.EnabledParameters) bool {
+	return !l.disabled
+}
+
+// attributes returns the string attributes of record
+func attributes(record otellog.Record) map[string]string {
+	attrs := map[string]string{}
+	record.WalkAttributes(func(kv otellog.KeyValue) bool {
+		attrs[kv.Key] = kv.Value.String()
+		return true
+	})
+	return attrs
+}
+
+func TestOTelLogger(t *testing.T) {
+	recorder := &recordingLogger{}
+	logger := NewOTelLogger(&recordingLoggerProvider{logger: recorder})
+
+	t.Run("traced request", func(t *testing.T) {
+		recorder.records = nil
+		req := &reconcile.Request{NamespacedName: types.NamespacedName{
+			Namespace: "default",
+			Name:      "f620f5cad0af940c294f980c5366a6a1;45f359cdc1c8ab06;Pod;test-pod;test-replicaset",
+		}}
+		TracedLogConstructor(logger.WithName("replicaset"), "ReplicaSet")(req).Info("Reconciling")
+
+		assert.Len(t, recorder.records, 1)
+		record := recorder.records[0].record
+		assert.Equal(t, "Reconciling", record.Body().AsString())
+		assert.Equal(t, otellog.SeverityInfo, record.Severity())
+		assert.Equal(t, "replicaset", attributes(record)["logger"])
+		assert.Equal(t, "Pod/test-pod", attributes(record)["triggeredBy"])
+
+		spanContext := trace.SpanContextFromContext(recorder.records[0].ctx)
+		assert.Equal(t, "f620f5cad0af940c294f980c5366a6a1", spanContext.TraceID().String())
+		assert.Equal(t, "45f359cdc1c8ab06", spanContext.SpanID().String())
+		assert.True(t, spanContext.IsRemote())
+		assert.True(t, spanContext.IsSampled())
+	})
+
+	t.Run("context value", func(t *testing.T) {
+		recorder.records = nil
+		traceID, _ := trace.TraceIDFromHex("0af7651916cd43dd8448eb211c80319c")
+		spanID, _ := trace.SpanIDFromHex("b7ad6b7169203331")
+		ctx := trace.ContextWithSpanContext(context.Background(), trace.NewSpanContext(trace.SpanContextConfig{TraceID: traceID, SpanID: spanID}))
+		logger.WithValues("traceID", "f620f5cad0af940c294f980c5366a6a1", "spanID", "45f359cdc1c8ab06").Error(errors.New("conflict"), "Reconcile failed", "ctx", ctx)
+
+		assert.Len(t, recorder.records, 1)
+		record := recorder.records[0].record
+		assert.Equal(t, otellog.SeverityError, record.Severity())
+		assert.Equal(t, "conflict", attributes(record)["error"])
+		assert.NotContains(t, attributes(record), "ctx")
+		assert.Equal(t, "0af7651916cd43dd8448eb211c80319c", trace.SpanContextFromContext(recorder.records[0].ctx).TraceID().String())
+	})
+
+	t.Run("untraced", func(t *testing.T) {
+		recorder.records = nil
+		logger.V(1).Info("Cache synced")
+
+		assert.Len(t, recorder.records, 1)
+		assert.Equal(t, otellog.SeverityInfo-1, recorder.records[0].record.Severity())
+		assert.False(t, trace.SpanContextFromContext(recorder.records[0].ctx).IsValid())
+	})
+
+	t.Run("disabled", func(t *testing.T) {
+		recorder.records = nil
+		recorder.disabled = true
+		defer func() { recorder.disabled = false }()
+		logger.Info("Dropped")
+
+		assert.Empty(t, recorder.records)
+	})
+}
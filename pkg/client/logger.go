@@ -0,0 +1,34 @@
+package client
+
+import (
+	"strings"
+
+	"github.com/go-logr/logr"
+	"k8s.io/klog/v2"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+// TracedLogConstructor returns a LogConstructor for controller.Options building the logger of each request
+// as controller-runtime does, with the name embedded by EmbedTraceIDInNamespacedName replaced by the name of
+// the object, and the traceID, spanID and triggeredBy values parsed from the request.  Code that never
+// touches the TracingClient then logs correlated IDs as well:
+//
+//	ctrl.NewControllerManagedBy(mgr).For(&appsv1.Deployment{}).WithOptions(controller.Options{
+//		LogConstructor: kubetracer.TracedLogConstructor(mgr.GetLogger().WithValues("controller", "deployment"), "Deployment"),
+//	})
+func TracedLogConstructor(logger logr.Logger, kind string) func(*reconcile.Request) logr.Logger {
+	return func(req *reconcile.Request) logr.Logger {
+		if req == nil {
+			return logger
+		}
+
+		name := getNameFromNamespacedName(req.NamespacedName)
+		log := logger.WithValues(kind, klog.KRef(req.Namespace, name), "namespace", req.Namespace, "name", name)
+
+		keyNameParts := strings.Split(req.Name, ";")
+		if len(keyNameParts) != 5 {
+			return log
+		}
+		return log.WithValues("traceID", keyNameParts[0], "spanID", keyNameParts[1], "triggeredBy", keyNameParts[2]+"/"+keyNameParts[3])
+	}
+}
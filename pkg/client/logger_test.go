@@ -0,0 +1,37 @@
+package client
+
+import (
+	"testing"
+
+	"github.com/go-logr/logr/funcr"
+	"github.com/stretchr/testify/assert"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+func TestTracedLogConstructor(t *testing.T) {
+	var lines []string
+	logger := funcr.New(func(prefix, args string) {
+		lines = append(lines, args)
+	}, funcr.Options{})
+	logConstructor := TracedLogConstructor(logger, "Pod")
+
+	// A request embedding the trace
+	logConstructor(&reconcile.Request{NamespacedName: types.NamespacedName{
+		Namespace: "default",
+		Name:      "f620f5cad0af940c294f980c5366a6a1;45f359cdc1c8ab06;ConfigMap;pod-configmap;default-pod",
+	}}).Info("reconciling")
+	// A plain request
+	logConstructor(&reconcile.Request{NamespacedName: types.NamespacedName{Namespace: "default", Name: "other-pod"}}).Info("reconciling")
+	// The logger of the controller
+	logConstructor(nil).Info("starting")
+
+	assert.Len(t, lines, 3)
+	assert.Contains(t, lines[0], `"name"="default-pod"`)
+	assert.Contains(t, lines[0], `"traceID"="f620f5cad0af940c294f980c5366a6a1"`)
+	assert.Contains(t, lines[0], `"spanID"="45f359cdc1c8ab06"`)
+	assert.Contains(t, lines[0], `"triggeredBy"="ConfigMap/pod-configmap"`)
+	assert.Contains(t, lines[1], `"name"="other-pod"`)
+	assert.NotContains(t, lines[1], "traceID")
+	assert.NotContains(t, lines[2], "name")
+}
@@ -0,0 +1,22 @@
+package client
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// keepMetadataGVK returns a func restoring the GVK of obj when it is a PartialObjectMetadata, as read by
+// metadata-only controllers.  Not every client keeps it on the responses of writes, and without it the
+// kind of the object, needed by the following spans and EndTrace, is lost.
+func keepMetadataGVK(obj client.Object) func() {
+	metadata, ok := obj.(*metav1.PartialObjectMetadata)
+	if !ok {
+		return func() {}
+	}
+	gvk := metadata.GroupVersionKind()
+	return func() {
+		if metadata.GroupVersionKind().Empty() {
+			metadata.SetGroupVersionKind(gvk)
+		}
+	}
+}
@@ -0,0 +1,59 @@
+package client
+
+import (
+	"context"
+	"testing"
+
+	"github.com/go-logr/logr"
+	constants "github.com/kubetracer/kubetracer-go/pkg/constants"
+	"github.com/stretchr/testify/assert"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestPartialObjectMetadata(t *testing.T) {
+	// Create a fake Kubernetes client holding a traced pod
+	k8sClient := fake.NewClientBuilder().WithObjects(&corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "metadata-pod",
+			Namespace: "default",
+			Annotations: map[string]string{
+				constants.TraceIDAnnotation: "f620f5cad0af940c294f980c5366a6a1",
+				constants.SpanIDAnnotation:  "45f359cdc1c8ab06",
+			},
+		},
+	}).Build()
+
+	recorder := tracetest.NewSpanRecorder()
+	tracer := sdktrace.NewTracerProvider(sdktrace.WithSampler(sdktrace.AlwaysSample()), sdktrace.WithSpanProcessor(recorder)).Tracer("kubetracer")
+	tracingClient := NewTracingClient(k8sClient, k8sClient, tracer, logr.Discard())
+
+	// Reconcile the pod only reading its metadata
+	metadata := &metav1.PartialObjectMetadata{}
+	metadata.SetGroupVersionKind(corev1.SchemeGroupVersion.WithKind("Pod"))
+	ctx, span, err := tracingClient.StartTrace(context.Background(), client.ObjectKey{Name: "metadata-pod", Namespace: "default"}, metadata)
+	assert.NoError(t, err)
+	assert.Equal(t, "f620f5cad0af940c294f980c5366a6a1", span.SpanContext().TraceID().String())
+
+	// Writes of the metadata carry the trace on
+	err = tracingClient.Patch(ctx, metadata, client.MergeFrom(metadata.DeepCopy()))
+	assert.NoError(t, err)
+	assert.Equal(t, "f620f5cad0af940c294f980c5366a6a1", metadata.Annotations[constants.TraceIDAnnotation])
+
+	_, err = tracingClient.EndTrace(ctx, metadata)
+	assert.NoError(t, err)
+	span.End()
+
+	pod := &corev1.Pod{}
+	err = k8sClient.Get(context.Background(), client.ObjectKey{Name: "metadata-pod", Namespace: "default"}, pod)
+	assert.NoError(t, err)
+	assert.Empty(t, pod.Annotations[constants.TraceIDAnnotation])
+
+	for _, s := range recorder.Ended() {
+		assert.NotContains(t, s.Name(), "PartialObjectMetadata")
+	}
+}
@@ -0,0 +1,105 @@
+package client
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const meterName = "github.com/kubetracer/kubetracer-go/pkg/client"
+
+// reconcileInterval records the time between consecutive StartTraces of the same object, by whether the
+// reconcile continued a propagated trace, so traced chains can be compared with background resyncs.
+// Instruments created from the global MeterProvider are forwarded to the provider set with
+// otel.SetMeterProvider, so this is a no-op until the binary configures one.
+var reconcileInterval, _ = otel.Meter(meterName).Float64Histogram(
+	"kubetracer.reconcile.interval",
+	metric.WithDescription("Time between consecutive reconciles of the same object."),
+	metric.WithUnit("s"),
+)
+
+const (
+	// maxReconcileTimes caps the number of objects whose last reconcile is tracked
+	maxReconcileTimes = 50000
+	// reconcileTimeExpiry is the time after which the last reconcile of an object is forgotten, e.g. when its
+	// deletion was not seen by StartTrace.  It is above the default resync period of the manager of 10h.
+	reconcileTimeExpiry = 12 * time.Hour
+)
+
+// reconcileTimes tracks the last StartTrace of each object for reconcileInterval.  The last reconciles expire
+// after expiry and at most maxSize are tracked, the oldest being dropped first.
+type reconcileTimes struct {
+	maxSize int
+	expiry  time.Duration
+
+	mu   sync.Mutex
+	last map[objectIdentity]time.Time
+}
+
+// newReconcileTimes returns a reconcileTimes with the default size and expiry
+func newReconcileTimes() *reconcileTimes {
+	return &reconcileTimes{
+		maxSize: maxReconcileTimes,
+		expiry:  reconcileTimeExpiry,
+		last:    map[objectIdentity]time.Time{},
+	}
+}
+
+// recordReconcile records the interval since the previous reconcile of obj, if any
+func (r *reconcileTimes) recordReconcile(ctx context.Context, obj client.Object, kind string, traced bool) {
+	now := time.Now()
+	identity := identityOf(obj)
+
+	r.mu.Lock()
+	previous, ok := r.last[identity]
+	if ok && now.Sub(previous) > r.expiry {
+		ok = false
+	}
+	if _, tracked := r.last[identity]; !tracked && len(r.last) >= r.maxSize {
+		r.evict(now)
+	}
+	r.last[identity] = now
+	r.mu.Unlock()
+
+	if !ok {
+		return
+	}
+	reconcileInterval.Record(ctx, now.Sub(previous).Seconds(), metric.WithAttributes(
+		attribute.String("kind", kind),
+		attribute.Bool("traced", traced),
+	))
+}
+
+// evict drops the expired reconciles, or the oldest one if none expired.  r.mu must be held.
+func (r *reconcileTimes) evict(now time.Time) {
+	var oldest objectIdentity
+	var oldestTime time.Time
+	evicted := false
+	for identity, last := range r.last {
+		if now.Sub(last) > r.expiry {
+			delete(r.last, identity)
+			evicted = true
+		} else if oldestTime.IsZero() || last.Before(oldestTime) {
+			oldest, oldestTime = identity, last
+		}
+	}
+	if !evicted && !oldestTime.IsZero() {
+		delete(r.last, oldest)
+	}
+}
+
+// forget stops tracking the object of the type of obj at key, once it is deleted
+func (r *reconcileTimes) forget(obj client.Object, key client.ObjectKey) {
+	identity := identityOf(obj)
+	identity.namespace = key.Namespace
+	identity.name = key.Name
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(r.last, identity)
+}
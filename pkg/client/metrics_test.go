@@ -0,0 +1,82 @@
+package client
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/otel"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// intervalCount returns the number of reconcile intervals collected by reader
+func intervalCount(t *testing.T, reader *sdkmetric.ManualReader) uint64 {
+	var rm metricdata.ResourceMetrics
+	assert.NoError(t, reader.Collect(context.Background(), &rm))
+
+	count := uint64(0)
+	for _, scopeMetrics := range rm.ScopeMetrics {
+		for _, m := range scopeMetrics.Metrics {
+			if m.Name != "kubetracer.reconcile.interval" {
+				continue
+			}
+			for _, dataPoint := range m.Data.(metricdata.Histogram[float64]).DataPoints {
+				count += dataPoint.Count
+			}
+		}
+	}
+	return count
+}
+
+func TestReconcileTimes(t *testing.T) {
+	reader := sdkmetric.NewManualReader(sdkmetric.WithTemporality(func(sdkmetric.InstrumentKind) metricdata.Temporality {
+		return metricdata.DeltaTemporality
+	}))
+	otel.SetMeterProvider(sdkmetric.NewMeterProvider(sdkmetric.WithReader(reader)))
+
+	ctx := context.Background()
+	configMap := func(name string) *corev1.ConfigMap {
+		return &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default"}}
+	}
+
+	t.Run("interval recorded", func(t *testing.T) {
+		r := newReconcileTimes()
+		r.recordReconcile(ctx, configMap("test-configmap"), "ConfigMap", true)
+		assert.Equal(t, uint64(0), intervalCount(t, reader))
+		r.recordReconcile(ctx, configMap("test-configmap"), "ConfigMap", true)
+		assert.Equal(t, uint64(1), intervalCount(t, reader))
+	})
+
+	t.Run("size capped", func(t *testing.T) {
+		r := newReconcileTimes()
+		r.maxSize = 2
+		r.recordReconcile(ctx, configMap("first"), "ConfigMap", false)
+		r.recordReconcile(ctx, configMap("second"), "ConfigMap", false)
+		r.recordReconcile(ctx, configMap("third"), "ConfigMap", false)
+		assert.Len(t, r.last, 2)
+		assert.NotContains(t, r.last, identityOf(configMap("first")))
+
+		// the reconciles still tracked are recorded
+		r.recordReconcile(ctx, configMap("third"), "ConfigMap", false)
+		assert.Equal(t, uint64(1), intervalCount(t, reader))
+	})
+
+	t.Run("expired reconciles dropped", func(t *testing.T) {
+		r := newReconcileTimes()
+		r.maxSize = 2
+		r.last[identityOf(configMap("expired"))] = time.Now().Add(-2 * reconcileTimeExpiry)
+		r.recordReconcile(ctx, configMap("first"), "ConfigMap", false)
+		r.recordReconcile(ctx, configMap("second"), "ConfigMap", false)
+		assert.Len(t, r.last, 2)
+		assert.NotContains(t, r.last, identityOf(configMap("expired")))
+
+		// no interval is recorded from an expired reconcile
+		r.last[identityOf(configMap("first"))] = time.Now().Add(-2 * reconcileTimeExpiry)
+		r.recordReconcile(ctx, configMap("first"), "ConfigMap", false)
+		assert.Equal(t, uint64(0), intervalCount(t, reader))
+	})
+}
@@ -0,0 +1,386 @@
+package client
+
+import (
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// StartTraceOption configures StartTrace.  It is passed alongside the client.GetOptions given to
+// StartTrace and is never forwarded to the underlying Reader.
+type StartTraceOption interface {
+	client.GetOption
+	applyToStartTrace(*startTraceOptions)
+}
+
+type startTraceOptions struct {
+	// recordTriggeredBy writes the TriggeredByAnnotation on the reconciled object
+	recordTriggeredBy bool
+
+	// apiReader if set is used to read the object when the Reader does not find it
+	apiReader client.Reader
+
+	// strict returns the error of reading the object
+	strict bool
+
+	// ownerChainDepth if positive is the number of ownerReference levels searched for a trace to adopt
+	ownerChainDepth int
+}
+
+// splitStartTraceOptions separates the StartTraceOptions from the client.GetOptions meant for the Reader
+func splitStartTraceOptions(opts []client.GetOption) (startTraceOptions, []client.GetOption) {
+	options := startTraceOptions{}
+	getOpts := make([]client.GetOption, 0, len(opts))
+	for _, opt := range opts {
+		if startTraceOpt, ok := opt.(StartTraceOption); ok {
+			startTraceOpt.applyToStartTrace(&options)
+			continue
+		}
+		getOpts = append(getOpts, opt)
+	}
+	return options, getOpts
+}
+
+// WithTriggeredByAnnotation makes StartTrace write the kubetracer.io/triggered-by annotation, describing
+// the object whose change was embedded in the request as Kind/namespace/name, on the reconciled object.
+// This lets kubectl users see what caused the latest reconcile without a tracing backend.
+func WithTriggeredByAnnotation() StartTraceOption {
+	return recordTriggeredBy{}
+}
+
+type recordTriggeredBy struct{}
+
+// ApplyToGet implements client.GetOption.  It has no effect on the Get.
+func (recordTriggeredBy) ApplyToGet(*client.GetOptions) {}
+
+func (recordTriggeredBy) applyToStartTrace(opts *startTraceOptions) {
+	opts.recordTriggeredBy = true
+}
+
+// WithAPIReaderFallback makes StartTrace retry the read against apiReader when the object is not found
+// by the TracingClient's Reader.  When the Reader is the cache, objects created moments ago may not
+// be in it yet; pass the manager's APIReader to avoid starting traces on a spurious NotFound.
+func WithAPIReaderFallback(apiReader client.Reader) StartTraceOption {
+	return apiReaderFallback{apiReader: apiReader}
+}
+
+type apiReaderFallback struct {
+	apiReader client.Reader
+}
+
+// ApplyToGet implements client.GetOption.  It has no effect on the Get.
+func (apiReaderFallback) ApplyToGet(*client.GetOptions) {}
+
+func (f apiReaderFallback) applyToStartTrace(opts *startTraceOptions) {
+	opts.apiReader = f.apiReader
+}
+
+// WithStrictErrors makes StartTrace return the error of reading the object, e.g. NotFound when it was deleted,
+// so that reconcilers can short-circuit with IgnoreNotFound instead of operating on an empty object.
+// The span is returned regardless and must still be ended.
+func WithStrictErrors() StartTraceOption {
+	return strictErrors{}
+}
+
+type strictErrors struct{}
+
+// ApplyToGet implements client.GetOption.  It has no effect on the Get.
+func (strictErrors) ApplyToGet(*client.GetOptions) {}
+
+func (strictErrors) applyToStartTrace(opts *startTraceOptions) {
+	opts.strict = true
+}
+
+// WriteOption configures the annotations written by Create, Update and Patch.  It is passed alongside the
+// options of the call and is never forwarded to the underlying Client.
+type WriteOption interface {
+	client.CreateOption
+	client.UpdateOption
+	client.PatchOption
+	applyToWrite(*writeOptions)
+}
+
+type writeOptions struct {
+	// traceLabel mirrors the trace ID into the TraceLabel
+	traceLabel bool
+
+	// traceparentData writes the traceparent into the data of ConfigMaps and Secrets
+	traceparentData bool
+
+	// dryRun skips writing the trace, set by the TracingClient for dry runs
+	dryRun bool
+}
+
+// splitWriteOptions separates the WriteOptions from the options meant for the Client
+func splitWriteOptions[O any](opts []O) ([]O, writeOptions) {
+	options := writeOptions{}
+	clientOpts := make([]O, 0, len(opts))
+	for _, opt := range opts {
+		if writeOpt, ok := any(opt).(WriteOption); ok {
+			writeOpt.applyToWrite(&options)
+			continue
+		}
+		clientOpts = append(clientOpts, opt)
+	}
+	return clientOpts, options
+}
+
+// WithTraceLabel makes Create, Update and Patch mirror the trace ID into the kubetracer.io/trace label,
+// so all objects of a trace can be selected with `kubectl get --selector kubetracer.io/trace=<trace ID>`
+// or a label selector on an informer.  The annotations remain the source of truth.
+func WithTraceLabel() WriteOption {
+	return traceLabel{}
+}
+
+type traceLabel struct{}
+
+// ApplyToCreate implements client.CreateOption.  It has no effect on the Create.
+func (traceLabel) ApplyToCreate(*client.CreateOptions) {}
+
+// ApplyToUpdate implements client.UpdateOption.  It has no effect on the Update.
+func (traceLabel) ApplyToUpdate(*client.UpdateOptions) {}
+
+// ApplyToPatch implements client.PatchOption.  It has no effect on the Patch.
+func (traceLabel) ApplyToPatch(*client.PatchOptions) {}
+
+func (traceLabel) applyToWrite(opts *writeOptions) {
+	opts.traceLabel = true
+}
+
+// WithTraceparentData makes Create, Update and Patch write the W3C traceparent of the span into the
+// kubetracer.traceparent data key of ConfigMaps and Secrets, so applications mounting them can continue
+// the trace without downward-API or environment plumbing.  It has no effect on other kinds.
+func WithTraceparentData() WriteOption {
+	return traceparentData{}
+}
+
+type traceparentData struct{}
+
+// ApplyToCreate implements client.CreateOption.  It has no effect on the Create.
+func (traceparentData) ApplyToCreate(*client.CreateOptions) {}
+
+// ApplyToUpdate implements client.UpdateOption.  It has no effect on the Update.
+func (traceparentData) ApplyToUpdate(*client.UpdateOptions) {}
+
+// ApplyToPatch implements client.PatchOption.  It has no effect on the Patch.
+func (traceparentData) ApplyToPatch(*client.PatchOptions) {}
+
+func (traceparentData) applyToWrite(opts *writeOptions) {
+	opts.traceparentData = true
+}
+
+// SpanOption enriches the span of a single Create, Update, Patch or Delete.  It is passed alongside the options
+// of the call and is never forwarded to the underlying Client.
+type SpanOption interface {
+	client.CreateOption
+	client.UpdateOption
+	client.PatchOption
+	client.DeleteOption
+	spanStartOptions() []trace.SpanStartOption
+}
+
+// splitSpanOptions separates the SpanOptions from the options meant for the Client
+func splitSpanOptions[O any](opts []O) ([]O, []trace.SpanStartOption) {
+	var spanOpts []trace.SpanStartOption
+	clientOpts := make([]O, 0, len(opts))
+	for _, opt := range opts {
+		if spanOpt, ok := any(opt).(SpanOption); ok {
+			spanOpts = append(spanOpts, spanOpt.spanStartOptions()...)
+			continue
+		}
+		clientOpts = append(clientOpts, opt)
+	}
+	return clientOpts, spanOpts
+}
+
+// WithAttributes adds attrs to the span of the call, e.g. the reason for an Update
+func WithAttributes(attrs ...attribute.KeyValue) SpanOption {
+	return spanOption{trace.WithAttributes(attrs...)}
+}
+
+// WithSpanKind sets the kind of the span of the call, which is internal by default
+func WithSpanKind(kind trace.SpanKind) SpanOption {
+	return spanOption{trace.WithSpanKind(kind)}
+}
+
+// WithLinks links the span of the call to other spans, e.g. to the spans of the events which were batched
+// into the write
+func WithLinks(links ...trace.Link) SpanOption {
+	return spanOption{trace.WithLinks(links...)}
+}
+
+type spanOption []trace.SpanStartOption
+
+// ApplyToCreate implements client.CreateOption.  It has no effect on the Create.
+func (spanOption) ApplyToCreate(*client.CreateOptions) {}
+
+// ApplyToUpdate implements client.UpdateOption.  It has no effect on the Update.
+func (spanOption) ApplyToUpdate(*client.UpdateOptions) {}
+
+// ApplyToPatch implements client.PatchOption.  It has no effect on the Patch.
+func (spanOption) ApplyToPatch(*client.PatchOptions) {}
+
+// ApplyToDelete implements client.DeleteOption.  It has no effect on the Delete.
+func (spanOption) ApplyToDelete(*client.DeleteOptions) {}
+
+func (o spanOption) spanStartOptions() []trace.SpanStartOption {
+	return o
+}
+
+// ReadOption configures the reads of Get and List.  It is passed alongside the options of the call and is never
+// forwarded to the underlying Client.
+type ReadOption interface {
+	client.GetOption
+	client.ListOption
+	applyToRead(*readOptions)
+}
+
+type readOptions struct {
+	// live reads from the API reader instead of the cache
+	live bool
+
+	// perItemSpans records a child span of the List span per item
+	perItemSpans bool
+}
+
+// splitReadOptions separates the ReadOptions from the options meant for the Client
+func splitReadOptions[O any](opts []O) ([]O, readOptions) {
+	options := readOptions{}
+	clientOpts := make([]O, 0, len(opts))
+	for _, opt := range opts {
+		if readOpt, ok := any(opt).(ReadOption); ok {
+			readOpt.applyToRead(&options)
+			continue
+		}
+		clientOpts = append(clientOpts, opt)
+	}
+	return clientOpts, options
+}
+
+// WithLiveRead makes Get and List read from the API reader given to NewTracingClientWithAPIReader instead of
+// the cache, e.g. right after a write the cache may not have observed yet.  It has no effect when the
+// TracingClient has no API reader.
+func WithLiveRead() ReadOption {
+	return liveRead{}
+}
+
+type liveRead struct{}
+
+// ApplyToGet implements client.GetOption.  It has no effect on the Get.
+func (liveRead) ApplyToGet(*client.GetOptions) {}
+
+// ApplyToList implements client.ListOption.  It has no effect on the List.
+func (liveRead) ApplyToList(*client.ListOptions) {}
+
+func (liveRead) applyToRead(opts *readOptions) {
+	opts.live = true
+}
+
+// WithPerItemSpans makes List record a child span of the List span per returned item, with the key of the item
+// and the trace it carries, for debugging fan-out reconcilers acting on every listed object.  Large Lists record
+// as many spans, so it is best kept to debugging.
+func WithPerItemSpans() client.ListOption {
+	return perItemSpans{}
+}
+
+type perItemSpans struct{}
+
+// ApplyToGet implements client.GetOption, so that splitReadOptions strips it.  It is never given to Get.
+func (perItemSpans) ApplyToGet(*client.GetOptions) {}
+
+// ApplyToList implements client.ListOption.  It has no effect on the List.
+func (perItemSpans) ApplyToList(*client.ListOptions) {}
+
+func (perItemSpans) applyToRead(opts *readOptions) {
+	opts.perItemSpans = true
+}
+
+// WithAffectedCount makes DeleteAllOf List the objects matching its options before deleting them, and record
+// their number on the span in the kubetracer.affected_count attribute.  It costs a List, read from the cache
+// like any other.
+func WithAffectedCount() client.DeleteAllOfOption {
+	return affectedCount{}
+}
+
+type affectedCount struct{}
+
+// ApplyToDeleteAllOf implements client.DeleteAllOfOption.  It has no effect on the DeleteAllOf.
+func (affectedCount) ApplyToDeleteAllOf(*client.DeleteAllOfOptions) {}
+
+// splitAffectedCount separates WithAffectedCount from the options meant for the Client
+func splitAffectedCount[O any](opts []O) ([]O, bool) {
+	count := false
+	clientOpts := make([]O, 0, len(opts))
+	for _, opt := range opts {
+		if _, ok := any(opt).(affectedCount); ok {
+			count = true
+			continue
+		}
+		clientOpts = append(clientOpts, opt)
+	}
+	return clientOpts, count
+}
+
+// EndTraceOption selects the parts of the trace removed by EndTrace.  It is passed alongside the options of the
+// call and is never forwarded to the underlying Client.
+type EndTraceOption interface {
+	client.PatchOption
+	applyToEndTrace(*endTraceOptions)
+}
+
+type endTraceOptions struct {
+	// skipAnnotations leaves the annotations and the label
+	skipAnnotations bool
+
+	// skipConditions leaves the status
+	skipConditions bool
+
+	// keepTraceID leaves the trace ID, in the annotations, the label and the status
+	keepTraceID bool
+}
+
+// splitEndTraceOptions separates the EndTraceOptions from the options meant for the Client
+func splitEndTraceOptions(opts []client.PatchOption) ([]client.PatchOption, endTraceOptions) {
+	options := endTraceOptions{}
+	clientOpts := make([]client.PatchOption, 0, len(opts))
+	for _, opt := range opts {
+		if endTraceOpt, ok := opt.(EndTraceOption); ok {
+			endTraceOpt.applyToEndTrace(&options)
+			continue
+		}
+		clientOpts = append(clientOpts, opt)
+	}
+	return clientOpts, options
+}
+
+// OnlyAnnotations makes EndTrace remove the trace annotations and label only, leaving the status alone, e.g.
+// for controllers which never write the status.  No status patch is sent.
+func OnlyAnnotations() EndTraceOption {
+	return endTraceOption(func(opts *endTraceOptions) {
+		opts.skipConditions = true
+	})
+}
+
+// OnlyConditions makes EndTrace remove the trace from the status only, leaving the annotations and label alone
+func OnlyConditions() EndTraceOption {
+	return endTraceOption(func(opts *endTraceOptions) {
+		opts.skipAnnotations = true
+	})
+}
+
+// KeepTraceID makes EndTrace leave the trace ID, in the annotation, the label and the status, removing the
+// span ID and the other trace metadata.  The object remains selectable by trace afterwards.
+func KeepTraceID() EndTraceOption {
+	return endTraceOption(func(opts *endTraceOptions) {
+		opts.keepTraceID = true
+	})
+}
+
+type endTraceOption func(*endTraceOptions)
+
+// ApplyToPatch implements client.PatchOption.  It has no effect on the Patch.
+func (endTraceOption) ApplyToPatch(*client.PatchOptions) {}
+
+func (o endTraceOption) applyToEndTrace(opts *endTraceOptions) {
+	o(opts)
+}
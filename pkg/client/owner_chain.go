@@ -0,0 +1,97 @@
+package client
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/go-logr/logr"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// TraceOwnerKey is the StartTrace span attribute holding the owner, as Kind/name, whose trace was adopted by
+// WithOwnerChainFallback
+const TraceOwnerKey = attribute.Key("kubetracer.trace.owner")
+
+// WithOwnerChainFallback makes StartTrace adopt the trace of an owner when the reconciled object carries none
+// and no trace is embedded in the request or the context.  The ownerReferences are followed through the
+// Reader, the controller reference first, up to maxDepth levels, so that e.g. a Pod joins the trace of its
+// Deployment even when the ReplicaSet in between was not annotated.  A maxDepth of zero or less disables it.
+func WithOwnerChainFallback(maxDepth int) StartTraceOption {
+	return ownerChainFallback{maxDepth: maxDepth}
+}
+
+type ownerChainFallback struct {
+	maxDepth int
+}
+
+// ApplyToGet implements client.GetOption.  It has no effect on the Get.
+func (ownerChainFallback) ApplyToGet(*client.GetOptions) {}
+
+func (f ownerChainFallback) applyToStartTrace(opts *startTraceOptions) {
+	opts.ownerChainDepth = f.maxDepth
+}
+
+// ownerTrace returns the trace carried by the closest owner of obj, walking up to maxDepth levels of
+// ownerReferences, along with the owner as Kind/name.  Owners which cannot be read end the walk.
+func (tc *tracingClient) ownerTrace(ctx context.Context, obj client.Object, maxDepth int) (trace.SpanContext, string) {
+	current := obj
+	for depth := 0; depth < maxDepth; depth++ {
+		ref := ownerOf(current)
+		if ref == nil {
+			break
+		}
+		owner, err := tc.getOwner(ctx, obj.GetNamespace(), *ref)
+		if err != nil {
+			tc.Logger.V(1).Info("Failed to read owner", "owner", ref.Name, "kind", ref.Kind, "error", err)
+			break
+		}
+		spanContext := trace.SpanContextFromContext(tc.propagation.extract(ctx, logr.Discard(), owner, tc.scheme))
+		if spanContext.IsValid() {
+			return spanContext, fmt.Sprintf("%s/%s", ref.Kind, ref.Name)
+		}
+		current = owner
+	}
+	return trace.SpanContext{}, ""
+}
+
+// ownerOf returns the controller reference of obj, or its first ownerReference when it has no controller
+func ownerOf(obj client.Object) *metav1.OwnerReference {
+	if ref := metav1.GetControllerOfNoCopy(obj); ref != nil {
+		return ref
+	}
+	if refs := obj.GetOwnerReferences(); len(refs) > 0 {
+		return &refs[0]
+	}
+	return nil
+}
+
+// getOwner reads the owner referenced by ref, as a typed object when its kind is registered in the scheme and
+// as an unstructured object otherwise.  Owners of cluster-scoped kinds are read without namespace.
+func (tc *tracingClient) getOwner(ctx context.Context, namespace string, ref metav1.OwnerReference) (client.Object, error) {
+	gv, err := schema.ParseGroupVersion(ref.APIVersion)
+	if err != nil {
+		return nil, err
+	}
+	gvk := gv.WithKind(ref.Kind)
+
+	var owner client.Object
+	if newObj, err := tc.scheme.New(gvk); err == nil {
+		owner, _ = newObj.(client.Object)
+	}
+	if owner == nil {
+		u := &unstructured.Unstructured{}
+		u.SetGroupVersionKind(gvk)
+		owner = u
+	}
+
+	if namespaced, err := tc.Client.IsObjectNamespaced(owner); err == nil && !namespaced {
+		namespace = ""
+	}
+	err = tc.Reader.Get(ctx, client.ObjectKey{Namespace: namespace, Name: ref.Name}, owner)
+	return owner, err
+}
@@ -0,0 +1,79 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	constants "github.com/kubetracer/kubetracer-go/pkg/constants"
+	"github.com/stretchr/testify/assert"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestStartTraceWithOwnerChainFallback(t *testing.T) {
+	isController := true
+	deployment := &appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{
+		Name:      "web",
+		Namespace: "default",
+		UID:       "deployment-uid",
+		Annotations: map[string]string{
+			constants.TraceIDAnnotation: "0af7651916cd43dd8448eb211c80319c",
+			constants.SpanIDAnnotation:  "b7ad6b7169203331",
+		},
+	}}
+	replicaSet := &appsv1.ReplicaSet{ObjectMeta: metav1.ObjectMeta{
+		Name:      "web-5d4f8",
+		Namespace: "default",
+		UID:       "replicaset-uid",
+		OwnerReferences: []metav1.OwnerReference{
+			{APIVersion: "apps/v1", Kind: "Deployment", Name: "web", UID: "deployment-uid", Controller: &isController},
+		},
+	}}
+	pod := &corev1.Pod{ObjectMeta: metav1.ObjectMeta{
+		Name:      "web-5d4f8-x2x9z",
+		Namespace: "default",
+		OwnerReferences: []metav1.OwnerReference{
+			{APIVersion: "apps/v1", Kind: "ReplicaSet", Name: "web-5d4f8", UID: "replicaset-uid", Controller: &isController},
+		},
+	}}
+
+	tests := []struct {
+		opts    []client.GetOption
+		adopted bool
+	}{
+		{adopted: false},
+		{opts: []client.GetOption{WithOwnerChainFallback(1)}, adopted: false},
+		{opts: []client.GetOption{WithOwnerChainFallback(2)}, adopted: true},
+		{opts: []client.GetOption{WithOwnerChainFallback(0)}, adopted: false},
+	}
+	for i, tt := range tests {
+		t.Run(fmt.Sprintf("case %d", i), func(t *testing.T) {
+			k8sClient := fake.NewClientBuilder().WithObjects(deployment, replicaSet, pod).Build()
+			recorder := tracetest.NewSpanRecorder()
+			tp := sdktrace.NewTracerProvider(sdktrace.WithSampler(sdktrace.AlwaysSample()), sdktrace.WithSpanProcessor(recorder))
+			tracingClient := NewTracingClientWithOptions(k8sClient, WithTracerProvider(tp))
+
+			retrievedPod := &corev1.Pod{}
+			_, span, err := tracingClient.StartTrace(context.Background(), client.ObjectKeyFromObject(pod), retrievedPod, tt.opts...)
+			span.End()
+			assert.NoError(t, err)
+
+			spans := recorder.Ended()
+			assert.Len(t, spans, 1)
+			if tt.adopted {
+				assert.Equal(t, "0af7651916cd43dd8448eb211c80319c", spans[0].SpanContext().TraceID().String())
+				assert.Equal(t, "b7ad6b7169203331", spans[0].Parent().SpanID().String())
+				assert.Contains(t, spans[0].Attributes(), TraceOwnerKey.String("Deployment/web"))
+			} else {
+				assert.NotEqual(t, "0af7651916cd43dd8448eb211c80319c", spans[0].SpanContext().TraceID().String())
+				assert.False(t, spans[0].Parent().IsValid())
+			}
+		})
+	}
+}
@@ -0,0 +1,52 @@
+package client
+
+import (
+	"encoding/json"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	// PatchTypeKey is the span attribute holding the type of a patch: "json", "merge", "strategic" or "apply"
+	PatchTypeKey = attribute.Key("kubetracer.patch.type")
+	// PatchOperationsKey is the span attribute holding the number of operations of a JSON patch
+	PatchOperationsKey = attribute.Key("kubetracer.patch.operations")
+	// PatchSizeKey is the span attribute holding the size in bytes of the payload of a patch
+	PatchSizeKey = attribute.Key("kubetracer.patch.size")
+)
+
+// patchTypes names the types of patches in the PatchTypeKey attribute
+var patchTypes = map[types.PatchType]string{
+	types.JSONPatchType:           "json",
+	types.MergePatchType:          "merge",
+	types.StrategicMergePatchType: "strategic",
+	types.ApplyPatchType:          "apply",
+}
+
+// recordPatch sets the type, size and, for JSON patches, number of operations of patch of obj on the span.  The
+// payload is only computed when the span is recording, and not recorded if it cannot be.
+func recordPatch(span trace.Span, patch client.Patch, obj client.Object) {
+	if !span.IsRecording() {
+		return
+	}
+	patchType, ok := patchTypes[patch.Type()]
+	if !ok {
+		patchType = string(patch.Type())
+	}
+	span.SetAttributes(PatchTypeKey.String(patchType))
+
+	data, err := patch.Data(obj)
+	if err != nil {
+		return
+	}
+	span.SetAttributes(PatchSizeKey.Int(len(data)))
+	if patch.Type() == types.JSONPatchType {
+		var operations []json.RawMessage
+		if json.Unmarshal(data, &operations) == nil {
+			span.SetAttributes(PatchOperationsKey.Int(len(operations)))
+		}
+	}
+}
@@ -0,0 +1,68 @@
+package client
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/client/interceptor"
+)
+
+func TestPatchAttributes(t *testing.T) {
+	pod := &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "test-pod", Namespace: "default"}}
+	k8sClient := fake.NewClientBuilder().WithObjects(pod).Build()
+	recorder := tracetest.NewSpanRecorder()
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSampler(sdktrace.AlwaysSample()), sdktrace.WithSpanProcessor(recorder))
+	tracingClient := NewTracingClientWithOptions(k8sClient, WithTracerProvider(tp))
+
+	patched := pod.DeepCopy()
+	patched.Labels = map[string]string{"app": "web"}
+	assert.NoError(t, tracingClient.Patch(context.Background(), patched, client.MergeFrom(pod)))
+
+	jsonPatch := []byte(`[{"op":"add","path":"/metadata/labels/tier","value":"frontend"},{"op":"remove","path":"/metadata/labels/app"}]`)
+	assert.NoError(t, tracingClient.Patch(context.Background(), patched, client.RawPatch("application/json-patch+json", jsonPatch)))
+
+	spans := recorder.Ended()
+	assert.Len(t, spans, 2)
+	assert.Contains(t, spans[0].Attributes(), PatchTypeKey.String("merge"))
+	for _, attr := range spans[0].Attributes() {
+		assert.NotEqual(t, PatchOperationsKey, attr.Key)
+		if attr.Key == PatchSizeKey {
+			assert.Positive(t, attr.Value.AsInt64())
+		}
+	}
+	assert.Contains(t, spans[1].Attributes(), PatchTypeKey.String("json"))
+	assert.Contains(t, spans[1].Attributes(), PatchOperationsKey.Int(2))
+	assert.Contains(t, spans[1].Attributes(), PatchSizeKey.Int(len(jsonPatch)))
+}
+
+func TestStatusApplySkipsTraceConditions(t *testing.T) {
+	var applied *corev1.Pod
+	k8sClient := fake.NewClientBuilder().WithInterceptorFuncs(interceptor.Funcs{
+		SubResourcePatch: func(ctx context.Context, c client.Client, subResourceName string, obj client.Object, patch client.Patch, opts ...client.SubResourcePatchOption) error {
+			applied = obj.(*corev1.Pod).DeepCopy()
+			return nil
+		},
+	}).Build()
+	recorder := tracetest.NewSpanRecorder()
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSampler(sdktrace.AlwaysSample()), sdktrace.WithSpanProcessor(recorder))
+	tracingClient := NewTracingClientWithOptions(k8sClient, WithTracerProvider(tp))
+
+	pod := &corev1.Pod{
+		TypeMeta:   metav1.TypeMeta{APIVersion: "v1", Kind: "Pod"},
+		ObjectMeta: metav1.ObjectMeta{Name: "test-pod", Namespace: "default"},
+		Status:     corev1.PodStatus{Phase: corev1.PodRunning},
+	}
+	assert.NoError(t, tracingClient.Status().Patch(context.Background(), pod, client.Apply, client.FieldOwner("my-controller")))
+	assert.Empty(t, applied.Status.Conditions)
+	assert.Contains(t, recorder.Ended()[0].Attributes(), PatchTypeKey.String("apply"))
+
+	assert.NoError(t, tracingClient.Status().Patch(context.Background(), pod, client.Merge))
+	assert.NotEmpty(t, applied.Status.Conditions)
+}
@@ -0,0 +1,88 @@
+package client
+
+import (
+	"context"
+	"errors"
+
+	constants "github.com/kubetracer/kubetracer-go/pkg/constants"
+	"go.opentelemetry.io/otel/trace"
+	corev1 "k8s.io/api/core/v1"
+)
+
+// TraceparentEnv is the environment variable read by OTel SDKs, and set by WithTraceparentEnv, to continue the
+// trace inside a Pod
+const TraceparentEnv = "TRACEPARENT"
+
+// traceparentAnnotation holds the W3C traceparent referenced by the TraceparentEnv environment variables
+const traceparentAnnotation = constants.PropagatorAnnotationPrefix + "traceparent"
+
+// PodTemplateOption configures InjectTraceIntoPodTemplate
+type PodTemplateOption func(*podTemplateOptions)
+
+type podTemplateOptions struct {
+	// traceparentEnv if set exposes the traceparent to the containers as the TraceparentEnv environment variable
+	traceparentEnv bool
+}
+
+// WithTraceparentEnv makes InjectTraceIntoPodTemplate also set the TRACEPARENT environment variable of every
+// container, init containers included, from the traceparent annotation through the downward API.  The
+// traceparent annotation is then written whichever propagator is globally configured.  Containers already
+// setting TRACEPARENT are left untouched.
+func WithTraceparentEnv() PodTemplateOption {
+	return func(o *podTemplateOptions) {
+		o.traceparentEnv = true
+	}
+}
+
+// InjectTraceIntoPodTemplate writes the trace of the span in ctx into the annotations of template, so that the
+// Pods spawned by a Deployment, Job or StatefulSet created by the controller join its trace.  The trace ID and
+// span ID annotations, as well as the annotations of the globally configured propagator, are written.  An
+// error is returned, and template is left untouched, if ctx does not carry a valid span.
+func InjectTraceIntoPodTemplate(ctx context.Context, template *corev1.PodTemplateSpec, opts ...PodTemplateOption) error {
+	options := podTemplateOptions{}
+	for _, opt := range opts {
+		opt(&options)
+	}
+
+	spanContext := trace.SpanContextFromContext(ctx)
+	if !spanContext.IsValid() {
+		return errors.New("no valid span in context to inject into the pod template")
+	}
+
+	// PodTemplateSpec is not a client.Object, so inject through a Pod sharing its metadata
+	pod := &corev1.Pod{ObjectMeta: template.ObjectMeta}
+	if err := InjectSpanContext(spanContext, pod); err != nil {
+		return err
+	}
+	InjectIntoObject(ctx, pod)
+	if options.traceparentEnv {
+		pod.Annotations[traceparentAnnotation] = traceparentOf(spanContext)
+	}
+	template.Annotations = pod.Annotations
+
+	if options.traceparentEnv {
+		for i := range template.Spec.InitContainers {
+			addTraceparentEnv(&template.Spec.InitContainers[i])
+		}
+		for i := range template.Spec.Containers {
+			addTraceparentEnv(&template.Spec.Containers[i])
+		}
+	}
+	return nil
+}
+
+// addTraceparentEnv sets the TraceparentEnv environment variable of container from the traceparent annotation,
+// unless already set
+func addTraceparentEnv(container *corev1.Container) {
+	for _, env := range container.Env {
+		if env.Name == TraceparentEnv {
+			return
+		}
+	}
+	container.Env = append(container.Env, corev1.EnvVar{
+		Name: TraceparentEnv,
+		ValueFrom: &corev1.EnvVarSource{
+			FieldRef: &corev1.ObjectFieldSelector{FieldPath: "metadata.annotations['" + traceparentAnnotation + "']"},
+		},
+	})
+}
@@ -0,0 +1,66 @@
+package client
+
+import (
+	"context"
+	"testing"
+
+	constants "github.com/kubetracer/kubetracer-go/pkg/constants"
+	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestInjectTraceIntoPodTemplate(t *testing.T) {
+	previous := otel.GetTextMapPropagator()
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator())
+	t.Cleanup(func() { otel.SetTextMapPropagator(previous) })
+
+	traceID, _ := trace.TraceIDFromHex("0af7651916cd43dd8448eb211c80319c")
+	spanID, _ := trace.SpanIDFromHex("b7ad6b7169203331")
+	ctx := trace.ContextWithSpanContext(context.Background(), trace.NewSpanContext(trace.SpanContextConfig{
+		TraceID: traceID, SpanID: spanID, TraceFlags: trace.FlagsSampled,
+	}))
+
+	newTemplate := func() *corev1.PodTemplateSpec {
+		return &corev1.PodTemplateSpec{
+			ObjectMeta: metav1.ObjectMeta{Labels: map[string]string{"app": "web"}},
+			Spec: corev1.PodSpec{
+				InitContainers: []corev1.Container{{Name: "init"}},
+				Containers: []corev1.Container{
+					{Name: "app"},
+					{Name: "sidecar", Env: []corev1.EnvVar{{Name: TraceparentEnv, Value: "custom"}}},
+				},
+			},
+		}
+	}
+
+	t.Run("annotations", func(t *testing.T) {
+		template := newTemplate()
+		assert.NoError(t, InjectTraceIntoPodTemplate(ctx, template))
+		assert.Equal(t, "0af7651916cd43dd8448eb211c80319c", template.Annotations[constants.TraceIDAnnotation])
+		assert.Equal(t, "b7ad6b7169203331", template.Annotations[constants.SpanIDAnnotation])
+		assert.NotContains(t, template.Annotations, traceparentAnnotation)
+		assert.Empty(t, template.Spec.Containers[0].Env)
+	})
+
+	t.Run("traceparent env", func(t *testing.T) {
+		template := newTemplate()
+		assert.NoError(t, InjectTraceIntoPodTemplate(ctx, template, WithTraceparentEnv()))
+		assert.Equal(t, "00-0af7651916cd43dd8448eb211c80319c-b7ad6b7169203331-01", template.Annotations[traceparentAnnotation])
+		for _, container := range []corev1.Container{template.Spec.InitContainers[0], template.Spec.Containers[0]} {
+			assert.Len(t, container.Env, 1)
+			assert.Equal(t, "metadata.annotations['otel.kubetracer.io/traceparent']", container.Env[0].ValueFrom.FieldRef.FieldPath)
+		}
+		assert.Equal(t, []corev1.EnvVar{{Name: TraceparentEnv, Value: "custom"}}, template.Spec.Containers[1].Env)
+	})
+
+	t.Run("no span", func(t *testing.T) {
+		template := newTemplate()
+		assert.Error(t, InjectTraceIntoPodTemplate(context.Background(), template, WithTraceparentEnv()))
+		assert.Nil(t, template.Annotations)
+		assert.Empty(t, template.Spec.Containers[0].Env)
+	})
+}
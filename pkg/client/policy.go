@@ -0,0 +1,60 @@
+package client
+
+import (
+	"slices"
+
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/apiutil"
+)
+
+// tracePolicy decides which objects the trace is written to.  Spans are recorded for all objects regardless.
+type tracePolicy struct {
+	// readOnly never writes the trace
+	readOnly bool
+
+	// allowNamespaces if not empty are the only namespaces the trace is written in
+	allowNamespaces []string
+	// denyNamespaces are the namespaces the trace is never written in
+	denyNamespaces []string
+
+	// kindPredicate if set tells the kinds the trace is written to
+	kindPredicate func(schema.GroupVersionKind) bool
+	// scheme looks up the kind of objects for the kindPredicate
+	scheme *runtime.Scheme
+}
+
+// writes reports whether the trace is written to obj.  A nil policy writes it to every object.
+func (p *tracePolicy) writes(obj client.Object) bool {
+	if p == nil {
+		return true
+	}
+	if p.readOnly {
+		return false
+	}
+	return p.allowsNamespace(obj.GetNamespace()) && p.allowsKind(obj)
+}
+
+// allowsKind reports whether the trace is written to objects of the kind of obj, never if it cannot be told
+func (p *tracePolicy) allowsKind(obj client.Object) bool {
+	if p.kindPredicate == nil {
+		return true
+	}
+	gvk, err := apiutil.GVKForObject(obj, p.scheme)
+	if err != nil {
+		return false
+	}
+	return p.kindPredicate(gvk)
+}
+
+// allowsNamespace reports whether the trace is written in namespace, always for cluster-scoped objects
+func (p *tracePolicy) allowsNamespace(namespace string) bool {
+	if namespace == "" {
+		return true
+	}
+	if slices.Contains(p.denyNamespaces, namespace) {
+		return false
+	}
+	return len(p.allowNamespaces) == 0 || slices.Contains(p.allowNamespaces, namespace)
+}
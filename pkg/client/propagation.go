@@ -0,0 +1,210 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	constants "github.com/kubetracer/kubetracer-go/pkg/constants"
+	"go.opentelemetry.io/otel/trace"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// ErrNoTraceContext is returned by TraceContextFromObject when the object does not carry a trace.
+var ErrNoTraceContext = errors.New("no trace context found on object")
+
+// TraceContextFromObject extracts the trace.SpanContext propagated on obj, for code paths outside of
+// the TracingClient such as webhooks, CLIs or custom informers.  As in the TracingClient, the TraceID
+// and SpanID status conditions, or the field registered with RegisterTraceContextField, take precedence
+// over the annotations; pass a nil scheme to only read the annotations.  ErrNoTraceContext is returned if obj does not carry a trace.
+func TraceContextFromObject(obj client.Object, scheme *runtime.Scheme) (trace.SpanContext, error) {
+	return traceContextFromObject(obj, scheme, defaultAnnotationKeys)
+}
+
+// traceContextFromObject is TraceContextFromObject reading the annotations keys
+func traceContextFromObject(obj client.Object, scheme *runtime.Scheme, keys *annotationKeys) (trace.SpanContext, error) {
+	if scheme != nil {
+		if fields, ok := traceContextField(obj, scheme); ok {
+			if traceID, spanID, err := getTraceContextField(obj, fields); err == nil {
+				return spanContextFromHex(traceID, spanID)
+			}
+		} else if traceID, spanID, ok := getTraceConditions(obj, scheme); ok {
+			return spanContextFromHex(traceID, spanID)
+		}
+	}
+
+	traceID, spanID, ok := traceIDs(obj, keys)
+	if !ok {
+		return trace.SpanContext{}, ErrNoTraceContext
+	}
+	return spanContextFromHex(traceID, spanID)
+}
+
+// SpanContextFromObject returns the remote trace.SpanContext propagated on obj, read from the trace ID and span ID
+// annotations or else from the annotations of the globally configured propagator.  Pass WithConditions to have
+// the status conditions storing the trace take precedence over the annotations, as in the TracingClient, and
+// WithTraceAnnotations to read other annotations.  The returned span context is invalid if obj does not carry a
+// trace.
+func SpanContextFromObject(obj client.Object, opts ...PropagateOption) trace.SpanContext {
+	options := propagateOptions{}
+	for _, opt := range opts {
+		opt(&options)
+	}
+
+	if spanContext, err := traceContextFromObject(obj, options.conditionsScheme, options.annotationKeys()); err == nil {
+		return spanContext
+	}
+	return trace.SpanContextFromContext(ExtractFromObject(context.Background(), obj))
+}
+
+// ContextFromObject returns a copy of ctx joining the trace propagated on obj, so that spans started from it by
+// webhooks, CLI tools or event handlers are children of the last operation on obj.  Baggage written by the
+// propagator is extracted as well.  ctx is returned with only the baggage if obj does not carry a trace.
+func ContextFromObject(ctx context.Context, obj client.Object, opts ...PropagateOption) context.Context {
+	ctx = ExtractFromObject(ctx, obj)
+	if spanContext := SpanContextFromObject(obj, opts...); spanContext.IsValid() {
+		ctx = trace.ContextWithRemoteSpanContext(ctx, spanContext)
+	}
+	return ctx
+}
+
+// traceIDs returns the trace and span IDs stored on obj, in the annotations or else in the labels written by
+// WithLabelPropagation, and whether obj carries a trace ID at all
+func traceIDs(obj client.Object, keys *annotationKeys) (string, string, bool) {
+	if traceID, ok := obj.GetAnnotations()[keys.traceID]; ok {
+		return traceID, obj.GetAnnotations()[keys.spanID], true
+	}
+	traceID, ok := obj.GetLabels()[keys.traceID]
+	return traceID, obj.GetLabels()[keys.spanID], ok
+}
+
+// InjectSpanContext writes the trace ID and span ID of spanContext as annotations on obj, so that
+// the TracingClient of the controller reconciling obj continues the trace.
+func InjectSpanContext(spanContext trace.SpanContext, obj client.Object) error {
+	return injectSpanContext(spanContext, obj, defaultAnnotationKeys)
+}
+
+// injectSpanContext is InjectSpanContext writing the annotations keys
+func injectSpanContext(spanContext trace.SpanContext, obj client.Object, keys *annotationKeys) error {
+	if !spanContext.IsValid() {
+		return fmt.Errorf("invalid span context for %s", obj.GetName())
+	}
+
+	if obj.GetAnnotations() == nil {
+		obj.SetAnnotations(map[string]string{})
+	}
+	annotations := obj.GetAnnotations()
+	annotations[keys.traceID] = spanContext.TraceID().String()
+	annotations[keys.spanID] = spanContext.SpanID().String()
+	obj.SetAnnotations(annotations)
+	stampTraceTime(obj, keys)
+	return nil
+}
+
+// injectSpanContextLabels writes the trace ID and span ID of spanContext as labels on obj under the keys of the
+// annotations.  The lowercase hex IDs are valid label values as they are.
+func injectSpanContextLabels(spanContext trace.SpanContext, obj client.Object, keys *annotationKeys) {
+	if obj.GetLabels() == nil {
+		obj.SetLabels(map[string]string{})
+	}
+	labels := obj.GetLabels()
+	labels[keys.traceID] = spanContext.TraceID().String()
+	labels[keys.spanID] = spanContext.SpanID().String()
+	obj.SetLabels(labels)
+}
+
+// spanContextFromHex builds a remote trace.SpanContext from hex encoded trace and span IDs
+func spanContextFromHex(traceID, spanID string) (trace.SpanContext, error) {
+	traceIDValue, err := trace.TraceIDFromHex(traceID)
+	if err != nil {
+		return trace.SpanContext{}, fmt.Errorf("invalid trace ID %q: %w", traceID, err)
+	}
+	spanIDValue, err := trace.SpanIDFromHex(spanID)
+	if err != nil {
+		return trace.SpanContext{}, fmt.Errorf("invalid span ID %q: %w", spanID, err)
+	}
+
+	return trace.NewSpanContext(trace.SpanContextConfig{
+		TraceID: traceIDValue,
+		SpanID:  spanIDValue,
+		Remote:  true,
+	}), nil
+}
+
+// MatchingTraceLabel selects the objects labeled with traceID by a TracingClient given WithTraceLabel
+func MatchingTraceLabel(traceID string) client.MatchingLabels {
+	return client.MatchingLabels{constants.TraceLabel: traceID}
+}
+
+// PropagateOption configures PropagateTrace
+type PropagateOption func(*propagateOptions)
+
+type propagateOptions struct {
+	// conditionsScheme if set is used to copy the trace conditions
+	conditionsScheme *runtime.Scheme
+
+	// keys if set are the annotations the trace is read from and written to
+	keys *annotationKeys
+}
+
+// annotationKeys returns the annotations the trace is read from and written to
+func (o *propagateOptions) annotationKeys() *annotationKeys {
+	if o.keys == nil {
+		return defaultAnnotationKeys
+	}
+	return o.keys
+}
+
+// WithConditions makes PropagateTrace also copy the status conditions storing the trace,
+// using scheme to access the conditions of both objects.
+func WithConditions(scheme *runtime.Scheme) PropagateOption {
+	return func(o *propagateOptions) {
+		o.conditionsScheme = scheme
+	}
+}
+
+// WithTraceAnnotations makes SpanContextFromObject, ContextFromObject and PropagateTrace use the traceIDKey and
+// spanIDKey annotations instead of kubetracer.io/trace-id and kubetracer.io/span-id, for TracingClients
+// configured with WithAnnotationPrefix, WithTraceIDAnnotation or WithSpanIDAnnotation.
+func WithTraceAnnotations(traceIDKey, spanIDKey string) PropagateOption {
+	return func(o *propagateOptions) {
+		keys := *defaultAnnotationKeys
+		keys.traceID = traceIDKey
+		keys.spanID = spanIDKey
+		o.keys = &keys
+	}
+}
+
+// PropagateTrace copies the trace ID and span ID annotations of from to to, in memory.  It is meant
+// for children constructed outside of the TracingClient that should explicitly join the trace of
+// their parent.  An error is returned, and to is left untouched, if from does not carry a valid trace.
+func PropagateTrace(from, to client.Object, opts ...PropagateOption) error {
+	options := propagateOptions{}
+	for _, opt := range opts {
+		opt(&options)
+	}
+
+	keys := options.annotationKeys()
+	traceID := from.GetAnnotations()[keys.traceID]
+	if _, err := trace.TraceIDFromHex(traceID); err != nil {
+		return fmt.Errorf("invalid trace ID %q on %s: %w", traceID, from.GetName(), err)
+	}
+	spanID := from.GetAnnotations()[keys.spanID]
+	if _, err := trace.SpanIDFromHex(spanID); err != nil {
+		return fmt.Errorf("invalid span ID %q on %s: %w", spanID, from.GetName(), err)
+	}
+
+	if to.GetAnnotations() == nil {
+		to.SetAnnotations(map[string]string{})
+	}
+	annotations := to.GetAnnotations()
+	annotations[keys.traceID] = traceID
+	annotations[keys.spanID] = spanID
+	to.SetAnnotations(annotations)
+
+	if options.conditionsScheme == nil {
+		return nil
+	}
+	return copyTraceConditions(from, to, options.conditionsScheme)
+}
@@ -0,0 +1,213 @@
+package client
+
+import (
+	"context"
+	"testing"
+
+	"github.com/kubetracer/kubetracer-go/pkg/constants"
+	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/baggage"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+func TestPropagateTrace(t *testing.T) {
+	// Create a scheme
+	scheme := runtime.NewScheme()
+	corev1.AddToScheme(scheme)
+
+	parent := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "parent-pod",
+			Namespace: "default",
+			Annotations: map[string]string{
+				constants.TraceIDAnnotation: "f620f5cad0af940c294f980c5366a6a1",
+				constants.SpanIDAnnotation:  "45f359cdc1c8ab06",
+			},
+		},
+		Status: corev1.PodStatus{
+			Conditions: []corev1.PodCondition{
+				{
+					Type:    "TraceID",
+					Status:  corev1.ConditionUnknown,
+					Message: "f620f5cad0af940c294f980c5366a6a1",
+				},
+			},
+		},
+	}
+
+	t.Run("annotations", func(t *testing.T) {
+		child := &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "child-pod", Namespace: "default"}}
+
+		err := PropagateTrace(parent, child)
+		assert.NoError(t, err)
+		assert.Equal(t, "f620f5cad0af940c294f980c5366a6a1", child.Annotations[constants.TraceIDAnnotation])
+		assert.Equal(t, "45f359cdc1c8ab06", child.Annotations[constants.SpanIDAnnotation])
+		assert.Empty(t, child.Status.Conditions)
+	})
+
+	t.Run("conditions", func(t *testing.T) {
+		child := &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "child-pod", Namespace: "default"}}
+
+		err := PropagateTrace(parent, child, WithConditions(scheme))
+		assert.NoError(t, err)
+		message, err := getConditionMessage("TraceID", child, scheme)
+		assert.NoError(t, err)
+		assert.Equal(t, "f620f5cad0af940c294f980c5366a6a1", message)
+	})
+
+	t.Run("custom annotations", func(t *testing.T) {
+		custom := &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "parent-pod", Annotations: map[string]string{
+			"example.com/trace-id": "f620f5cad0af940c294f980c5366a6a1",
+			"example.com/span-id":  "45f359cdc1c8ab06",
+		}}}
+		child := &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "child-pod", Namespace: "default"}}
+
+		err := PropagateTrace(custom, child, WithTraceAnnotations("example.com/trace-id", "example.com/span-id"))
+		assert.NoError(t, err)
+		assert.Equal(t, "f620f5cad0af940c294f980c5366a6a1", child.Annotations["example.com/trace-id"])
+		assert.Equal(t, "45f359cdc1c8ab06", child.Annotations["example.com/span-id"])
+		assert.NotContains(t, child.Annotations, constants.TraceIDAnnotation)
+	})
+
+	t.Run("invalid trace", func(t *testing.T) {
+		invalidParent := &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: "parent-pod",
+				Annotations: map[string]string{
+					constants.TraceIDAnnotation: "1234",
+					constants.SpanIDAnnotation:  "5678",
+				},
+			},
+		}
+		child := &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "child-pod", Namespace: "default"}}
+
+		err := PropagateTrace(invalidParent, child)
+		assert.Error(t, err)
+		assert.Empty(t, child.Annotations)
+	})
+}
+
+func TestTraceContextFromObject(t *testing.T) {
+	// Create a scheme
+	scheme := runtime.NewScheme()
+	corev1.AddToScheme(scheme)
+
+	t.Run("annotations", func(t *testing.T) {
+		pod := &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: "test-pod",
+				Annotations: map[string]string{
+					constants.TraceIDAnnotation: "f620f5cad0af940c294f980c5366a6a1",
+					constants.SpanIDAnnotation:  "45f359cdc1c8ab06",
+				},
+			},
+		}
+
+		spanContext, err := TraceContextFromObject(pod, scheme)
+		assert.NoError(t, err)
+		assert.True(t, spanContext.IsRemote())
+		assert.Equal(t, "f620f5cad0af940c294f980c5366a6a1", spanContext.TraceID().String())
+		assert.Equal(t, "45f359cdc1c8ab06", spanContext.SpanID().String())
+	})
+
+	t.Run("conditions take precedence", func(t *testing.T) {
+		pod := &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: "test-pod",
+				Annotations: map[string]string{
+					constants.TraceIDAnnotation: "f620f5cad0af940c294f980c5366a6a1",
+					constants.SpanIDAnnotation:  "45f359cdc1c8ab06",
+				},
+			},
+			Status: corev1.PodStatus{
+				Conditions: []corev1.PodCondition{
+					{Type: "TraceID", Message: "0af7651916cd43dd8448eb211c80319c"},
+					{Type: "SpanID", Message: "b7ad6b7169203331"},
+				},
+			},
+		}
+
+		spanContext, err := TraceContextFromObject(pod, scheme)
+		assert.NoError(t, err)
+		assert.Equal(t, "0af7651916cd43dd8448eb211c80319c", spanContext.TraceID().String())
+
+		spanContext, err = TraceContextFromObject(pod, nil)
+		assert.NoError(t, err)
+		assert.Equal(t, "f620f5cad0af940c294f980c5366a6a1", spanContext.TraceID().String())
+	})
+
+	t.Run("no trace", func(t *testing.T) {
+		_, err := TraceContextFromObject(&corev1.Pod{}, scheme)
+		assert.ErrorIs(t, err, ErrNoTraceContext)
+	})
+}
+
+func TestInjectSpanContext(t *testing.T) {
+	traceID, _ := trace.TraceIDFromHex("f620f5cad0af940c294f980c5366a6a1")
+	spanID, _ := trace.SpanIDFromHex("45f359cdc1c8ab06")
+	spanContext := trace.NewSpanContext(trace.SpanContextConfig{TraceID: traceID, SpanID: spanID})
+
+	pod := &corev1.Pod{}
+	err := InjectSpanContext(spanContext, pod)
+	assert.NoError(t, err)
+	assert.Equal(t, "f620f5cad0af940c294f980c5366a6a1", pod.Annotations[constants.TraceIDAnnotation])
+	assert.Equal(t, "45f359cdc1c8ab06", pod.Annotations[constants.SpanIDAnnotation])
+
+	err = InjectSpanContext(trace.SpanContext{}, &corev1.Pod{})
+	assert.Error(t, err)
+}
+
+func TestContextFromObject(t *testing.T) {
+	previous := otel.GetTextMapPropagator()
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{}))
+	t.Cleanup(func() { otel.SetTextMapPropagator(previous) })
+
+	scheme := runtime.NewScheme()
+	corev1.AddToScheme(scheme)
+
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "test-pod",
+			Annotations: map[string]string{
+				constants.TraceIDAnnotation:  "f620f5cad0af940c294f980c5366a6a1",
+				constants.SpanIDAnnotation:   "45f359cdc1c8ab06",
+				"otel.kubetracer.io/baggage": "tenant=blue",
+			},
+		},
+		Status: corev1.PodStatus{
+			Conditions: []corev1.PodCondition{
+				{Type: "TraceID", Message: "0af7651916cd43dd8448eb211c80319c"},
+				{Type: "SpanID", Message: "b7ad6b7169203331"},
+			},
+		},
+	}
+
+	spanContext := SpanContextFromObject(pod)
+	assert.True(t, spanContext.IsRemote())
+	assert.Equal(t, "f620f5cad0af940c294f980c5366a6a1", spanContext.TraceID().String())
+	assert.Equal(t, "0af7651916cd43dd8448eb211c80319c", SpanContextFromObject(pod, WithConditions(scheme)).TraceID().String())
+
+	ctx := ContextFromObject(context.Background(), pod)
+	assert.Equal(t, "45f359cdc1c8ab06", trace.SpanContextFromContext(ctx).SpanID().String())
+	assert.Equal(t, "blue", baggage.FromContext(ctx).Member("tenant").Value())
+
+	w3cPod := &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Annotations: map[string]string{
+		"otel.kubetracer.io/traceparent": "00-0af7651916cd43dd8448eb211c80319c-b7ad6b7169203331-01",
+	}}}
+	assert.Equal(t, "b7ad6b7169203331", SpanContextFromObject(w3cPod).SpanID().String())
+
+	customPod := &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Annotations: map[string]string{
+		"example.com/trace-id": "f620f5cad0af940c294f980c5366a6a1",
+		"example.com/span-id":  "45f359cdc1c8ab06",
+	}}}
+	assert.False(t, SpanContextFromObject(customPod).IsValid())
+	assert.Equal(t, "45f359cdc1c8ab06", SpanContextFromObject(customPod, WithTraceAnnotations("example.com/trace-id", "example.com/span-id")).SpanID().String())
+
+	assert.False(t, SpanContextFromObject(&corev1.Pod{}).IsValid())
+	assert.False(t, trace.SpanContextFromContext(ContextFromObject(context.Background(), &corev1.Pod{})).IsValid())
+}
@@ -0,0 +1,42 @@
+package client
+
+import (
+	"github.com/go-logr/logr"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// ReadSourceKey is the span attribute telling whether a Get or List was served by the cache or the API server
+const ReadSourceKey = attribute.Key("kubetracer.read_source")
+
+const (
+	// ReadSourceCache is the ReadSourceKey of reads served by the cached client of the manager
+	ReadSourceCache = "cache"
+	// ReadSourceAPI is the ReadSourceKey of reads served by the API server
+	ReadSourceAPI = "api"
+)
+
+// NewTracingClientWithAPIReader wraps the cached client c of the manager like NewTracingClient, and keeps the
+// uncached apiReader, e.g. mgr.GetAPIReader(), for the Gets and Lists given WithLiveRead.  The span of every
+// Get and List records the path taken in the kubetracer.read_source attribute.
+func NewTracingClientWithAPIReader(c client.Client, apiReader client.Reader, t trace.Tracer, l logr.Logger, scheme ...*runtime.Scheme) TracingClient {
+	tc := NewTracingClient(c, c, t, l, scheme...).(*tracingClient)
+	tc.apiReader = apiReader
+	return tc
+}
+
+// reader returns the reader of a Get or List and records its source on the span.  Without an API reader the
+// source of the Client is unknown and no attribute is recorded.
+func (tc *tracingClient) reader(span trace.Span, options readOptions) client.Reader {
+	if tc.apiReader == nil {
+		return tc.Client
+	}
+	if options.live {
+		span.SetAttributes(ReadSourceKey.String(ReadSourceAPI))
+		return tc.apiReader
+	}
+	span.SetAttributes(ReadSourceKey.String(ReadSourceCache))
+	return tc.Client
+}
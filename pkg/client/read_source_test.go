@@ -0,0 +1,40 @@
+package client
+
+import (
+	"context"
+	"testing"
+
+	"github.com/go-logr/logr"
+	"github.com/stretchr/testify/assert"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestNewTracingClientWithAPIReader(t *testing.T) {
+	pod := &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "test-pod", Namespace: "default"}}
+	// the cache has not observed the pod yet
+	cache := fake.NewClientBuilder().Build()
+	apiReader := fake.NewClientBuilder().WithObjects(pod).Build()
+	recorder := tracetest.NewSpanRecorder()
+	tracer := sdktrace.NewTracerProvider(sdktrace.WithSampler(sdktrace.AlwaysSample()), sdktrace.WithSpanProcessor(recorder)).Tracer("kubetracer")
+	tracingClient := NewTracingClientWithAPIReader(cache, apiReader, tracer, logr.Discard())
+
+	ctx := context.Background()
+	key := client.ObjectKeyFromObject(pod)
+	assert.Error(t, tracingClient.Get(ctx, key, &corev1.Pod{}))
+	assert.NoError(t, tracingClient.Get(ctx, key, &corev1.Pod{}, WithLiveRead()))
+
+	pods := &corev1.PodList{}
+	assert.NoError(t, tracingClient.List(ctx, pods, WithLiveRead(), client.InNamespace("default")))
+	assert.Len(t, pods.Items, 1)
+
+	spans := recorder.Ended()
+	assert.Len(t, spans, 3)
+	assert.Contains(t, spans[0].Attributes(), ReadSourceKey.String(ReadSourceCache))
+	assert.Contains(t, spans[1].Attributes(), ReadSourceKey.String(ReadSourceAPI))
+	assert.Contains(t, spans[2].Attributes(), ReadSourceKey.String(ReadSourceAPI))
+}
@@ -0,0 +1,106 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"go.opentelemetry.io/otel/trace"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+// requeueTraceGrace is how long after the requeue delay a registered trace is kept for the follow-up reconcile,
+// which may be delayed by the rate limiter or by other objects in the queue
+const requeueTraceGrace = 5 * time.Minute
+
+// objectIdentity identifies an object across calls, by type, namespace and name
+type objectIdentity struct {
+	objectType string
+	namespace  string
+	name       string
+}
+
+// identityOf returns the objectIdentity of obj.  The type meta of typed objects is not reliably set, it is
+// only used to tell unstructured and metadata-only objects apart.
+func identityOf(obj client.Object) objectIdentity {
+	objectType := fmt.Sprintf("%T", obj)
+	switch obj.(type) {
+	case runtime.Unstructured, *metav1.PartialObjectMetadata:
+		objectType = obj.GetObjectKind().GroupVersionKind().String()
+	}
+	return objectIdentity{
+		objectType: objectType,
+		namespace:  obj.GetNamespace(),
+		name:       obj.GetName(),
+	}
+}
+
+// requeuedTrace is the trace registered for the follow-up reconcile of an object
+type requeuedTrace struct {
+	spanContext trace.SpanContext
+	expires     time.Time
+}
+
+// requeueRegistry holds the traces registered by RequeueWithTrace and RequeueAfterWithTrace in this process
+var requeueRegistry = struct {
+	sync.Mutex
+	traces map[objectIdentity]requeuedTrace
+}{traces: map[objectIdentity]requeuedTrace{}}
+
+// RequeueWithTrace returns a Result requeueing obj, whose follow-up reconcile continues the trace in ctx
+// instead of starting a fresh root.  The trace is kept in process, StartTrace picks it up unless the
+// request embeds a trace.
+func RequeueWithTrace(ctx context.Context, obj client.Object) reconcile.Result {
+	registerRequeueTrace(ctx, obj, 0)
+	return reconcile.Result{Requeue: true}
+}
+
+// RequeueAfterWithTrace returns a Result requeueing obj after the given duration, whose follow-up reconcile
+// continues the trace in ctx instead of starting a fresh root.
+func RequeueAfterWithTrace(ctx context.Context, obj client.Object, after time.Duration) reconcile.Result {
+	registerRequeueTrace(ctx, obj, after)
+	return reconcile.Result{RequeueAfter: after}
+}
+
+// registerRequeueTrace keeps the trace in ctx for the follow-up reconcile of obj
+func registerRequeueTrace(ctx context.Context, obj client.Object, after time.Duration) {
+	spanContext := trace.SpanContextFromContext(ctx)
+	if !spanContext.IsValid() {
+		return
+	}
+
+	requeueRegistry.Lock()
+	defer requeueRegistry.Unlock()
+
+	now := time.Now()
+	for identity, requeued := range requeueRegistry.traces {
+		if now.After(requeued.expires) {
+			delete(requeueRegistry.traces, identity)
+		}
+	}
+	requeueRegistry.traces[identityOf(obj)] = requeuedTrace{
+		spanContext: spanContext,
+		expires:     now.Add(after + requeueTraceGrace),
+	}
+}
+
+// takeRequeueTrace returns and forgets the trace registered for the follow-up reconcile of obj, if any
+func takeRequeueTrace(obj client.Object) (trace.SpanContext, bool) {
+	requeueRegistry.Lock()
+	defer requeueRegistry.Unlock()
+
+	identity := identityOf(obj)
+	requeued, ok := requeueRegistry.traces[identity]
+	if !ok {
+		return trace.SpanContext{}, false
+	}
+	delete(requeueRegistry.traces, identity)
+	if time.Now().After(requeued.expires) {
+		return trace.SpanContext{}, false
+	}
+	return requeued.spanContext, true
+}
@@ -0,0 +1,49 @@
+package client
+
+import (
+	"context"
+
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// traceRootKey is the context key holding the traceRoot of the current trace
+type traceRootKey struct{}
+
+// traceRoot is the resource whose StartTrace began the trace
+type traceRoot struct {
+	kind string
+	name string
+}
+
+// contextWithTraceRoot stores the resource that originated the trace in ctx
+func contextWithTraceRoot(ctx context.Context, kind, name string) context.Context {
+	return context.WithValue(ctx, traceRootKey{}, traceRoot{kind: kind, name: name})
+}
+
+// contextWithTraceRootFromObject carries the root annotations of obj, if any, over to ctx so that
+// the objects written while reconciling obj keep pointing at the same root
+func contextWithTraceRootFromObject(ctx context.Context, obj client.Object, keys *annotationKeys) context.Context {
+	annotations := obj.GetAnnotations()
+	if annotations[keys.traceRoot] != "true" {
+		return ctx
+	}
+	return contextWithTraceRoot(ctx, annotations[keys.traceRootKind], annotations[keys.traceRootName])
+}
+
+// addTraceRootAnnotations marks obj with the kind and name of the resource that originated the trace
+// in ctx, so that any object of the chain can tell where it started without walking the graph
+func addTraceRootAnnotations(ctx context.Context, obj client.Object, keys *annotationKeys) {
+	root, ok := ctx.Value(traceRootKey{}).(traceRoot)
+	if !ok {
+		return
+	}
+
+	if obj.GetAnnotations() == nil {
+		obj.SetAnnotations(map[string]string{})
+	}
+	annotations := obj.GetAnnotations()
+	annotations[keys.traceRoot] = "true"
+	annotations[keys.traceRootKind] = root.kind
+	annotations[keys.traceRootName] = root.name
+	obj.SetAnnotations(annotations)
+}
@@ -0,0 +1,31 @@
+package client
+
+import (
+	"strings"
+
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/apiutil"
+)
+
+// SamplerFunc decides whether an operation is traced, before its span is started.  verb is the operation as
+// passed to the OnSpanEndFunc, e.g. "status-update", gvk the kind of the object, or of the items of a list, and
+// key the object, with only the namespace for List and DeleteAllOf.
+type SamplerFunc func(verb string, gvk schema.GroupVersionKind, key client.ObjectKey) bool
+
+// sample reports whether the operation verb on obj at key is traced.  A nil SamplerFunc traces all operations,
+// as are those on objects of unknown kind.
+func (s SamplerFunc) sample(scheme *runtime.Scheme, verb string, obj runtime.Object, key client.ObjectKey) bool {
+	if s == nil {
+		return true
+	}
+	gvk, err := apiutil.GVKForObject(obj, scheme)
+	if err != nil {
+		return true
+	}
+	if _, ok := obj.(client.ObjectList); ok {
+		gvk.Kind = strings.TrimSuffix(gvk.Kind, "List")
+	}
+	return s(verb, gvk, key)
+}
@@ -0,0 +1,51 @@
+package client
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestWithSampler(t *testing.T) {
+	pod := &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "test-pod", Namespace: "default"}}
+	k8sClient := fake.NewClientBuilder().WithObjects(pod).Build()
+	recorder := tracetest.NewSpanRecorder()
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSampler(sdktrace.AlwaysSample()), sdktrace.WithSpanProcessor(recorder))
+
+	type sampled struct {
+		verb string
+		gvk  schema.GroupVersionKind
+		key  client.ObjectKey
+	}
+	var calls []sampled
+	tracingClient := NewTracingClientWithOptions(k8sClient, WithTracerProvider(tp), WithSampler(func(verb string, gvk schema.GroupVersionKind, key client.ObjectKey) bool {
+		calls = append(calls, sampled{verb, gvk, key})
+		return verb != "status-update" && verb != "list"
+	}))
+
+	ctx := context.Background()
+	assert.NoError(t, tracingClient.Status().Update(ctx, pod))
+	assert.NoError(t, tracingClient.List(ctx, &corev1.PodList{}, client.InNamespace("default")))
+	assert.NoError(t, tracingClient.Delete(ctx, pod))
+
+	podGVK := corev1.SchemeGroupVersion.WithKind("Pod")
+	assert.Equal(t, []sampled{
+		{"status-update", podGVK, client.ObjectKeyFromObject(pod)},
+		{"list", podGVK, client.ObjectKey{Namespace: "default"}},
+		{"delete", podGVK, client.ObjectKeyFromObject(pod)},
+	}, calls)
+
+	// the status update sampled out did not write the trace either
+	assert.Empty(t, pod.Status.Conditions)
+	spans := recorder.Ended()
+	assert.Len(t, spans, 1)
+	assert.Equal(t, "Delete Pod test-pod", spans[0].Name())
+}
@@ -0,0 +1,110 @@
+package client
+
+import (
+	"errors"
+	"fmt"
+
+	"go.opentelemetry.io/otel/attribute"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// The attributes set on the span of every client operation, following the OpenTelemetry semantic conventions
+// for Kubernetes where they exist, so backends can filter and group the spans by object
+const (
+	// K8sNamespaceNameKey is the namespace of the object
+	K8sNamespaceNameKey = attribute.Key("k8s.namespace.name")
+	// K8sObjectNameKey is the name of the object
+	K8sObjectNameKey = attribute.Key("k8s.object.name")
+	// GenerateNameKey is the generateName of an object created without a name
+	GenerateNameKey = attribute.Key("kubetracer.generate_name")
+	// K8sObjectKindKey is the kind of the object
+	K8sObjectKindKey = attribute.Key("k8s.object.kind")
+	// K8sObjectUIDKey is the UID of the object
+	K8sObjectUIDKey = attribute.Key("k8s.object.uid")
+	// K8sObjectResourceVersionKey is the resourceVersion of the object once the operation completed
+	K8sObjectResourceVersionKey = attribute.Key("k8s.object.resource_version")
+	// DryRunKey is set to true on the span of a write made with client.DryRunAll
+	DryRunKey = attribute.Key("k8s.dry_run")
+	// ErrorTypeKey is the type of the error of a failed operation, the reason of Kubernetes API errors, e.g.
+	// "NotFound", or the Go type of other errors
+	ErrorTypeKey = attribute.Key("error.type")
+	// HTTPStatusCodeKey is the HTTP status code of a Kubernetes API error
+	HTTPStatusCodeKey = attribute.Key("http.response.status_code")
+	// APIErrorReasonKey is the reason of a Kubernetes API error, e.g. "Conflict", "NotFound" or "Forbidden"
+	APIErrorReasonKey = attribute.Key("kubetracer.api_error.reason")
+	// APIErrorRetryAfterKey is the delay in seconds the API server asked the client to wait before retrying
+	APIErrorRetryAfterKey = attribute.Key("kubetracer.api_error.retry_after")
+	// LabelSelectorKey is the label selector of a DeleteAllOf
+	LabelSelectorKey = attribute.Key("kubetracer.label_selector")
+	// FieldSelectorKey is the field selector of a DeleteAllOf
+	FieldSelectorKey = attribute.Key("kubetracer.field_selector")
+	// AffectedCountKey is the number of objects matched by a DeleteAllOf given WithAffectedCount
+	AffectedCountKey = attribute.Key("kubetracer.affected_count")
+	// OperationKey is the verb of the operation, e.g. "update", as passed to the OnSpanEndFunc, or "starttrace"
+	OperationKey = attribute.Key("kubetracer.operation")
+)
+
+// objectAttributes returns the attributes describing the operation verb on obj of the given kind.  Empty
+// values, e.g. the UID of an object not created yet, are left out.
+func objectAttributes(verb, kind string, obj client.Object) []attribute.KeyValue {
+	attrs := []attribute.KeyValue{OperationKey.String(verb)}
+	if kind != "" {
+		attrs = append(attrs, K8sObjectKindKey.String(kind))
+	}
+	if obj == nil {
+		return attrs
+	}
+
+	for key, value := range map[attribute.Key]string{
+		K8sNamespaceNameKey:         obj.GetNamespace(),
+		K8sObjectNameKey:            obj.GetName(),
+		K8sObjectUIDKey:             string(obj.GetUID()),
+		K8sObjectResourceVersionKey: obj.GetResourceVersion(),
+	} {
+		if value != "" {
+			attrs = append(attrs, key.String(value))
+		}
+	}
+	return attrs
+}
+
+// keyAttributes returns the attributes describing the object at key, for reads which may not find it
+func keyAttributes(key client.ObjectKey) []attribute.KeyValue {
+	attrs := []attribute.KeyValue{K8sObjectNameKey.String(key.Name)}
+	if key.Namespace != "" {
+		attrs = append(attrs, K8sNamespaceNameKey.String(key.Namespace))
+	}
+	return attrs
+}
+
+// errorType returns the value of the ErrorTypeKey for err
+func errorType(err error) string {
+	if reason := apierrors.ReasonForError(err); reason != metav1.StatusReasonUnknown {
+		return string(reason)
+	}
+	return fmt.Sprintf("%T", err)
+}
+
+// apiErrorAttributes returns the attributes describing err if it is a Kubernetes API error, e.g. an
+// apierrors.StatusError
+func apiErrorAttributes(err error) []attribute.KeyValue {
+	var apiStatus apierrors.APIStatus
+	if !errors.As(err, &apiStatus) {
+		return nil
+	}
+
+	status := apiStatus.Status()
+	var attrs []attribute.KeyValue
+	if status.Reason != "" {
+		attrs = append(attrs, APIErrorReasonKey.String(string(status.Reason)))
+	}
+	if status.Code != 0 {
+		attrs = append(attrs, HTTPStatusCodeKey.Int(int(status.Code)))
+	}
+	if seconds, ok := apierrors.SuggestsClientDelay(err); ok {
+		attrs = append(attrs, APIErrorRetryAfterKey.Int(seconds))
+	}
+	return attrs
+}
@@ -0,0 +1,97 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/go-logr/logr"
+	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/otel/attribute"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestObjectAttributes(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	tracer := sdktrace.NewTracerProvider(sdktrace.WithSampler(sdktrace.AlwaysSample()), sdktrace.WithSpanProcessor(recorder)).Tracer("kubetracer")
+	k8sClient := fake.NewClientBuilder().Build()
+	tracingClient := NewTracingClient(k8sClient, k8sClient, tracer, logr.Discard())
+
+	ctx := context.Background()
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "test-pod",
+			Namespace: "default",
+			UID:       "1234",
+		},
+	}
+	assert.NoError(t, tracingClient.Create(ctx, pod))
+
+	missing := &corev1.Pod{}
+	assert.Error(t, tracingClient.Get(ctx, client.ObjectKey{Name: "missing-pod", Namespace: "default"}, missing))
+
+	spans := recorder.Ended()
+	assert.Len(t, spans, 2)
+
+	assert.Subset(t, spans[0].Attributes(), []attribute.KeyValue{
+		OperationKey.String("create"),
+		K8sObjectKindKey.String("Pod"),
+		K8sNamespaceNameKey.String("default"),
+		K8sObjectNameKey.String("test-pod"),
+		K8sObjectUIDKey.String("1234"),
+		K8sObjectResourceVersionKey.String(pod.ResourceVersion),
+	})
+	assert.NotEmpty(t, pod.ResourceVersion)
+
+	assert.Subset(t, spans[1].Attributes(), []attribute.KeyValue{
+		OperationKey.String("get"),
+		K8sObjectKindKey.String("Pod"),
+		K8sNamespaceNameKey.String("default"),
+		K8sObjectNameKey.String("missing-pod"),
+	})
+	for _, attr := range spans[1].Attributes() {
+		assert.NotEqual(t, K8sObjectUIDKey, attr.Key)
+	}
+}
+
+func TestAPIErrorAttributes(t *testing.T) {
+	podsResource := schema.GroupResource{Resource: "pods"}
+
+	assert.ElementsMatch(t, []attribute.KeyValue{
+		APIErrorReasonKey.String("Conflict"),
+		HTTPStatusCodeKey.Int(409),
+	}, apiErrorAttributes(apierrors.NewConflict(podsResource, "test-pod", errors.New("the object has been modified"))))
+
+	assert.ElementsMatch(t, []attribute.KeyValue{
+		APIErrorReasonKey.String("TooManyRequests"),
+		HTTPStatusCodeKey.Int(429),
+		APIErrorRetryAfterKey.Int(5),
+	}, apiErrorAttributes(fmt.Errorf("problem listing pods: %w", apierrors.NewTooManyRequests("slow down", 5))))
+
+	assert.Empty(t, apiErrorAttributes(errors.New("connection refused")))
+}
+
+func TestAPIErrorAttributesOnSpan(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	tracer := sdktrace.NewTracerProvider(sdktrace.WithSampler(sdktrace.AlwaysSample()), sdktrace.WithSpanProcessor(recorder)).Tracer("kubetracer")
+	k8sClient := fake.NewClientBuilder().Build()
+	tracingClient := NewTracingClient(k8sClient, k8sClient, tracer, logr.Discard())
+
+	err := tracingClient.Get(context.Background(), client.ObjectKey{Name: "missing-pod", Namespace: "default"}, &corev1.Pod{})
+	assert.True(t, apierrors.IsNotFound(err))
+
+	spans := recorder.Ended()
+	assert.Len(t, spans, 1)
+	assert.Subset(t, spans[0].Attributes(), []attribute.KeyValue{
+		APIErrorReasonKey.String("NotFound"),
+		HTTPStatusCodeKey.Int(404),
+	})
+}
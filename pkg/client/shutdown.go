@@ -0,0 +1,59 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// DefaultShutdownTimeout bounds the flush and shutdown of the TracerProvider, it matches the default
+// graceful shutdown timeout of the manager
+const DefaultShutdownTimeout = 30 * time.Second
+
+// FlushShutdowner is implemented by the TracerProvider of the OTel SDK
+type FlushShutdowner interface {
+	ForceFlush(ctx context.Context) error
+	Shutdown(ctx context.Context) error
+}
+
+// TracerProviderShutdown is a manager Runnable that flushes and shuts down a TracerProvider when the manager
+// stops, so the spans produced during termination are exported:
+//
+//	mgr.Add(kubetracer.NewTracerProviderShutdown(tp, 0))
+//
+// It runs on every replica, not only the leader.
+type TracerProviderShutdown struct {
+	tracerProvider FlushShutdowner
+	timeout        time.Duration
+}
+
+// NewTracerProviderShutdown returns a TracerProviderShutdown for tp.  timeout bounds the flush and the
+// shutdown, DefaultShutdownTimeout is used if it is zero.
+func NewTracerProviderShutdown(tp FlushShutdowner, timeout time.Duration) *TracerProviderShutdown {
+	if timeout == 0 {
+		timeout = DefaultShutdownTimeout
+	}
+	return &TracerProviderShutdown{
+		tracerProvider: tp,
+		timeout:        timeout,
+	}
+}
+
+// Start implements manager.Runnable.  It blocks until ctx is done, then flushes and shuts down the TracerProvider.
+func (s *TracerProviderShutdown) Start(ctx context.Context) error {
+	<-ctx.Done()
+
+	// ctx is already done, the flush gets its own deadline
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), s.timeout)
+	defer cancel()
+
+	return errors.Join(
+		s.tracerProvider.ForceFlush(shutdownCtx),
+		s.tracerProvider.Shutdown(shutdownCtx),
+	)
+}
+
+// NeedLeaderElection implements manager.LeaderElectionRunnable.  Spans are produced by every replica.
+func (s *TracerProviderShutdown) NeedLeaderElection() bool {
+	return false
+}
@@ -0,0 +1,43 @@
+package client
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+)
+
+// countingExporter counts the exported spans, unlike tracetest.InMemoryExporter it keeps them on shutdown
+type countingExporter struct {
+	exported int
+}
+
+func (e *countingExporter) ExportSpans(_ context.Context, spans []sdktrace.ReadOnlySpan) error {
+	e.exported += len(spans)
+	return nil
+}
+
+func (e *countingExporter) Shutdown(context.Context) error {
+	return nil
+}
+
+func TestTracerProviderShutdown(t *testing.T) {
+	exporter := &countingExporter{}
+	// The batcher would only export the span after its timeout
+	tp := sdktrace.NewTracerProvider(sdktrace.WithBatcher(exporter))
+
+	_, span := tp.Tracer("kubetracer").Start(context.Background(), "terminating")
+	span.End()
+	assert.Zero(t, exporter.exported)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+	shutdown := NewTracerProviderShutdown(tp, 0)
+	go func() { done <- shutdown.Start(ctx) }()
+
+	cancel()
+	assert.NoError(t, <-done)
+	assert.Equal(t, 1, exporter.exported)
+	assert.False(t, shutdown.NeedLeaderElection())
+}
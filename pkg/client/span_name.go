@@ -0,0 +1,33 @@
+package client
+
+import (
+	"fmt"
+
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// SpanNameFormatter names the span of an operation.  verb is the operation as it starts the default span names,
+// e.g. "Update", "StatusPatch", "ScaleUpdate" or "StartTrace", gvk the kind of the object, or of the items of a
+// List or Watch, and key the object, with only the namespace for List and Watch.  The "Triggered By" suffix
+// naming the object which caused the reconcile is appended to the names of writes and StartTrace.
+type SpanNameFormatter func(verb string, gvk schema.GroupVersionKind, key client.ObjectKey) string
+
+// spanName names the span of the operation verb on the object at key, e.g. "Update Deployment deploy01"
+// when f is nil
+func (f SpanNameFormatter) spanName(verb string, gvk schema.GroupVersionKind, key client.ObjectKey) string {
+	if f == nil {
+		return fmt.Sprintf("%s %s %s", verb, gvk.Kind, key.Name)
+	}
+	return f(verb, gvk, key)
+}
+
+// createKey returns the key naming the span of the Create of obj, with its generateName as name until the API
+// server assigns one, e.g. "Create Pod web-"
+func createKey(obj client.Object) client.ObjectKey {
+	key := client.ObjectKeyFromObject(obj)
+	if key.Name == "" {
+		key.Name = obj.GetGenerateName()
+	}
+	return key
+}
@@ -0,0 +1,62 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestWithSpanNameFormatter(t *testing.T) {
+	deployment := &appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{Name: "deploy01", Namespace: "default"}}
+	k8sClient := fake.NewClientBuilder().WithObjects(deployment).Build()
+	recorder := tracetest.NewSpanRecorder()
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSampler(sdktrace.AlwaysSample()), sdktrace.WithSpanProcessor(recorder))
+	tracingClient := NewTracingClientWithOptions(k8sClient, WithTracerProvider(tp), WithSpanNameFormatter(func(verb string, gvk schema.GroupVersionKind, key client.ObjectKey) string {
+		return fmt.Sprintf("%s %s %s", strings.ToLower(verb), gvk.GroupKind(), key)
+	}))
+
+	key := client.ObjectKey{Name: "0af7651916cd43dd8448eb211c80319c;b7ad6b7169203331;ConfigMap;deploy01-configs;deploy01", Namespace: "default"}
+	ctx, span, err := tracingClient.StartTrace(context.Background(), key, &appsv1.Deployment{})
+	assert.NoError(t, err)
+	assert.NoError(t, tracingClient.Update(ctx, deployment))
+	assert.NoError(t, tracingClient.List(ctx, &corev1.PodList{}, client.InNamespace("default")))
+	span.End()
+
+	var names []string
+	for _, span := range recorder.Ended() {
+		names = append(names, span.Name())
+	}
+	assert.Equal(t, []string{
+		"update Deployment.apps default/deploy01 Triggered By ConfigMap deploy01-configs",
+		"list Pod default/",
+		"starttrace Deployment.apps default/deploy01 Triggered By ConfigMap deploy01-configs",
+	}, names)
+}
+
+func TestCreateWithGenerateName(t *testing.T) {
+	k8sClient := fake.NewClientBuilder().Build()
+	recorder := tracetest.NewSpanRecorder()
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSampler(sdktrace.AlwaysSample()), sdktrace.WithSpanProcessor(recorder))
+	tracingClient := NewTracingClientWithOptions(k8sClient, WithTracerProvider(tp))
+
+	pod := &corev1.Pod{ObjectMeta: metav1.ObjectMeta{GenerateName: "web-", Namespace: "default"}}
+	assert.NoError(t, tracingClient.Create(context.Background(), pod))
+	assert.NotEmpty(t, pod.Name)
+
+	spans := recorder.Ended()
+	assert.Len(t, spans, 1)
+	assert.Equal(t, "Create Pod web-", spans[0].Name())
+	assert.Contains(t, spans[0].Attributes(), GenerateNameKey.String("web-"))
+	assert.Contains(t, spans[0].Attributes(), K8sObjectNameKey.String(pod.Name))
+}
@@ -0,0 +1,41 @@
+package client
+
+import (
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+	"golang.org/x/time/rate"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+)
+
+// WithErrorStackTraces records the stack trace with the errors of the operations, to pinpoint which call site
+// of a large reconciler produced a failing API call.  Expected errors, such as NotFound, AlreadyExists and
+// Conflict, are recorded without.  Capturing stack traces is costly, at most perSecond of them are recorded,
+// with bursts of up to burst.
+func WithErrorStackTraces(perSecond float64, burst int) Option {
+	return func(o *clientOptions) {
+		o.stackTraces = &stackTraces{limiter: rate.NewLimiter(rate.Limit(perSecond), burst)}
+	}
+}
+
+// stackTraces rate limits the stack traces recorded with errors
+type stackTraces struct {
+	limiter *rate.Limiter
+}
+
+// recordError records err on span, with the stack trace if enabled and err is not expected, and marks the span
+// as failed
+func (s *stackTraces) recordError(span trace.Span, err error) {
+	span.SetStatus(codes.Error, err.Error())
+	span.SetAttributes(ErrorTypeKey.String(errorType(err)))
+	span.SetAttributes(apiErrorAttributes(err)...)
+	if s == nil || isExpectedError(err) || !s.limiter.Allow() {
+		span.RecordError(err)
+		return
+	}
+	span.RecordError(err, trace.WithStackTrace(true))
+}
+
+// isExpectedError reports whether err is a routine outcome of a reconcile rather than a failure
+func isExpectedError(err error) bool {
+	return apierrors.IsNotFound(err) || apierrors.IsAlreadyExists(err) || apierrors.IsConflict(err)
+}
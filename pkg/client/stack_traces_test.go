@@ -0,0 +1,93 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/go-logr/logr"
+	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/client/interceptor"
+)
+
+// hasStackTrace reports whether the exception event of span carries a stack trace
+func hasStackTrace(span sdktrace.ReadOnlySpan) bool {
+	for _, event := range span.Events() {
+		for _, attr := range event.Attributes {
+			if attr.Key == "exception.stacktrace" {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func TestWithErrorStackTraces(t *testing.T) {
+	existing := &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "existing-pod", Namespace: "default"}}
+	k8sClient := fake.NewClientBuilder().WithObjects(existing).WithInterceptorFuncs(interceptor.Funcs{
+		Update: func(ctx context.Context, c client.WithWatch, obj client.Object, opts ...client.UpdateOption) error {
+			return errors.New("admission webhook denied the request")
+		},
+	}).Build()
+
+	recorder := tracetest.NewSpanRecorder()
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSampler(sdktrace.AlwaysSample()), sdktrace.WithSpanProcessor(recorder))
+	// A single stack trace is allowed
+	tracingClient := NewTracingClientWithOptions(k8sClient, WithTracerProvider(tp), WithErrorStackTraces(0, 1))
+
+	// Expected errors are recorded without stack trace
+	duplicate := &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "existing-pod", Namespace: "default"}}
+	assert.True(t, apierrors.IsAlreadyExists(tracingClient.Create(context.Background(), duplicate)))
+	// Unexpected errors are recorded with one, within the rate limit
+	assert.Error(t, tracingClient.Update(context.Background(), existing.DeepCopy()))
+	assert.Error(t, tracingClient.Update(context.Background(), existing.DeepCopy()))
+
+	spans := recorder.Ended()
+	assert.Len(t, spans, 3)
+	assert.False(t, hasStackTrace(spans[0]))
+	assert.True(t, hasStackTrace(spans[1]))
+	assert.False(t, hasStackTrace(spans[2]))
+}
+
+func TestErrorStatus(t *testing.T) {
+	existing := &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "existing-pod", Namespace: "default"}}
+	k8sClient := fake.NewClientBuilder().WithObjects(existing).WithInterceptorFuncs(interceptor.Funcs{
+		SubResourceUpdate: func(ctx context.Context, c client.Client, subResourceName string, obj client.Object, opts ...client.SubResourceUpdateOption) error {
+			return errors.New("admission webhook denied the request")
+		},
+	}).Build()
+
+	recorder := tracetest.NewSpanRecorder()
+	tracer := sdktrace.NewTracerProvider(sdktrace.WithSampler(sdktrace.AlwaysSample()), sdktrace.WithSpanProcessor(recorder)).Tracer("kubetracer")
+	tracingClient := NewTracingClient(k8sClient, k8sClient, tracer, logr.Discard())
+
+	ctx := context.Background()
+	assert.NoError(t, tracingClient.Get(ctx, client.ObjectKeyFromObject(existing), &corev1.Pod{}))
+	assert.Error(t, tracingClient.Get(ctx, client.ObjectKey{Name: "missing-pod", Namespace: "default"}, &corev1.Pod{}))
+	assert.Error(t, tracingClient.Status().Update(ctx, existing.DeepCopy()))
+	_, span, err := tracingClient.StartTrace(ctx, client.ObjectKey{Name: "missing-pod", Namespace: "default"}, &corev1.Pod{}, WithStrictErrors())
+	span.End()
+	assert.Error(t, err)
+
+	spans := recorder.Ended()
+	assert.Len(t, spans, 4)
+	assert.Equal(t, codes.Unset, spans[0].Status().Code)
+
+	assert.Equal(t, codes.Error, spans[1].Status().Code)
+	assert.Contains(t, spans[1].Attributes(), ErrorTypeKey.String("NotFound"))
+
+	assert.Equal(t, codes.Error, spans[2].Status().Code)
+	assert.Equal(t, "admission webhook denied the request", spans[2].Status().Description)
+	assert.Contains(t, spans[2].Attributes(), ErrorTypeKey.String("*errors.errorString"))
+
+	assert.Equal(t, codes.Error, spans[3].Status().Code)
+	assert.Contains(t, spans[3].Attributes(), ErrorTypeKey.String("NotFound"))
+}
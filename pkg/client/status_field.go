@@ -0,0 +1,153 @@
+package client
+
+import (
+	"fmt"
+	"slices"
+	"sync"
+
+	"go.opentelemetry.io/otel/trace"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/apiutil"
+)
+
+// traceContextFields holds the status field paths registered with RegisterTraceContextField
+var traceContextFields = struct {
+	mu    sync.RWMutex
+	paths map[schema.GroupKind][]string
+}{paths: map[schema.GroupKind][]string{}}
+
+// RegisterTraceContextField makes the TracingClient store the trace of the kind gk in the status field at
+// fields, e.g. "status", "traceContext", instead of the status conditions, for CRDs modelling it
+// explicitly in their API.  The field is an object with traceID and spanID string fields:
+//
+//	status:
+//	  traceContext:
+//	    traceID: f620f5cad0af940c294f980c5366a6a1
+//	    spanID: 45f359cdc1c8ab06
+//
+// It is read and written through unstructured access, and applies to every TracingClient of the binary.
+func RegisterTraceContextField(gk schema.GroupKind, fields ...string) {
+	traceContextFields.mu.Lock()
+	defer traceContextFields.mu.Unlock()
+	traceContextFields.paths[gk] = fields
+}
+
+// traceContextField returns the status field path registered for the kind of obj, if any
+func traceContextField(obj client.Object, scheme *runtime.Scheme) ([]string, bool) {
+	gvk, err := apiutil.GVKForObject(obj, scheme)
+	if err != nil {
+		return nil, false
+	}
+
+	traceContextFields.mu.RLock()
+	defer traceContextFields.mu.RUnlock()
+	fields, ok := traceContextFields.paths[gvk.GroupKind()]
+	return fields, ok
+}
+
+// getTraceContextField reads the trace and span IDs stored at fields of obj
+func getTraceContextField(obj client.Object, fields []string) (string, string, error) {
+	content, err := unstructuredContent(obj)
+	if err != nil {
+		return "", "", err
+	}
+
+	traceContext, found, err := unstructured.NestedStringMap(content, fields...)
+	if err != nil {
+		return "", "", fmt.Errorf("problem reading the trace context field: %w", err)
+	}
+	if !found || traceContext["traceID"] == "" {
+		return "", "", ErrNoTraceContext
+	}
+	return traceContext["traceID"], traceContext["spanID"], nil
+}
+
+// setTraceContextField stores the trace and span IDs of spanContext at fields of obj
+func setTraceContextField(spanContext trace.SpanContext, obj client.Object, fields []string) error {
+	return updateUnstructuredContent(obj, func(content map[string]interface{}) error {
+		return unstructured.SetNestedStringMap(content, map[string]string{
+			"traceID": spanContext.TraceID().String(),
+			"spanID":  spanContext.SpanID().String(),
+		}, fields...)
+	})
+}
+
+// deleteTraceContextField removes the field at fields of obj
+func deleteTraceContextField(obj client.Object, fields []string) error {
+	return updateUnstructuredContent(obj, func(content map[string]interface{}) error {
+		unstructured.RemoveNestedField(content, fields...)
+		return nil
+	})
+}
+
+// setStatusTraceContext stores spanContext in the status of obj, in the registered status field or else in
+// the conditions of format
+func setStatusTraceContext(spanContext trace.SpanContext, obj client.Object, scheme *runtime.Scheme, format ConditionFormat) error {
+	if fields, ok := traceContextField(obj, scheme); ok {
+		return setTraceContextField(spanContext, obj, fields)
+	}
+	return setTraceConditions(spanContext, obj, scheme, format)
+}
+
+// hasStatusTraceContext reports whether the status of obj carries a trace, in the registered status field or
+// else in the conditions of either format
+func hasStatusTraceContext(obj client.Object, scheme *runtime.Scheme) bool {
+	if fields, ok := traceContextField(obj, scheme); ok {
+		_, _, err := getTraceContextField(obj, fields)
+		return err == nil
+	}
+	return hasTraceConditions(obj, scheme)
+}
+
+// deleteStatusTraceContext removes the trace from the status of obj
+func deleteStatusTraceContext(obj client.Object, scheme *runtime.Scheme) error {
+	if fields, ok := traceContextField(obj, scheme); ok {
+		return deleteTraceContextField(obj, fields)
+	}
+	return deleteConditions(obj, scheme, traceConditionTypes...)
+}
+
+// deleteStatusTrace removes the trace from the status of obj, but for the trace ID if keepTraceID
+func deleteStatusTrace(obj client.Object, scheme *runtime.Scheme, keepTraceID bool) error {
+	if !keepTraceID {
+		return deleteStatusTraceContext(obj, scheme)
+	}
+	if fields, ok := traceContextField(obj, scheme); ok {
+		return deleteTraceContextField(obj, append(slices.Clone(fields), "spanID"))
+	}
+	return deleteSpanConditions(obj, scheme)
+}
+
+// unstructuredContent returns the content of obj as unstructured data, a copy for typed objects
+func unstructuredContent(obj client.Object) (map[string]interface{}, error) {
+	if u, ok := obj.(runtime.Unstructured); ok {
+		return u.UnstructuredContent(), nil
+	}
+	content, err := runtime.DefaultUnstructuredConverter.ToUnstructured(obj)
+	if err != nil {
+		return nil, fmt.Errorf("problem converting to unstructured: %w", err)
+	}
+	return content, nil
+}
+
+// updateUnstructuredContent applies update to the unstructured content of obj and writes it back to obj
+func updateUnstructuredContent(obj client.Object, update func(map[string]interface{}) error) error {
+	content, err := unstructuredContent(obj)
+	if err != nil {
+		return err
+	}
+	if err := update(content); err != nil {
+		return err
+	}
+	if u, ok := obj.(runtime.Unstructured); ok {
+		u.SetUnstructuredContent(content)
+		return nil
+	}
+	if err := runtime.DefaultUnstructuredConverter.FromUnstructured(content, obj); err != nil {
+		return fmt.Errorf("problem converting from unstructured: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,48 @@
+package client
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/otel/trace"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+func TestTraceContextField(t *testing.T) {
+	RegisterTraceContextField(schema.GroupKind{Group: "example.com", Kind: "Widget"}, "status", "traceContext")
+
+	widget := &unstructured.Unstructured{}
+	widget.SetGroupVersionKind(schema.GroupVersionKind{Group: "example.com", Version: "v1", Kind: "Widget"})
+	widget.SetName("widget")
+	scheme := runtime.NewScheme()
+
+	_, err := TraceContextFromObject(widget, scheme)
+	assert.ErrorIs(t, err, ErrNoTraceContext)
+	assert.False(t, hasStatusTraceContext(widget, scheme))
+
+	traceID, _ := trace.TraceIDFromHex("f620f5cad0af940c294f980c5366a6a1")
+	spanID, _ := trace.SpanIDFromHex("45f359cdc1c8ab06")
+	spanContext := trace.NewSpanContext(trace.SpanContextConfig{TraceID: traceID, SpanID: spanID})
+
+	// The trace is stored in the field instead of the conditions
+	err = setStatusTraceContext(spanContext, widget, scheme, TraceAndSpanIDConditions)
+	assert.NoError(t, err)
+	traceContext, found, _ := unstructured.NestedStringMap(widget.Object, "status", "traceContext")
+	assert.True(t, found)
+	assert.Equal(t, map[string]string{"traceID": "f620f5cad0af940c294f980c5366a6a1", "spanID": "45f359cdc1c8ab06"}, traceContext)
+	_, found, _ = unstructured.NestedSlice(widget.Object, "status", "conditions")
+	assert.False(t, found)
+
+	extracted, err := TraceContextFromObject(widget, scheme)
+	assert.NoError(t, err)
+	assert.Equal(t, traceID, extracted.TraceID())
+	assert.Equal(t, spanID, extracted.SpanID())
+	assert.True(t, hasStatusTraceContext(widget, scheme))
+
+	err = deleteStatusTraceContext(widget, scheme)
+	assert.NoError(t, err)
+	_, found, _ = unstructured.NestedFieldNoCopy(widget.Object, "status", "traceContext")
+	assert.False(t, found)
+}
@@ -0,0 +1,67 @@
+package client
+
+import (
+	"strings"
+	"sync"
+
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/discovery"
+)
+
+// WithStatusSubresourceDiscovery looks up with dc whether a kind has a status subresource before EndTrace
+// patches the status.  For kinds without one, such as CRDs not enabling it, the conditions are removed by
+// patching the main resource instead of a status patch that is bound to fail.  The lookups are cached per kind.
+// Without it every kind is assumed to have a status subresource.
+func WithStatusSubresourceDiscovery(dc discovery.ServerResourcesInterface) Option {
+	return func(o *clientOptions) {
+		o.statusDiscovery = dc
+	}
+}
+
+// statusSubresources caches whether kinds have a status subresource
+type statusSubresources struct {
+	discovery discovery.ServerResourcesInterface
+
+	mu    sync.RWMutex
+	known map[schema.GroupVersionKind]bool
+}
+
+// has reports whether gvk has a status subresource.  When discovery fails it assumes it has one, and the
+// lookup is retried on the next call.
+func (s *statusSubresources) has(gvk schema.GroupVersionKind) bool {
+	if s == nil {
+		return true
+	}
+
+	s.mu.RLock()
+	hasStatus, ok := s.known[gvk]
+	s.mu.RUnlock()
+	if ok {
+		return hasStatus
+	}
+
+	resources, err := s.discovery.ServerResourcesForGroupVersion(gvk.GroupVersion().String())
+	if err != nil {
+		return true
+	}
+
+	resource := ""
+	for _, r := range resources.APIResources {
+		if r.Kind == gvk.Kind && !strings.Contains(r.Name, "/") {
+			resource = r.Name
+			break
+		}
+	}
+	hasStatus = false
+	for _, r := range resources.APIResources {
+		if resource != "" && r.Name == resource+"/status" {
+			hasStatus = true
+			break
+		}
+	}
+
+	s.mu.Lock()
+	s.known[gvk] = hasStatus
+	s.mu.Unlock()
+	return hasStatus
+}
@@ -0,0 +1,90 @@
+package client
+
+import (
+	"context"
+	"testing"
+
+	constants "github.com/kubetracer/kubetracer-go/pkg/constants"
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	fakediscovery "k8s.io/client-go/discovery/fake"
+	clienttesting "k8s.io/client-go/testing"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/client/interceptor"
+)
+
+func TestEndTraceWithoutStatusSubresource(t *testing.T) {
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "test-pod",
+			Namespace: "default",
+			Annotations: map[string]string{
+				constants.TraceIDAnnotation: "f620f5cad0af940c294f980c5366a6a1",
+				constants.SpanIDAnnotation:  "45f359cdc1c8ab06",
+			},
+		},
+		Status: corev1.PodStatus{
+			Conditions: []corev1.PodCondition{
+				{Type: corev1.PodReady, Status: corev1.ConditionTrue},
+				{Type: "TraceID", Message: "f620f5cad0af940c294f980c5366a6a1"},
+				{Type: "SpanID", Message: "45f359cdc1c8ab06"},
+			},
+		},
+	}
+	// Count the patches of the main resource and of the status subresource
+	patches, statusPatches := 0, 0
+	k8sClient := fake.NewClientBuilder().WithObjects(pod).WithInterceptorFuncs(interceptor.Funcs{
+		Patch: func(ctx context.Context, c client.WithWatch, obj client.Object, patch client.Patch, opts ...client.PatchOption) error {
+			patches++
+			return c.Patch(ctx, obj, patch, opts...)
+		},
+		SubResourcePatch: func(ctx context.Context, c client.Client, subResourceName string, obj client.Object, patch client.Patch, opts ...client.SubResourcePatchOption) error {
+			statusPatches++
+			return c.SubResource(subResourceName).Patch(ctx, obj, patch, opts...)
+		},
+	}).Build()
+
+	// Discovery does not list pods/status
+	dc := &fakediscovery.FakeDiscovery{Fake: &clienttesting.Fake{Resources: []*metav1.APIResourceList{{
+		GroupVersion: "v1",
+		APIResources: []metav1.APIResource{{Name: "pods", Kind: "Pod", Namespaced: true}},
+	}}}}
+	initTracer()
+	tracingClient := NewTracingClientWithOptions(k8sClient, WithStatusSubresourceDiscovery(dc))
+
+	endedPod, err := tracingClient.EndTrace(context.Background(), pod.DeepCopy())
+	assert.NoError(t, err)
+	assert.Empty(t, endedPod.GetAnnotations()[constants.TraceIDAnnotation])
+
+	// The conditions are removed by the patch of the annotations
+	assert.Equal(t, 1, patches)
+	assert.Equal(t, 0, statusPatches)
+
+	// The lookup is cached
+	assert.Len(t, dc.Actions(), 1)
+	_, err = tracingClient.EndTrace(context.Background(), pod.DeepCopy())
+	assert.NoError(t, err)
+	assert.Len(t, dc.Actions(), 1)
+}
+
+func TestStatusSubresourcesDetection(t *testing.T) {
+	dc := &fakediscovery.FakeDiscovery{Fake: &clienttesting.Fake{Resources: []*metav1.APIResourceList{{
+		GroupVersion: "v1",
+		APIResources: []metav1.APIResource{
+			{Name: "pods", Kind: "Pod"},
+			{Name: "pods/status", Kind: "Pod"},
+			{Name: "configmaps", Kind: "ConfigMap"},
+		},
+	}}}}
+	subresources := &statusSubresources{discovery: dc, known: map[schema.GroupVersionKind]bool{}}
+
+	assert.True(t, subresources.has(corev1.SchemeGroupVersion.WithKind("Pod")))
+	assert.False(t, subresources.has(corev1.SchemeGroupVersion.WithKind("ConfigMap")))
+	// Kinds of unknown group versions are assumed to have one
+	assert.True(t, subresources.has(schema.GroupVersionKind{Group: "example.com", Version: "v1", Kind: "Widget"}))
+	// No discovery configured
+	assert.True(t, (*statusSubresources)(nil).has(corev1.SchemeGroupVersion.WithKind("ConfigMap")))
+}
@@ -0,0 +1,179 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/go-logr/logr"
+	"go.opentelemetry.io/otel/trace"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/apiutil"
+)
+
+// tracingSubResourceClient records spans for the operations on a subresource, e.g. scale or eviction
+type tracingSubResourceClient struct {
+	scheme *runtime.Scheme
+	client.SubResourceClient
+	trace.Tracer
+	Logger      logr.Logger
+	onSpanEnd   OnSpanEndFunc
+	stackTraces *stackTraces
+	propagation *tracePropagation
+	policy      *tracePolicy
+	sampler     SamplerFunc
+	spanNames   SpanNameFormatter
+
+	missingConditions MissingConditionsPolicy
+
+	// subResource is the name of the subresource, e.g. "scale"
+	subResource string
+}
+
+// SubResource returns a client for the named subresource of objects, recording spans named after the
+// subresource, e.g. "ScaleUpdate Deployment my-deployment".  Writes to the status subresource store the
+// trace in the status as Status() does, and the body of a Create, e.g. an Eviction, carries the trace
+// annotations.
+func (tc *tracingClient) SubResource(subResource string) client.SubResourceClient {
+	return &tracingSubResourceClient{
+		scheme:            tc.scheme,
+		SubResourceClient: tc.Client.SubResource(subResource),
+		Tracer:            tc.Tracer,
+		Logger:            tc.Logger,
+		onSpanEnd:         tc.onSpanEnd,
+		stackTraces:       tc.stackTraces,
+		propagation:       tc.propagation,
+		subResource:       subResource,
+		policy:            tc.policy,
+		sampler:           tc.sampler,
+		spanNames:         tc.spanNames,
+
+		missingConditions: tc.missingConditions,
+	}
+}
+
+// start starts the span of the verb on the subresource of obj, returning the kind of obj
+func (ts *tracingSubResourceClient) start(ctx context.Context, verb string, obj client.Object) (context.Context, trace.Span, string, error) {
+	gvk, err := apiutil.GVKForObject(obj, ts.scheme)
+	if err != nil {
+		return ctx, nil, "", fmt.Errorf("problem getting the scheme: %w", err)
+	}
+
+	prefix := ts.subResource
+	if prefix != "" {
+		prefix = strings.ToUpper(prefix[:1]) + prefix[1:]
+	}
+	operationName := ts.spanNames.spanName(prefix+verb, gvk, client.ObjectKeyFromObject(obj))
+	ctx, span := startSpanFromContext(ctx, ts.Logger, ts.Tracer, obj, ts.scheme, ts.propagation, operationName)
+	return ctx, span, gvk.GroupKind().Kind, nil
+}
+
+// verb is the verb passed to the OnSpanEndFunc, e.g. "scale-update"
+func (ts *tracingSubResourceClient) verb(verb string) string {
+	return ts.subResource + "-" + verb
+}
+
+func (ts *tracingSubResourceClient) Get(ctx context.Context, obj client.Object, subResource client.Object, opts ...client.SubResourceGetOption) error {
+	if tracingDisabled(ctx) || !ts.sampler.sample(ts.scheme, ts.verb("get"), obj, client.ObjectKeyFromObject(obj)) {
+		return ts.SubResourceClient.Get(ctx, obj, subResource, opts...)
+	}
+	ctx, span, kind, err := ts.start(ctx, "Get", obj)
+	if err != nil {
+		return err
+	}
+	defer endSpan(ctx, span, ts.onSpanEnd, ts.verb("get"), kind, obj)
+
+	err = ts.SubResourceClient.Get(ctx, obj, subResource, opts...)
+	if err != nil {
+		ts.stackTraces.recordError(span, err)
+	}
+	return err
+}
+
+func (ts *tracingSubResourceClient) Create(ctx context.Context, obj client.Object, subResource client.Object, opts ...client.SubResourceCreateOption) error {
+	if tracingDisabled(ctx) || !ts.sampler.sample(ts.scheme, ts.verb("create"), obj, client.ObjectKeyFromObject(obj)) {
+		return ts.SubResourceClient.Create(ctx, obj, subResource, opts...)
+	}
+	ctx, span, kind, err := ts.start(ctx, "Create", obj)
+	if err != nil {
+		return err
+	}
+	defer endSpan(ctx, span, ts.onSpanEnd, ts.verb("create"), kind, obj)
+
+	dryRun := recordDryRun(span, opts)
+	if err := ts.setTraceContext(span, obj, dryRun); err != nil {
+		ts.stackTraces.recordError(span, err)
+		return err
+	}
+	if subResource != nil && !dryRun && ts.policy.writes(subResource) {
+		addTraceIDAnnotation(ctx, subResource, ts.propagation)
+	}
+
+	ts.Logger.Info("creating subresource", "subresource", ts.subResource, "object", obj.GetName())
+	err = ts.SubResourceClient.Create(ctx, obj, subResource, opts...)
+	if err != nil {
+		ts.stackTraces.recordError(span, err)
+	}
+	return err
+}
+
+func (ts *tracingSubResourceClient) Update(ctx context.Context, obj client.Object, opts ...client.SubResourceUpdateOption) error {
+	if tracingDisabled(ctx) || !ts.sampler.sample(ts.scheme, ts.verb("update"), obj, client.ObjectKeyFromObject(obj)) {
+		return ts.SubResourceClient.Update(ctx, obj, opts...)
+	}
+	ctx, span, kind, err := ts.start(ctx, "Update", obj)
+	if err != nil {
+		return err
+	}
+	defer endSpan(ctx, span, ts.onSpanEnd, ts.verb("update"), kind, obj)
+
+	if err := ts.setTraceContext(span, obj, recordDryRun(span, opts)); err != nil {
+		ts.stackTraces.recordError(span, err)
+		return err
+	}
+
+	ts.Logger.Info("updating subresource", "subresource", ts.subResource, "object", obj.GetName())
+	err = ts.SubResourceClient.Update(ctx, obj, opts...)
+	if err != nil {
+		ts.stackTraces.recordError(span, err)
+	}
+	return err
+}
+
+func (ts *tracingSubResourceClient) Patch(ctx context.Context, obj client.Object, patch client.Patch, opts ...client.SubResourcePatchOption) error {
+	if tracingDisabled(ctx) || !ts.sampler.sample(ts.scheme, ts.verb("patch"), obj, client.ObjectKeyFromObject(obj)) {
+		return ts.SubResourceClient.Patch(ctx, obj, patch, opts...)
+	}
+	ctx, span, kind, err := ts.start(ctx, "Patch", obj)
+	if err != nil {
+		return err
+	}
+	defer endSpan(ctx, span, ts.onSpanEnd, ts.verb("patch"), kind, obj)
+
+	dryRun := recordDryRun(span, opts)
+	// the trace conditions would be owned by the field manager of an apply, and removed by its next apply
+	if !isApply(patch) {
+		if err := ts.setTraceContext(span, obj, dryRun); err != nil {
+			ts.stackTraces.recordError(span, err)
+			return err
+		}
+	}
+	recordPatch(span, patch, obj)
+
+	ts.Logger.Info("patching subresource", "subresource", ts.subResource, "object", obj.GetName())
+	err = ts.SubResourceClient.Patch(ctx, obj, patch, opts...)
+	if err != nil {
+		ts.stackTraces.recordError(span, err)
+	}
+	return err
+}
+
+// setTraceContext stores the trace in the status of obj on writes to the status subresource but dry runs, if the
+// status stores it.  Other subresources ignore the metadata and status of obj.
+func (ts *tracingSubResourceClient) setTraceContext(span trace.Span, obj client.Object, dryRun bool) error {
+	if ts.subResource != "status" || dryRun || !ts.propagation.conditionStorage() || !ts.policy.writes(obj) {
+		return nil
+	}
+	return ts.missingConditions.setStatusTraceContext(span.SpanContext(), obj, ts.scheme, ts.propagation)
+}
@@ -0,0 +1,48 @@
+package client
+
+import (
+	"context"
+	"testing"
+
+	"github.com/go-logr/logr"
+	"github.com/stretchr/testify/assert"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	appsv1 "k8s.io/api/apps/v1"
+	autoscalingv1 "k8s.io/api/autoscaling/v1"
+	corev1 "k8s.io/api/core/v1"
+	policyv1 "k8s.io/api/policy/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestSubResource(t *testing.T) {
+	deployment := &appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{Name: "test-deployment", Namespace: "default"}}
+	pod := &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "test-pod", Namespace: "default"}}
+	k8sClient := fake.NewClientBuilder().WithObjects(deployment, pod).Build()
+	recorder := tracetest.NewSpanRecorder()
+	tracer := sdktrace.NewTracerProvider(sdktrace.WithSampler(sdktrace.AlwaysSample()), sdktrace.WithSpanProcessor(recorder)).Tracer("kubetracer")
+	tracingClient := NewTracingClient(k8sClient, k8sClient, tracer, logr.Discard())
+
+	ctx, span := tracingClient.StartSpan(context.Background(), "Reconcile")
+	scale := &autoscalingv1.Scale{}
+	assert.NoError(t, tracingClient.SubResource("scale").Get(ctx, deployment, scale))
+	scale.Spec.Replicas = 3
+	assert.NoError(t, tracingClient.SubResource("scale").Update(ctx, deployment, client.WithSubResourceBody(scale)))
+
+	eviction := &policyv1.Eviction{ObjectMeta: metav1.ObjectMeta{Name: "test-pod", Namespace: "default"}}
+	assert.NoError(t, tracingClient.SubResource("eviction").Create(ctx, pod, eviction))
+	span.End()
+
+	spans := recorder.Ended()
+	names := []string{}
+	for _, s := range spans {
+		names = append(names, s.Name())
+		assert.Equal(t, span.SpanContext().TraceID(), s.SpanContext().TraceID())
+	}
+	assert.Equal(t, []string{"ScaleGet Deployment test-deployment", "ScaleUpdate Deployment test-deployment", "EvictionCreate Pod test-pod", "Reconcile"}, names)
+
+	// The eviction carries the trace of its span
+	assert.Equal(t, spans[2].SpanContext().SpanID().String(), eviction.Annotations["kubetracer.io/span-id"])
+}
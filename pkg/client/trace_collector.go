@@ -0,0 +1,149 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// DefaultTraceCollectorInterval is how often TraceCollector looks for stale traces
+const DefaultTraceCollectorInterval = 10 * time.Minute
+
+// TraceCollectorRemovedKey is the attribute of the CollectStaleTraces span holding the number of objects whose
+// trace was removed
+const TraceCollectorRemovedKey = attribute.Key("kubetracer.trace_collector.removed")
+
+// TraceCollector removes the trace left on objects by controllers which crashed or were restarted before calling
+// EndTrace.  It periodically lists the objects of the configured kinds and ends the trace of those whose trace
+// was attached more than the TTL ago, according to the kubetracer.io/trace-time annotation, or is reported
+// finished by Finished.  The trace is removed by EndTrace of the TracingClient, so that a newer trace written
+// concurrently is left alone.
+//
+// TraceCollector is a manager Runnable running on the leader:
+//
+//	mgr.Add(kubetracer.NewTraceCollector(tracingClient, 24*time.Hour, 0,
+//		appsv1.SchemeGroupVersion.WithKind("Deployment"), corev1.SchemeGroupVersion.WithKind("ConfigMap")))
+type TraceCollector struct {
+	tracingClient TracingClient
+	ttl           time.Duration
+	interval      time.Duration
+	gvks          []schema.GroupVersionKind
+
+	// Finished if set reports whether the trace with the hex encoded traceID is finished, e.g. by looking it up
+	// in the tracing backend.  The trace of objects whose trace is finished is removed regardless of its age,
+	// including objects written before the kubetracer.io/trace-time annotation existed.
+	Finished func(ctx context.Context, traceID string) (bool, error)
+}
+
+// NewTraceCollector returns a TraceCollector ending through tc the traces of the objects of gvks attached more
+// than ttl ago, every interval, DefaultTraceCollectorInterval if zero.  A ttl of zero or less only removes the
+// traces reported finished by Finished.
+func NewTraceCollector(tc TracingClient, ttl, interval time.Duration, gvks ...schema.GroupVersionKind) *TraceCollector {
+	if interval == 0 {
+		interval = DefaultTraceCollectorInterval
+	}
+	return &TraceCollector{
+		tracingClient: tc,
+		ttl:           ttl,
+		interval:      interval,
+		gvks:          gvks,
+	}
+}
+
+// Collect ends the stale traces of the objects of the configured kinds once.  The kinds are listed through the
+// Reader of the TracingClient, without tracing the Lists, and the EndTraces share a CollectStaleTraces span
+// when there is anything to remove.  It fails if the TracingClient was not built by this package.
+func (c *TraceCollector) Collect(ctx context.Context) error {
+	tc, ok := c.tracingClient.(*tracingClient)
+	if !ok {
+		return fmt.Errorf("unsupported TracingClient %T", c.tracingClient)
+	}
+	keys := tc.propagation.annotationKeys()
+
+	var errs []error
+	var stale []client.Object
+	for _, gvk := range c.gvks {
+		list := &unstructured.UnstructuredList{}
+		list.SetGroupVersionKind(gvk.GroupVersion().WithKind(gvk.Kind + "List"))
+		if err := tc.Reader.List(ctx, list); err != nil {
+			errs = append(errs, fmt.Errorf("problem listing %s: %w", gvk.Kind, err))
+			continue
+		}
+		for i := range list.Items {
+			obj := &list.Items[i]
+			obj.SetGroupVersionKind(gvk)
+			isStale, err := c.stale(ctx, obj, keys)
+			if err != nil {
+				errs = append(errs, fmt.Errorf("problem checking the trace of %s %s: %w", gvk.Kind, obj.GetName(), err))
+			}
+			if isStale {
+				stale = append(stale, obj)
+			}
+		}
+	}
+	if len(stale) == 0 {
+		return errors.Join(errs...)
+	}
+
+	ctx, span := tc.Tracer.Start(ctx, "CollectStaleTraces", trace.WithAttributes(TraceCollectorRemovedKey.Int(len(stale))))
+	defer span.End()
+	for _, obj := range stale {
+		tc.Logger.Info("Removing stale trace", "object", obj.GetName(), "namespace", obj.GetNamespace())
+		if _, err := c.tracingClient.EndTrace(ctx, obj); err != nil {
+			tc.stackTraces.recordError(span, err)
+			errs = append(errs, fmt.Errorf("problem ending the trace of %s: %w", obj.GetName(), err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// stale reports whether the trace of obj is to be removed
+func (c *TraceCollector) stale(ctx context.Context, obj client.Object, keys *annotationKeys) (bool, error) {
+	traceID, _, traced := traceIDs(obj, keys)
+	if !traced {
+		return false, nil
+	}
+	if age, ok := traceAge(obj, keys); ok && c.ttl > 0 && age > c.ttl {
+		return true, nil
+	}
+	if c.Finished == nil {
+		return false, nil
+	}
+	return c.Finished(ctx, traceID)
+}
+
+// Start implements manager.Runnable.  It collects the stale traces every interval until ctx is done.  It fails
+// right away if the TracingClient was not built by this package.
+func (c *TraceCollector) Start(ctx context.Context) error {
+	tc, ok := c.tracingClient.(*tracingClient)
+	if !ok {
+		return fmt.Errorf("unsupported TracingClient %T", c.tracingClient)
+	}
+
+	ticker := time.NewTicker(c.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			// the objects which failed are collected again on the next tick
+			if err := c.Collect(ctx); err != nil {
+				tc.Logger.Error(err, "Failed to collect stale traces")
+			}
+		case <-ctx.Done():
+			return nil
+		}
+	}
+}
+
+// NeedLeaderElection implements manager.LeaderElectionRunnable.  A single replica removes the stale traces.
+func (c *TraceCollector) NeedLeaderElection() bool {
+	return true
+}
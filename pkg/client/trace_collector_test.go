@@ -0,0 +1,90 @@
+package client
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	constants "github.com/kubetracer/kubetracer-go/pkg/constants"
+	"github.com/stretchr/testify/assert"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestTraceCollector(t *testing.T) {
+	tracedPod := func(name, traceID string, attached time.Time) *corev1.Pod {
+		annotations := map[string]string{
+			constants.TraceIDAnnotation: traceID,
+			constants.SpanIDAnnotation:  "b7ad6b7169203331",
+		}
+		if !attached.IsZero() {
+			annotations[constants.TraceTimeAnnotation] = attached.UTC().Format(time.RFC3339)
+		}
+		return &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default", Annotations: annotations}}
+	}
+	k8sClient := fake.NewClientBuilder().WithObjects(
+		tracedPod("stale-pod", "0af7651916cd43dd8448eb211c80319c", time.Now().Add(-7*24*time.Hour)),
+		tracedPod("recent-pod", "f620f5cad0af940c294f980c5366a6a1", time.Now().Add(-time.Minute)),
+		tracedPod("finished-pod", "4bf92f3577b34da6a3ce929d0e0e4736", time.Time{}),
+		tracedPod("legacy-pod", "5b8aa5a2d2c872e8321cf37308d69df2", time.Time{}),
+		&corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "untraced-pod", Namespace: "default"}},
+	).Build()
+	recorder := tracetest.NewSpanRecorder()
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSampler(sdktrace.AlwaysSample()), sdktrace.WithSpanProcessor(recorder))
+	tracingClient := NewTracingClientWithOptions(k8sClient, WithTracerProvider(tp))
+
+	collector := NewTraceCollector(tracingClient, time.Hour, 0, corev1.SchemeGroupVersion.WithKind("Pod"))
+	collector.Finished = func(_ context.Context, traceID string) (bool, error) {
+		return traceID == "4bf92f3577b34da6a3ce929d0e0e4736", nil
+	}
+	assert.NoError(t, collector.Collect(context.Background()))
+
+	traceIDOf := func(name string) string {
+		pod := &corev1.Pod{}
+		assert.NoError(t, k8sClient.Get(context.Background(), client.ObjectKey{Name: name, Namespace: "default"}, pod))
+		return pod.Annotations[constants.TraceIDAnnotation]
+	}
+	assert.Empty(t, traceIDOf("stale-pod"))
+	assert.Empty(t, traceIDOf("finished-pod"))
+	assert.Equal(t, "f620f5cad0af940c294f980c5366a6a1", traceIDOf("recent-pod"))
+	assert.Equal(t, "5b8aa5a2d2c872e8321cf37308d69df2", traceIDOf("legacy-pod"))
+
+	// the EndTraces are children of the span of the collection
+	var collect sdktrace.ReadOnlySpan
+	var endTraces []sdktrace.ReadOnlySpan
+	for _, span := range recorder.Ended() {
+		if span.Name() == "CollectStaleTraces" {
+			collect = span
+		} else {
+			endTraces = append(endTraces, span)
+		}
+	}
+	if assert.NotNil(t, collect) {
+		assert.Contains(t, collect.Attributes(), TraceCollectorRemovedKey.Int(2))
+		assert.Len(t, endTraces, 2)
+		for _, span := range endTraces {
+			assert.Equal(t, collect.SpanContext().SpanID(), span.Parent().SpanID())
+		}
+	}
+
+	// nothing is left to remove, no span is started
+	assert.NoError(t, collector.Collect(context.Background()))
+	assert.Len(t, recorder.Ended(), 3)
+}
+
+// wrappedTracingClient is a TracingClient not built by this package
+type wrappedTracingClient struct {
+	TracingClient
+}
+
+func TestTraceCollectorUnsupportedClient(t *testing.T) {
+	k8sClient := fake.NewClientBuilder().Build()
+	collector := NewTraceCollector(wrappedTracingClient{NewTracingClientWithOptions(k8sClient)}, time.Hour, 0, corev1.SchemeGroupVersion.WithKind("Pod"))
+
+	assert.ErrorContains(t, collector.Collect(context.Background()), "unsupported TracingClient")
+	assert.ErrorContains(t, collector.Start(context.Background()), "unsupported TracingClient")
+}
@@ -0,0 +1,158 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"slices"
+
+	constants "github.com/kubetracer/kubetracer-go/pkg/constants"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// ConditionFormat selects the status conditions the trace is stored in.  Both formats are always read, so that
+// the traces written before switching format are continued.
+type ConditionFormat int
+
+const (
+	// TraceAndSpanIDConditions stores the trace and span IDs in the TraceID and SpanID conditions.  It is the
+	// default.
+	TraceAndSpanIDConditions ConditionFormat = iota
+	// SingleTraceContextCondition stores the W3C traceparent in the single kubetracer.io/TraceContext condition,
+	// halving the status churn and the conditions shown by kubectl describe
+	SingleTraceContextCondition
+)
+
+// traceConditionTypes are the types of the conditions the trace is stored in, in either format
+var traceConditionTypes = []string{"TraceID", "SpanID", constants.TraceContextCondition}
+
+// WithConditionFormat stores the trace in the status conditions of format.  Writing the trace to the status
+// replaces the conditions of the other format, migrating the objects as they are written.
+func WithConditionFormat(format ConditionFormat) Option {
+	return func(o *clientOptions) {
+		o.conditions = format
+	}
+}
+
+// WithConditionStorage set to false never stores the trace in the status, for teams propagating it in the
+// annotations only: Status().Update and Patch leave the conditions, and the registered status fields, untouched,
+// so that third-party controllers watching them are not triggered, the trace is only read from the metadata,
+// and EndTrace leaves the status as is.  The trace is stored in the status by default.
+func WithConditionStorage(enabled bool) Option {
+	return func(o *clientOptions) {
+		o.noConditions = !enabled
+	}
+}
+
+// conditionStorage reports whether the trace is stored in and read from the status, true when p is nil
+func (p *tracePropagation) conditionStorage() bool {
+	return p == nil || !p.noConditions
+}
+
+// conditionsScheme returns scheme to read the trace from the status, nil to only read it from the metadata when
+// the status does not store it
+func (p *tracePropagation) conditionsScheme(scheme *runtime.Scheme) *runtime.Scheme {
+	if !p.conditionStorage() {
+		return nil
+	}
+	return scheme
+}
+
+// conditionFormat returns the format of the trace conditions, the TraceID and SpanID conditions when p is nil
+func (p *tracePropagation) conditionFormat() ConditionFormat {
+	if p == nil {
+		return TraceAndSpanIDConditions
+	}
+	return p.conditions
+}
+
+// setTraceConditions sets the conditions of format to spanContext in one pass, removing those of the other format
+func setTraceConditions(spanContext trace.SpanContext, obj client.Object, scheme *runtime.Scheme, format ConditionFormat) error {
+	if format == SingleTraceContextCondition {
+		return setConditionMessages([]conditionMessage{
+			{conditionType: constants.TraceContextCondition, message: traceparentOf(spanContext)},
+		}, obj, scheme, "TraceID", "SpanID")
+	}
+	return setConditionMessages([]conditionMessage{
+		{conditionType: "TraceID", message: spanContext.TraceID().String()},
+		{conditionType: "SpanID", message: spanContext.SpanID().String()},
+	}, obj, scheme, constants.TraceContextCondition)
+}
+
+// getTraceConditions returns the trace and span IDs stored in the conditions of obj, and whether they carry a
+// trace ID.  The TraceContext condition takes precedence over the TraceID and SpanID conditions.
+func getTraceConditions(obj client.Object, scheme *runtime.Scheme) (string, string, bool) {
+	conditions, err := getConditions(obj, scheme)
+	if err != nil {
+		return "", "", false
+	}
+
+	if condition := meta.FindStatusCondition(conditions, constants.TraceContextCondition); condition != nil {
+		spanContext := parseTraceparent(condition.Message)
+		return spanContext.TraceID().String(), spanContext.SpanID().String(), spanContext.IsValid()
+	}
+	traceID := meta.FindStatusCondition(conditions, "TraceID")
+	if traceID == nil {
+		return "", "", false
+	}
+	spanID := meta.FindStatusCondition(conditions, "SpanID")
+	if spanID == nil {
+		return traceID.Message, "", true
+	}
+	return traceID.Message, spanID.Message, true
+}
+
+// hasTraceConditions reports whether obj has any of the conditions the trace is stored in
+func hasTraceConditions(obj client.Object, scheme *runtime.Scheme) bool {
+	conditions, err := getConditions(obj, scheme)
+	if err != nil {
+		return false
+	}
+	return slices.ContainsFunc(conditions, func(condition metav1.Condition) bool {
+		return slices.Contains(traceConditionTypes, condition.Type)
+	})
+}
+
+// deleteSpanConditions removes the span ID from the conditions of obj, keeping its trace ID: the TraceContext
+// condition is replaced by the TraceID condition
+func deleteSpanConditions(obj client.Object, scheme *runtime.Scheme) error {
+	conditions, err := getConditions(obj, scheme)
+	if err != nil {
+		return err
+	}
+	condition := meta.FindStatusCondition(conditions, constants.TraceContextCondition)
+	if condition == nil {
+		return deleteConditions(obj, scheme, "SpanID")
+	}
+	spanContext := parseTraceparent(condition.Message)
+	if !spanContext.IsValid() {
+		return deleteConditions(obj, scheme, "SpanID", constants.TraceContextCondition)
+	}
+	return setConditionMessages([]conditionMessage{
+		{conditionType: "TraceID", message: spanContext.TraceID().String()},
+	}, obj, scheme, "SpanID", constants.TraceContextCondition)
+}
+
+// copyTraceConditions copies the conditions the trace is stored in from src to dst
+func copyTraceConditions(src, dst client.Object, scheme *runtime.Scheme) error {
+	for _, conditionType := range traceConditionTypes {
+		message, err := getConditionMessage(conditionType, src, scheme)
+		if err != nil {
+			continue
+		}
+		if err := setConditionMessage(conditionType, message, dst, scheme); err != nil {
+			return fmt.Errorf("problem setting %s condition on %s: %w", conditionType, dst.GetName(), err)
+		}
+	}
+	return nil
+}
+
+// parseTraceparent returns the span context of the W3C traceparent, invalid if traceparent is not one
+func parseTraceparent(traceparent string) trace.SpanContext {
+	ctx := propagation.TraceContext{}.Extract(context.Background(), propagation.MapCarrier{"traceparent": traceparent})
+	return trace.SpanContextFromContext(ctx)
+}
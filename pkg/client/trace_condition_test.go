@@ -0,0 +1,104 @@
+package client
+
+import (
+	"context"
+	"testing"
+
+	constants "github.com/kubetracer/kubetracer-go/pkg/constants"
+	"github.com/stretchr/testify/assert"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestWithConditionFormat(t *testing.T) {
+	// written by an earlier version in the TraceID and SpanID conditions
+	service := &corev1.Service{
+		ObjectMeta: metav1.ObjectMeta{Name: "test-service", Namespace: "default"},
+		Status: corev1.ServiceStatus{Conditions: []metav1.Condition{
+			{Type: "TraceID", Status: metav1.ConditionTrue, Reason: TraceConditionReason, Message: "f620f5cad0af940c294f980c5366a6a1"},
+			{Type: "SpanID", Status: metav1.ConditionTrue, Reason: TraceConditionReason, Message: "45f359cdc1c8ab06"},
+		}},
+	}
+	k8sClient := fake.NewClientBuilder().WithObjects(service).WithStatusSubresource(service).Build()
+	recorder := tracetest.NewSpanRecorder()
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSampler(sdktrace.AlwaysSample()), sdktrace.WithSpanProcessor(recorder))
+	tracingClient := NewTracingClientWithOptions(k8sClient, WithTracerProvider(tp), WithConditionFormat(SingleTraceContextCondition))
+
+	retrieved := &corev1.Service{}
+	assert.NoError(t, k8sClient.Get(context.Background(), client.ObjectKeyFromObject(service), retrieved))
+	spanContext, err := TraceContextFromObject(retrieved, clientgoscheme.Scheme)
+	assert.NoError(t, err)
+	assert.Equal(t, "f620f5cad0af940c294f980c5366a6a1", spanContext.TraceID().String())
+
+	assert.NoError(t, tracingClient.Status().Update(context.Background(), retrieved))
+	span := recorder.Ended()[0]
+	assert.Equal(t, "f620f5cad0af940c294f980c5366a6a1", span.SpanContext().TraceID().String())
+
+	assert.NoError(t, k8sClient.Get(context.Background(), client.ObjectKeyFromObject(service), retrieved))
+	assert.Len(t, retrieved.Status.Conditions, 1)
+	condition := meta.FindStatusCondition(retrieved.Status.Conditions, constants.TraceContextCondition)
+	assert.Equal(t, traceparentOf(span.SpanContext()), condition.Message)
+	assert.Equal(t, metav1.ConditionTrue, condition.Status)
+
+	spanContext, err = TraceContextFromObject(retrieved, clientgoscheme.Scheme)
+	assert.NoError(t, err)
+	assert.Equal(t, span.SpanContext().SpanID(), spanContext.SpanID())
+
+	// the trace ID is kept as the TraceID condition
+	assert.NoError(t, deleteStatusTrace(retrieved, clientgoscheme.Scheme, true))
+	assert.Len(t, retrieved.Status.Conditions, 1)
+	assert.Equal(t, span.SpanContext().TraceID().String(), meta.FindStatusCondition(retrieved.Status.Conditions, "TraceID").Message)
+	assert.NoError(t, deleteStatusTrace(retrieved, clientgoscheme.Scheme, false))
+	assert.Empty(t, retrieved.Status.Conditions)
+}
+
+func TestParseTraceparent(t *testing.T) {
+	spanContext := parseTraceparent("00-0af7651916cd43dd8448eb211c80319c-b7ad6b7169203331-01")
+	assert.True(t, spanContext.IsValid())
+	assert.Equal(t, trace.FlagsSampled, spanContext.TraceFlags())
+	assert.False(t, parseTraceparent("f620f5cad0af940c294f980c5366a6a1").IsValid())
+}
+
+func TestWithConditionStorage(t *testing.T) {
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "test-pod",
+			Namespace: "default",
+			Annotations: map[string]string{
+				constants.TraceIDAnnotation: "0af7651916cd43dd8448eb211c80319c",
+				constants.SpanIDAnnotation:  "b7ad6b7169203331",
+			},
+		},
+		Status: corev1.PodStatus{Conditions: []corev1.PodCondition{
+			{Type: "TraceID", Status: corev1.ConditionTrue, Message: "f620f5cad0af940c294f980c5366a6a1"},
+			{Type: "SpanID", Status: corev1.ConditionTrue, Message: "45f359cdc1c8ab06"},
+		}},
+	}
+	k8sClient := fake.NewClientBuilder().WithObjects(pod).WithStatusSubresource(pod).Build()
+	recorder := tracetest.NewSpanRecorder()
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSampler(sdktrace.AlwaysSample()), sdktrace.WithSpanProcessor(recorder))
+	tracingClient := NewTracingClientWithOptions(k8sClient, WithTracerProvider(tp), WithConditionStorage(false))
+
+	retrieved := &corev1.Pod{}
+	assert.NoError(t, k8sClient.Get(context.Background(), client.ObjectKeyFromObject(pod), retrieved))
+	assert.NoError(t, tracingClient.Status().Update(context.Background(), retrieved))
+
+	// the trace is read from the annotations, and the conditions are left as they were
+	span := recorder.Ended()[0]
+	assert.Equal(t, "0af7651916cd43dd8448eb211c80319c", span.SpanContext().TraceID().String())
+	assert.NoError(t, k8sClient.Get(context.Background(), client.ObjectKeyFromObject(pod), retrieved))
+	assert.Equal(t, pod.Status.Conditions[1].Message, retrieved.Status.Conditions[1].Message)
+
+	patched := retrieved.DeepCopy()
+	patched.Status.Phase = corev1.PodRunning
+	assert.NoError(t, tracingClient.Status().Patch(context.Background(), patched, client.MergeFrom(retrieved)))
+	assert.Len(t, patched.Status.Conditions, 2)
+	assert.Equal(t, "45f359cdc1c8ab06", patched.Status.Conditions[1].Message)
+}
@@ -0,0 +1,54 @@
+package client
+
+import (
+	"time"
+
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// WithTraceTTL makes the TracingClient start a new trace rather than resume the trace of an object attached more
+// than ttl ago, as recorded by the kubetracer.io/trace-time annotation, so that the annotations left by a change
+// a week ago are not stitched into the traces of today.  Objects without the annotation, e.g. written by older
+// versions, are resumed regardless.  A ttl of zero or less resumes traces of any age, which is the default.
+func WithTraceTTL(ttl time.Duration) Option {
+	return func(o *clientOptions) {
+		o.traceTTL = ttl
+	}
+}
+
+// traceTTL returns the age past which the trace of an object is not resumed, zero when p is nil
+func (p *tracePropagation) traceTTL() time.Duration {
+	if p == nil {
+		return 0
+	}
+	return p.ttl
+}
+
+// stale reports whether the trace of obj was attached more than the TTL ago
+func (p *tracePropagation) stale(obj client.Object) bool {
+	ttl := p.traceTTL()
+	if ttl <= 0 {
+		return false
+	}
+	age, ok := traceAge(obj, p.annotationKeys())
+	return ok && age > ttl
+}
+
+// traceAge returns how long ago the trace of obj was attached, false when obj does not record it
+func traceAge(obj client.Object, keys *annotationKeys) (time.Duration, bool) {
+	attached, err := time.Parse(time.RFC3339, obj.GetAnnotations()[keys.traceTime])
+	if err != nil {
+		return 0, false
+	}
+	return time.Since(attached), true
+}
+
+// stampTraceTime records on obj that its trace was attached now
+func stampTraceTime(obj client.Object, keys *annotationKeys) {
+	annotations := obj.GetAnnotations()
+	if annotations == nil {
+		annotations = map[string]string{}
+	}
+	annotations[keys.traceTime] = time.Now().UTC().Format(time.RFC3339)
+	obj.SetAnnotations(annotations)
+}
@@ -0,0 +1,71 @@
+package client
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	constants "github.com/kubetracer/kubetracer-go/pkg/constants"
+	"github.com/stretchr/testify/assert"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestWithTraceTTL(t *testing.T) {
+	tracedPod := func(name string, attached time.Time) *corev1.Pod {
+		return &corev1.Pod{ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: "default",
+			Annotations: map[string]string{
+				constants.TraceIDAnnotation:   "0af7651916cd43dd8448eb211c80319c",
+				constants.SpanIDAnnotation:    "b7ad6b7169203331",
+				constants.TraceTimeAnnotation: attached.UTC().Format(time.RFC3339),
+			},
+		}}
+	}
+	k8sClient := fake.NewClientBuilder().WithObjects(
+		tracedPod("recent-pod", time.Now().Add(-time.Minute)),
+		tracedPod("stale-pod", time.Now().Add(-7*24*time.Hour)),
+	).Build()
+	recorder := tracetest.NewSpanRecorder()
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSampler(sdktrace.AlwaysSample()), sdktrace.WithSpanProcessor(recorder))
+	tracingClient := NewTracingClientWithOptions(k8sClient, WithTracerProvider(tp), WithTraceTTL(time.Hour))
+
+	startTrace := func(name string) sdktrace.ReadOnlySpan {
+		pod := &corev1.Pod{}
+		_, span, err := tracingClient.StartTrace(context.Background(), client.ObjectKey{Name: name, Namespace: "default"}, pod)
+		span.End()
+		assert.NoError(t, err)
+		spans := recorder.Ended()
+		return spans[len(spans)-1]
+	}
+
+	// the recent trace is resumed
+	span := startTrace("recent-pod")
+	assert.Equal(t, "0af7651916cd43dd8448eb211c80319c", span.SpanContext().TraceID().String())
+	assert.Equal(t, "b7ad6b7169203331", span.Parent().SpanID().String())
+
+	// the stale trace is not, a new one is started
+	span = startTrace("stale-pod")
+	assert.NotEqual(t, "0af7651916cd43dd8448eb211c80319c", span.SpanContext().TraceID().String())
+	assert.False(t, span.Parent().IsValid())
+
+	// writing the trace records when it was attached
+	ctx, span2, err := tracingClient.StartTrace(context.Background(), client.ObjectKey{Name: "stale-pod", Namespace: "default"}, &corev1.Pod{})
+	assert.NoError(t, err)
+	pod := &corev1.Pod{}
+	assert.NoError(t, tracingClient.Get(ctx, client.ObjectKey{Name: "stale-pod", Namespace: "default"}, pod))
+	assert.NoError(t, tracingClient.Update(ctx, pod))
+	span2.End()
+
+	updated := &corev1.Pod{}
+	assert.NoError(t, k8sClient.Get(context.Background(), client.ObjectKey{Name: "stale-pod", Namespace: "default"}, updated))
+	assert.Equal(t, span2.SpanContext().TraceID().String(), updated.Annotations[constants.TraceIDAnnotation])
+	attached, err := time.Parse(time.RFC3339, updated.Annotations[constants.TraceTimeAnnotation])
+	assert.NoError(t, err)
+	assert.WithinDuration(t, time.Now(), attached, time.Minute)
+}
@@ -2,16 +2,30 @@ package client
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
+	"maps"
 	"reflect"
 	"strings"
 
 	"github.com/go-logr/logr"
 	constants "github.com/kubetracer/kubetracer-go/pkg/constants"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
 	"go.opentelemetry.io/otel/trace"
+	"go.opentelemetry.io/otel/trace/noop"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/wait"
 	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/util/retry"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/client/apiutil"
 )
@@ -23,15 +37,82 @@ type tracingClient struct {
 	client.Reader
 	trace.Tracer
 	Logger logr.Logger
+
+	// reconciles tracks the reconciles of each object for the reconcile interval metric
+	reconciles *reconcileTimes
+
+	// onSpanEnd is called when the span of a client operation ends
+	onSpanEnd OnSpanEndFunc
+
+	// statusSubresources tells whether kinds have a status subresource, all are assumed to when nil
+	statusSubresources *statusSubresources
+
+	// lastOps if set maintains the last-ops annotation
+	lastOps *lastOps
+
+	// stackTraces if set records stack traces with errors
+	stackTraces *stackTraces
+
+	// propagation configures the format of the trace on objects
+	propagation *tracePropagation
+
+	// apiReader if set serves the Gets and Lists given WithLiveRead
+	apiReader client.Reader
+
+	// missingConditions is applied by status writes of kinds without conditions
+	missingConditions MissingConditionsPolicy
+
+	// endTraceRetry if set retries EndTrace on conflicts
+	endTraceRetry *wait.Backoff
+
+	// policy if set restricts the objects the trace is written to
+	policy *tracePolicy
+
+	// sampler if set decides which operations are traced
+	sampler SamplerFunc
+
+	// spanNames if set names the spans of operations
+	spanNames SpanNameFormatter
+
+	// copyOnWrite writes the trace to a copy of the objects given to Create, Update and Patch
+	copyOnWrite bool
+
+	// maxListLinks bounds the items linked from List spans, DefaultMaxListLinks when zero and none when negative
+	maxListLinks int
 }
 
 type tracingStatusClient struct {
 	scheme *runtime.Scheme
 	client.StatusWriter
 	trace.Tracer
-	Logger logr.Logger
+	Logger      logr.Logger
+	onSpanEnd   OnSpanEndFunc
+	stackTraces *stackTraces
+	propagation *tracePropagation
+	policy      *tracePolicy
+	sampler     SamplerFunc
+	spanNames   SpanNameFormatter
+
+	missingConditions MissingConditionsPolicy
 }
 
+// TracingClient is a client.Client recording spans and propagating the trace on the objects it writes.
+//
+// A TracingClient is safe for concurrent use by multiple goroutines, e.g. the workers of a controller sharing the
+// client of the manager, provided the wrapped client, reader and tracer are.  The trace is carried by the context
+// and the objects, the state shared between calls is:
+//   - the time of the last StartTrace of each object, for the kubetracer.reconcile.interval histogram, guarded
+//     by a mutex of the client and bounded in size and age;
+//   - the traces registered by RequeueWithTrace and RequeueAfterWithTrace, in a registry global to the process
+//     guarded by its mutex, taken by the next StartTrace of the object or dropped once expired;
+//   - the status fields registered with RegisterTraceContextField and the accessors registered with
+//     RegisterConditionsAccessor, global to the process and guarded by read-write mutexes, normally registered
+//     once at startup;
+//   - the status subresources found by discovery given WithStatusSubresourceDiscovery, cached per client and
+//     guarded by a read-write mutex.
+//
+// The objects passed to it are mutated, so like with any client.Client the same object must not be passed to
+// concurrent calls.
 type TracingClient interface {
 	client.Client
 	trace.Tracer
@@ -39,7 +120,14 @@ type TracingClient interface {
 	StartTrace(ctx context.Context, key client.ObjectKey, obj client.Object, opts ...client.GetOption) (context.Context, trace.Span, error)
 	EndTrace(ctx context.Context, obj client.Object, opts ...client.PatchOption) (client.Object, error)
 	StartSpan(ctx context.Context, operationName string) (context.Context, trace.Span)
+	// StartExternalSpan starts a span for a call to a dependency outside of Kubernetes
+	StartExternalSpan(ctx context.Context, peerService, operationName string, obj client.Object, opts ...trace.SpanStartOption) (context.Context, trace.Span)
 	EmbedTraceIDInNamespacedName(key *client.ObjectKey, obj client.Object) error
+	// CreateAll and ApplyAll write several objects under one parent span
+	CreateAll(ctx context.Context, objs []client.Object, opts ...client.CreateOption) error
+	ApplyAll(ctx context.Context, objs []client.Object, opts ...client.PatchOption) error
+	// StartBatch groups the client operations made with the returned context under one span
+	StartBatch(ctx context.Context, name string) (context.Context, EndBatch)
 }
 
 var _ TracingClient = (*tracingClient)(nil)
@@ -47,18 +135,28 @@ var _ client.StatusWriter = (*tracingStatusClient)(nil)
 
 // NewTracingClient initializes and returns a new TracingClient
 // optional scheme.  If not, it will use client-go scheme
+//
+// r is used by StartTrace and EndTrace to read the object.  If r is nil, c is used instead.
+// With a manager, c reads from the cache which may lag behind the API server, while
+// mgr.GetAPIReader() always reads the latest object at the cost of an API call.
 func NewTracingClient(c client.Client, r client.Reader, t trace.Tracer, l logr.Logger, scheme ...*runtime.Scheme) TracingClient {
 	tracingScheme := clientgoscheme.Scheme
 	if len(scheme) > 0 {
 		tracingScheme = scheme[0]
 	}
 
+	if r == nil {
+		r = c
+	}
+
 	return &tracingClient{
 		scheme: tracingScheme,
 		Client: c,
 		Reader: r,
 		Tracer: t,
 		Logger: l,
+
+		reconciles: newReconcileTimes(),
 	}
 }
 
@@ -69,20 +167,75 @@ func (tc *tracingClient) Create(ctx context.Context, obj client.Object, opts ...
 		return fmt.Errorf("problem getting the scheme: %w", err)
 	}
 
-	kind := gvk.GroupKind().Kind
-	ctx, span := startSpanFromContext(ctx, tc.Logger, tc.Tracer, obj, tc.scheme, fmt.Sprintf("Create %s %s", kind, obj.GetName()))
-	defer span.End()
+	return tc.create(ctx, gvk, obj, opts...)
+}
+
+// create is Create for an object of the given kind
+func (tc *tracingClient) create(ctx context.Context, gvk schema.GroupVersionKind, obj client.Object, opts ...client.CreateOption) error {
+	if tracingDisabled(ctx) || !tc.sampler.sample(tc.scheme, "create", obj, client.ObjectKeyFromObject(obj)) {
+		return tc.Client.Create(ctx, obj, untraced(opts)...)
+	}
+	kind := gvk.Kind
+	opts, spanOpts := splitSpanOptions(opts)
+	ctx, span := startSpanFromContext(ctx, tc.Logger, tc.Tracer, obj, tc.scheme, tc.propagation, mutationSpanName(ctx, tc.spanNames, "Create", gvk, createKey(obj)), spanOpts...)
+	// the name assigned by the API server is recorded by endSpan once obj is updated with the response
+	defer endSpan(ctx, span, tc.onSpanEnd, "create", kind, obj)
+	if obj.GetName() == "" && obj.GetGenerateName() != "" {
+		span.SetAttributes(GenerateNameKey.String(obj.GetGenerateName()))
+	}
 
-	addTraceIDAnnotation(ctx, obj)
+	opts, writeOpts := splitWriteOptions(opts)
+	writeOpts.dryRun = recordDryRun(span, opts)
+	written := tc.writeTarget(obj)
+	tc.writeTraceMetadata(ctx, "create", written, writeOpts)
 	tc.Logger.Info("Creating object", "object", obj.GetName())
-	err = tc.Client.Create(ctx, obj, opts...)
+	defer keepMetadataGVK(written)()
+	err := tc.Client.Create(ctx, written, opts...)
+	syncWriteResult(obj, written)
 	if err != nil {
-		span.RecordError(err)
+		tc.stackTraces.recordError(span, err)
 	}
 
 	return err
 }
 
+// writeTraceMetadata writes the trace in ctx to obj before the write verb, if the policy writes it to obj
+func (tc *tracingClient) writeTraceMetadata(ctx context.Context, verb string, obj client.Object, writeOpts writeOptions) {
+	if writeOpts.dryRun || !tc.policy.writes(obj) {
+		return
+	}
+	addTraceIDAnnotation(ctx, obj, tc.propagation)
+	if writeOpts.traceLabel {
+		addTraceLabel(ctx, obj)
+	}
+	if writeOpts.traceparentData {
+		addTraceparentData(ctx, obj)
+	}
+	tc.lastOps.add(ctx, verb, obj, tc.propagation.annotationKeys())
+}
+
+// writeTarget returns the object the trace is written to and which is sent to the API server, a deep copy of
+// obj with copy-on-write
+func (tc *tracingClient) writeTarget(obj client.Object) client.Object {
+	if !tc.copyOnWrite {
+		return obj
+	}
+	return obj.DeepCopyObject().(client.Object)
+}
+
+// syncWriteResult copies the resourceVersion and UID of the response from written back to obj, if they differ,
+// and the name generated for a generateName
+func syncWriteResult(obj, written client.Object) {
+	if obj == written {
+		return
+	}
+	obj.SetResourceVersion(written.GetResourceVersion())
+	obj.SetUID(written.GetUID())
+	if obj.GetName() == "" {
+		obj.SetName(written.GetName())
+	}
+}
+
 // Update adds tracing and traceID annotation around the original client's Update method
 func (tc *tracingClient) Update(ctx context.Context, obj client.Object, opts ...client.UpdateOption) error {
 	gvk, err := apiutil.GVKForObject(obj, tc.scheme)
@@ -90,30 +243,44 @@ func (tc *tracingClient) Update(ctx context.Context, obj client.Object, opts ...
 		return fmt.Errorf("problem getting the scheme: %w", err)
 	}
 
-	kind := gvk.GroupKind().Kind
-
-	ctx, span := startSpanFromContext(ctx, tc.Logger, tc.Tracer, obj, tc.scheme, fmt.Sprintf("Update %s %s", kind, obj.GetName()))
-	defer span.End()
+	return tc.update(ctx, gvk, obj, opts...)
+}
 
-	addTraceIDAnnotation(ctx, obj)
+// update is Update for an object of the given kind
+func (tc *tracingClient) update(ctx context.Context, gvk schema.GroupVersionKind, obj client.Object, opts ...client.UpdateOption) error {
+	if tracingDisabled(ctx) || !tc.sampler.sample(tc.scheme, "update", obj, client.ObjectKeyFromObject(obj)) {
+		return tc.Client.Update(ctx, obj, untraced(opts)...)
+	}
+	kind := gvk.Kind
+	parent := trace.SpanFromContext(ctx)
+	opts, spanOpts := splitSpanOptions(opts)
+	ctx, span := startSpanFromContext(ctx, tc.Logger, tc.Tracer, obj, tc.scheme, tc.propagation, mutationSpanName(ctx, tc.spanNames, "Update", gvk, client.ObjectKeyFromObject(obj)), spanOpts...)
+	defer endSpan(ctx, span, tc.onSpanEnd, "update", kind, obj)
+
+	opts, writeOpts := splitWriteOptions(opts)
+	writeOpts.dryRun = recordDryRun(span, opts)
+	written := tc.writeTarget(obj)
+	tc.writeTraceMetadata(ctx, "update", written, writeOpts)
 	tc.Logger.Info("Updating object", "object", obj.GetName())
 
-	err = tc.Client.Update(ctx, obj, opts...)
+	defer keepMetadataGVK(written)()
+	err := tc.Client.Update(ctx, written, opts...)
+	syncWriteResult(obj, written)
 	if err != nil {
-		span.RecordError(err)
+		tc.stackTraces.recordError(span, err)
+		tc.recordConflict(ctx, parent, span, "Update", kind, obj, err)
 	}
 
 	return err
 }
 
 func (tc *tracingClient) StartSpan(ctx context.Context, operationName string) (context.Context, trace.Span) {
-	return startSpanFromContext(ctx, tc.Logger, tc.Tracer, nil, tc.scheme, operationName)
+	return startSpanFromContext(ctx, tc.Logger, tc.Tracer, nil, tc.scheme, tc.propagation, operationName)
 }
 
 // EmbedTraceIDInNamespacedName embeds the traceID and spanID in the key.Name
 func (tc *tracingClient) EmbedTraceIDInNamespacedName(key *client.ObjectKey, obj client.Object) error {
-	traceID := obj.GetAnnotations()[constants.TraceIDAnnotation]
-	spanID := obj.GetAnnotations()[constants.SpanIDAnnotation]
+	traceID, spanID, _ := traceIDs(obj, tc.propagation.annotationKeys())
 	if traceID == "" || spanID == "" {
 		return nil
 	}
@@ -136,10 +303,19 @@ func (tc *tracingClient) EmbedTraceIDInNamespacedName(key *client.ObjectKey, obj
 func (tc *tracingClient) StartTrace(ctx context.Context, key client.ObjectKey, obj client.Object, opts ...client.GetOption) (context.Context, trace.Span, error) {
 	name := getNameFromNamespacedName(key)
 	initialKey := client.ObjectKey{Name: name, Namespace: key.Namespace}
+	startTraceOpts, getOpts := splitStartTraceOptions(opts)
 
 	// Create or retrieve the span from the context
-	_ = tc.Reader.Get(ctx, initialKey, obj, opts...)
-	overrideTraceIDFromNamespacedName(key, obj)
+	getErr := tc.Reader.Get(ctx, initialKey, obj, getOpts...)
+	if apierrors.IsNotFound(getErr) && startTraceOpts.apiReader != nil {
+		tc.Logger.Info("Object not found, retrying with the API reader", "object", initialKey.Name)
+		getErr = startTraceOpts.apiReader.Get(ctx, initialKey, obj, getOpts...)
+	}
+	// the trace taken from the key is not left on obj when the policy does not write it
+	if !tc.policy.writes(obj) {
+		defer obj.SetAnnotations(maps.Clone(obj.GetAnnotations()))
+	}
+	overrideTraceIDFromNamespacedName(key, obj, tc.propagation.annotationKeys())
 
 	gvk, err := apiutil.GVKForObject(obj, tc.scheme)
 	objectKind := ""
@@ -149,118 +325,397 @@ func (tc *tracingClient) StartTrace(ctx context.Context, key client.ObjectKey, o
 	callerName := getCallerNameFromNamespacedName(key)
 	callerKind := getCallerKindFromNamespacedName(key)
 
+	// the reconcile continues a propagated trace, rather than handling a standalone event
+	traced := callerKind != "" || trace.SpanContextFromContext(ctx).IsValid()
+
+	// a requeue continues the trace of the reconcile which requested it, unless the request embeds one
+	if callerKind == "" && getErr == nil {
+		if spanContext, ok := takeRequeueTrace(obj); ok {
+			injectSpanContext(spanContext, obj, tc.propagation.annotationKeys())
+			traced = true
+		}
+	}
+
+	// an object without a trace of its own joins the trace of the closest owner carrying one
+	ownerName := ""
+	if !traced && getErr == nil && startTraceOpts.ownerChainDepth > 0 &&
+		!trace.SpanContextFromContext(tc.propagation.extract(ctx, logr.Discard(), obj, tc.scheme)).IsValid() {
+		var spanContext trace.SpanContext
+		if spanContext, ownerName = tc.ownerTrace(ctx, obj, startTraceOpts.ownerChainDepth); spanContext.IsValid() {
+			injectSpanContext(spanContext, obj, tc.propagation.annotationKeys())
+			traced = true
+		}
+	}
+
+	if getErr == nil {
+		tc.reconciles.recordReconcile(ctx, obj, objectKind, traced)
+	} else if apierrors.IsNotFound(getErr) {
+		tc.reconciles.forget(obj, initialKey)
+	}
+
 	operationName := ""
 
-	if callerKind != "" && callerName != "" {
+	if tc.spanNames != nil {
+		operationName = tc.spanNames("StartTrace", gvk, initialKey)
+		if callerKind != "" && callerName != "" {
+			operationName = fmt.Sprintf("%s Triggered By %s %s", operationName, callerKind, callerName)
+		}
+	} else if callerKind != "" && callerName != "" {
 		operationName = fmt.Sprintf("StartTrace %s/%s Triggered By Changed Object %s/%s", objectKind, name, callerKind, callerName)
 	} else {
 		operationName = fmt.Sprintf("StartTrace %s %s", objectKind, name)
 	}
 
-	ctx, span := startSpanFromContext(ctx, tc.Logger, tc.Tracer, obj, tc.scheme, operationName)
+	ctx = contextWithInjectionTracker(ctx)
+	if callerKind != "" && callerName != "" {
+		ctx = contextWithTrigger(ctx, callerKind, callerName)
+	}
+
+	// the trace starts here if there is no parent in the context, the annotations or the key
+	isRoot := !trace.SpanContextFromContext(ctx).IsValid() &&
+		!trace.SpanContextFromContext(tc.propagation.extract(ctx, logr.Discard(), obj, tc.scheme)).IsValid()
+
+	ctx = contextWithActorFromObject(ctx, obj, tc.propagation.annotationKeys())
+	ctx, span := startSpanFromContext(ctx, tc.Logger, tc.Tracer, obj, tc.scheme, tc.propagation, operationName)
+	span.SetAttributes(keyAttributes(initialKey)...)
+	span.SetAttributes(objectAttributes("starttrace", objectKind, obj)...)
+	if ownerName != "" {
+		span.SetAttributes(TraceOwnerKey.String(ownerName))
+	}
 
 	if err != nil {
-		span.RecordError(err)
+		tc.stackTraces.recordError(span, err)
+	}
+
+	if isRoot {
+		ctx = contextWithTraceRoot(ctx, objectKind, name)
+	} else {
+		ctx = contextWithTraceRootFromObject(ctx, obj, tc.propagation.annotationKeys())
+	}
+
+	if startTraceOpts.recordTriggeredBy && tc.policy.writes(obj) && getErr == nil && callerKind != "" && callerName != "" {
+		if patchErr := tc.recordTriggeredBy(ctx, obj, callerKind, key.Namespace, callerName); patchErr != nil {
+			span.RecordError(patchErr)
+		}
+		// the patch response replaces the trace annotations taken from the key
+		overrideTraceIDFromNamespacedName(key, obj, tc.propagation.annotationKeys())
+	}
+
+	if startTraceOpts.strict && getErr != nil {
+		tc.stackTraces.recordError(span, getErr)
+		err = getErr
 	}
 
 	tc.Logger.Info("Getting object", "object", key.Name)
 	return trace.ContextWithSpan(ctx, span), span, err
 }
 
-// Ends the trace by clearing the traceid from the object
-func (tc *tracingClient) EndTrace(ctx context.Context, obj client.Object, opts ...client.PatchOption) (client.Object, error) {
-	ctx, span := startSpanFromContext(ctx, tc.Logger, tc.Tracer, obj, tc.scheme, fmt.Sprintf("EndTrace %s %s", obj.GetObjectKind().GroupVersionKind().Kind, obj.GetName()))
-	defer span.End()
+// recordTriggeredBy patches the triggered-by annotation on obj with the object that caused the reconcile
+func (tc *tracingClient) recordTriggeredBy(ctx context.Context, obj client.Object, callerKind, callerNamespace, callerName string) error {
+	triggeredBy := fmt.Sprintf("%s/%s", callerKind, callerName)
+	if callerNamespace != "" {
+		triggeredBy = fmt.Sprintf("%s/%s/%s", callerKind, callerNamespace, callerName)
+	}
+	keys := tc.propagation.annotationKeys()
+	if obj.GetAnnotations()[keys.triggeredBy] == triggeredBy {
+		return nil
+	}
 
+	patch := client.MergeFrom(obj.DeepCopyObject().(client.Object))
 	annotations := obj.GetAnnotations()
 	if annotations == nil {
+		annotations = map[string]string{}
+	}
+	annotations[keys.triggeredBy] = triggeredBy
+	obj.SetAnnotations(annotations)
+
+	tc.Logger.Info("Recording triggered-by", "object", obj.GetName(), "triggeredBy", triggeredBy)
+	return tc.Client.Patch(ctx, obj, patch)
+}
+
+// Ends the trace by clearing the traceid from the object
+func (tc *tracingClient) EndTrace(ctx context.Context, obj client.Object, opts ...client.PatchOption) (client.Object, error) {
+	ctx, span := startSpanFromContext(ctx, tc.Logger, tc.Tracer, obj, tc.scheme, tc.propagation, tc.spanNames.spanName("EndTrace", obj.GetObjectKind().GroupVersionKind(), client.ObjectKeyFromObject(obj)))
+	defer endSpan(ctx, span, tc.onSpanEnd, "endtrace", obj.GetObjectKind().GroupVersionKind().Kind, obj)
+
+	keys := tc.propagation.annotationKeys()
+	traceID, spanID, traced := traceIDs(obj, keys)
+	if (obj.GetAnnotations() == nil && !traced) || !tc.policy.writes(obj) {
 		return obj, nil
 	}
 
+	opts, endTraceOpts := splitEndTraceOptions(opts)
+	if tc.endTraceRetry == nil {
+		return obj, tc.validateAndEndTrace(ctx, span, obj, endTraceOpts, opts...)
+	}
+
+	// a conflicting attempt is retried on the object read again
+	attempt := 0
+	err := retry.RetryOnConflict(*tc.endTraceRetry, func() error {
+		attempt++
+		if attempt == 1 {
+			return tc.validateAndEndTrace(ctx, span, obj, endTraceOpts, opts...)
+		}
+
+		tc.Logger.Info("Retrying EndTrace after a conflict", "object", obj.GetName(), "attempt", attempt)
+		restoreGVK := keepMetadataGVK(obj)
+		err := tc.validationReader().Get(ctx, client.ObjectKeyFromObject(obj), obj)
+		restoreGVK()
+		if err != nil {
+			tc.stackTraces.recordError(span, err)
+			return err
+		}
+		// the trace may have been removed by the conflicting attempt already
+		currentTraceID, currentSpanID, _ := traceIDs(obj, keys)
+		if currentTraceID != "" && (currentTraceID != traceID || currentSpanID != spanID) {
+			tc.Logger.Info("Trace has changed, skipping patch", "object", obj.GetName())
+			span.RecordError(fmt.Errorf("trace has changed, skipping patch: object %s", obj.GetName()))
+			return nil
+		}
+		return tc.endTrace(ctx, span, obj, obj.GetResourceVersion(), endTraceOpts, opts...)
+	})
+	if err == nil && attempt > 1 {
+		// the conflicts recorded by the earlier attempts were recovered from
+		span.SetStatus(codes.Ok, "")
+	}
+	return obj, err
+}
+
+// validateAndEndTrace removes the trace from obj if the object stored in the API server still carries it, to
+// leave a newer trace alone.  The object is read from the API reader when the client has one.  The Reader may be
+// a cache serving an older copy than obj, the cleanup patch is then preconditioned on the resourceVersion of obj.
+func (tc *tracingClient) validateAndEndTrace(ctx context.Context, span trace.Span, obj client.Object, endTraceOpts endTraceOptions, opts ...client.PatchOption) error {
 	// get the current object and ensure that current object has the expected traceid and spanid annotations
-	currentObjFromServer := obj.DeepCopyObject().(client.Object)
-	err := tc.Reader.Get(ctx, client.ObjectKeyFromObject(obj), currentObjFromServer)
+	currentObjFromServer := tc.newObjectLike(obj)
+	err := tc.validationReader().Get(ctx, client.ObjectKeyFromObject(obj), currentObjFromServer)
 
 	if err != nil {
-		span.RecordError(err)
+		tc.stackTraces.recordError(span, err)
 	}
 
 	// compare the traceid and spanid from currentobj to ensure that the traceid and spanid are not changed
-	if currentObjFromServer.GetAnnotations()[constants.TraceIDAnnotation] != obj.GetAnnotations()[constants.TraceIDAnnotation] {
+	keys := tc.propagation.annotationKeys()
+	currentTraceID, currentSpanID, _ := traceIDs(currentObjFromServer, keys)
+	expectedTraceID, expectedSpanID, _ := traceIDs(obj, keys)
+	if currentTraceID != expectedTraceID {
 		tc.Logger.Info("TraceID has changed, skipping patch", "object", obj.GetName())
 		span.RecordError(fmt.Errorf("TraceID has changed, skipping patch: object %s", obj.GetName()))
-		return obj, nil
+		return nil
+	}
+	if currentSpanID != expectedSpanID {
+		tc.Logger.Info("SpanID has changed, skipping patch", "object", obj.GetName())
+		span.RecordError(fmt.Errorf("SpanID has changed, skipping patch: object %s", obj.GetName()))
+		return nil
 	}
 
-	// Remove the traceid and spanid annotations and create a patch
-	original := obj.DeepCopyObject().(client.Object)
-	patch := client.MergeFrom(original)
+	resourceVersion := currentObjFromServer.GetResourceVersion()
+	if tc.apiReader == nil && obj.GetResourceVersion() != "" {
+		resourceVersion = obj.GetResourceVersion()
+	}
+	return tc.endTrace(ctx, span, obj, resourceVersion, endTraceOpts, opts...)
+}
 
-	delete(annotations, constants.TraceIDAnnotation)
-	delete(annotations, constants.SpanIDAnnotation)
-	obj.SetAnnotations(annotations)
+// validationReader returns the reader of the object a trace is validated on, the API reader if the client has one
+func (tc *tracingClient) validationReader() client.Reader {
+	if tc.apiReader != nil {
+		return tc.apiReader
+	}
+	return tc.Reader
+}
 
-	tc.Logger.Info("Patching object", "object", obj.GetName())
-	// Use the Patch function to apply the patch
+// endTrace removes the trace from obj.  The cleanup patch is preconditioned on resourceVersion, the version the
+// trace was validated on, so that a trace written concurrently is never wiped; it then fails with a Conflict.
+func (tc *tracingClient) endTrace(ctx context.Context, span trace.Span, obj client.Object, resourceVersion string, endTraceOpts endTraceOptions, opts ...client.PatchOption) error {
+	// Remove the trace annotations and label with a targeted patch, the response updates obj.  A status which
+	// is part of the main resource is cleaned up by the same patch.
+	content := map[string]interface{}{"metadata": map[string]interface{}{}}
+	if !endTraceOpts.skipAnnotations {
+		content = traceMetadataRemoval(obj, tc.propagation.annotationKeys(), endTraceOpts.keepTraceID)
+	}
+	if resourceVersion != "" {
+		content["metadata"].(map[string]interface{})["resourceVersion"] = resourceVersion
+	}
+	var cleaned client.Object
+	if !endTraceOpts.skipConditions && tc.propagation.conditionStorage() && hasStatusTraceContext(obj, tc.scheme) {
+		cleaned = obj.DeepCopyObject().(client.Object)
+		deleteStatusTrace(cleaned, tc.scheme, endTraceOpts.keepTraceID)
+	}
+	gvk, gvkErr := apiutil.GVKForObject(obj, tc.scheme)
+	statusInMain := cleaned != nil && gvkErr == nil && !tc.statusSubresources.has(gvk)
+	if statusInMain {
+		if err := mergeStatusRemoval(content, obj, cleaned); err != nil {
+			tc.stackTraces.recordError(span, err)
+			return err
+		}
+	}
 
-	err = tc.Client.Patch(ctx, obj, patch, opts...)
+	if !endTraceOpts.skipAnnotations || statusInMain {
+		data, err := json.Marshal(content)
+		if err != nil {
+			err = fmt.Errorf("problem building the patch: %w", err)
+			tc.stackTraces.recordError(span, err)
+			return err
+		}
 
+		tc.Logger.Info("Patching object", "object", obj.GetName())
+		span.AddEvent("Patch metadata", trace.WithAttributes(EndTraceStatusInPatchKey.Bool(statusInMain)))
+		restoreGVK := keepMetadataGVK(obj)
+		err = tc.Client.Patch(ctx, obj, client.RawPatch(types.MergePatchType, data), opts...)
+		restoreGVK()
+		if err != nil {
+			tc.stackTraces.recordError(span, err)
+			return err
+		}
+	} else if resourceVersion != "" {
+		// the status patch is preconditioned on the validated version instead
+		obj.SetResourceVersion(resourceVersion)
+	}
+	if cleaned == nil || statusInMain {
+		return nil
+	}
+
+	// the status subresource is patched from the object just written, failing rather than overwriting the
+	// status if anything else wrote the object in the meantime
+	original := obj.DeepCopyObject().(client.Object)
+	deleteStatusTrace(obj, tc.scheme, endTraceOpts.keepTraceID)
+	tc.Logger.Info("Patching object status", "object", obj.GetName())
+	span.AddEvent("Patch status")
+	// the status writer of the TracingClient would write the conditions again
+	err := tc.Client.Status().Patch(ctx, obj, client.MergeFromWithOptions(original, client.MergeFromWithOptimisticLock{}))
 	if err != nil {
-		span.RecordError(err)
+		tc.stackTraces.recordError(span, err)
 	}
+	return err
+}
 
-	// remove the traceid and spanid conditions from the object and create a status().patch
-	deleteCondition("TraceID", obj, tc.scheme)
-	deleteCondition("SpanID", obj, tc.scheme)
-	original = obj.DeepCopyObject().(client.Object)
-	patch = client.MergeFrom(original)
+// newObjectLike returns an empty object of the type of obj to read into, without deep copying obj
+func (tc *tracingClient) newObjectLike(obj client.Object) client.Object {
+	if u, ok := obj.(*unstructured.Unstructured); ok {
+		newObj := &unstructured.Unstructured{}
+		newObj.SetGroupVersionKind(u.GroupVersionKind())
+		return newObj
+	}
+	if metadata, ok := obj.(*metav1.PartialObjectMetadata); ok {
+		// only read the metadata, as the caller does
+		newObj := &metav1.PartialObjectMetadata{}
+		newObj.SetGroupVersionKind(metadata.GroupVersionKind())
+		return newObj
+	}
 
-	tc.Logger.Info("Patching object status", "object", obj.GetName())
-	err = tc.Status().Patch(ctx, obj, patch)
+	gvk, err := apiutil.GVKForObject(obj, tc.scheme)
+	if err == nil {
+		if newObj, err := tc.scheme.New(gvk); err == nil {
+			if clientObj, ok := newObj.(client.Object); ok {
+				return clientObj
+			}
+		}
+	}
+	return obj.DeepCopyObject().(client.Object)
+}
 
-	if err != nil {
-		span.RecordError(err)
+// traceMetadataRemoval builds the merge patch content removing the annotations and the label written by the
+// TracingClient which are present on obj, except for the trace ID annotation and the label if keepTraceID
+func traceMetadataRemoval(obj client.Object, keys *annotationKeys, keepTraceID bool) map[string]interface{} {
+	removed := []string{
+		keys.spanID,
+		keys.traceRoot,
+		keys.traceRootKind,
+		keys.traceRootName,
+		keys.actor,
+		keys.traceTime,
+	}
+	for _, field := range otel.GetTextMapPropagator().Fields() {
+		removed = append(removed, keys.propagatorPrefix+field)
+	}
+	if !keepTraceID {
+		removed = append(removed, keys.traceID)
 	}
 
-	return obj, err
+	annotations := map[string]interface{}{}
+	for _, key := range removed {
+		if _, ok := obj.GetAnnotations()[key]; ok {
+			annotations[key] = nil
+		}
+	}
+	metadata := map[string]interface{}{"annotations": annotations}
+	labels := map[string]interface{}{}
+	if _, ok := obj.GetLabels()[constants.TraceLabel]; ok && !keepTraceID {
+		labels[constants.TraceLabel] = nil
+	}
+	// the IDs written by WithLabelPropagation
+	for _, key := range []string{keys.traceID, keys.spanID} {
+		if _, ok := obj.GetLabels()[key]; ok && (key != keys.traceID || !keepTraceID) {
+			labels[key] = nil
+		}
+	}
+	if len(labels) > 0 {
+		metadata["labels"] = labels
+	}
+
+	return map[string]interface{}{"metadata": metadata}
 }
 
 // Get adds tracing around the original client's Get method
 func (tc *tracingClient) Get(ctx context.Context, key client.ObjectKey, obj client.Object, opts ...client.GetOption) error {
-	// Create or retrieve the span from the context
 	gvk, err := apiutil.GVKForObject(obj, tc.scheme)
 	if err != nil {
 		return fmt.Errorf("problem getting the scheme: %w", err)
 	}
 
-	kind := gvk.GroupKind().Kind
+	return tc.get(ctx, gvk, key, obj, opts...)
+}
 
-	ctx, span := startSpanFromContext(ctx, tc.Logger, tc.Tracer, obj, tc.scheme, fmt.Sprintf("Get %s %s", kind, key.Name))
-	defer span.End()
+// get is Get for an object of the given kind
+func (tc *tracingClient) get(ctx context.Context, gvk schema.GroupVersionKind, key client.ObjectKey, obj client.Object, opts ...client.GetOption) error {
+	if tracingDisabled(ctx) || !tc.sampler.sample(tc.scheme, "get", obj, key) {
+		opts, readOpts := splitReadOptions(opts)
+		return tc.reader(noop.Span{}, readOpts).Get(ctx, key, obj, opts...)
+	}
+	kind := gvk.Kind
+	ctx, span := startSpanFromContext(ctx, tc.Logger, tc.Tracer, obj, tc.scheme, tc.propagation, tc.spanNames.spanName("Get", gvk, key))
+	defer endSpan(ctx, span, tc.onSpanEnd, "get", kind, obj)
+	span.SetAttributes(keyAttributes(key)...)
 
 	tc.Logger.Info("Getting object", "object", key.Name)
 
-	err = tc.Client.Get(ctx, key, obj, opts...)
+	opts, readOpts := splitReadOptions(opts)
+	err := tc.reader(span, readOpts).Get(ctx, key, obj, opts...)
 
 	if err != nil {
-		span.RecordError(err)
+		tc.stackTraces.recordError(span, err)
 	}
 
 	return err
 }
 
 func (tc *tracingClient) List(ctx context.Context, list client.ObjectList, opts ...client.ListOption) error {
+	namespace := (&client.ListOptions{}).ApplyOptions(opts).Namespace
+	if tracingDisabled(ctx) || !tc.sampler.sample(tc.scheme, "list", list, client.ObjectKey{Namespace: namespace}) {
+		opts, readOpts := splitReadOptions(opts)
+		return tc.reader(noop.Span{}, readOpts).List(ctx, list, opts...)
+	}
 	gvk, _ := apiutil.GVKForObject(list, tc.scheme)
 	kind := gvk.GroupKind().Kind
-	ctx, span := startSpanFromContextList(ctx, tc.Logger, tc.Tracer, list, kind)
-	defer span.End()
+	operationName := kind
+	if tc.spanNames != nil {
+		itemGVK := gvk.GroupVersion().WithKind(strings.TrimSuffix(gvk.Kind, "List"))
+		operationName = tc.spanNames("List", itemGVK, client.ObjectKey{Namespace: namespace})
+	}
+	ctx, span := startSpanFromContextList(ctx, tc.Logger, tc.Tracer, list, operationName)
+	defer endSpan(ctx, span, tc.onSpanEnd, "list", kind, nil)
 
 	tc.Logger.Info("Getting List", "object", kind)
-	err := tc.Client.List(ctx, list, opts...)
+	opts, readOpts := splitReadOptions(opts)
+	err := tc.reader(span, readOpts).List(ctx, list, opts...)
 	if err != nil {
-		span.RecordError(err)
+		tc.stackTraces.recordError(span, err)
+		return err
 	}
-	return err
+	tc.linkListItems(span, list)
+	if readOpts.perItemSpans {
+		tc.recordListItemSpans(ctx, gvk.GroupVersion().WithKind(strings.TrimSuffix(gvk.Kind, "List")), list)
+	}
+	return nil
 }
 
 // Patch  adds tracing and traceID annotation around the original client's Patch method
@@ -270,16 +725,36 @@ func (tc *tracingClient) Patch(ctx context.Context, obj client.Object, patch cli
 		return fmt.Errorf("problem getting the scheme: %w", err)
 	}
 
-	kind := gvk.GroupKind().Kind
+	return tc.patch(ctx, gvk, obj, patch, opts...)
+}
 
-	ctx, span := startSpanFromContext(ctx, tc.Logger, tc.Tracer, obj, tc.scheme, fmt.Sprintf("Patch %s %s", kind, obj.GetName()))
-	defer span.End()
+// patch is Patch for an object of the given kind
+func (tc *tracingClient) patch(ctx context.Context, gvk schema.GroupVersionKind, obj client.Object, patch client.Patch, opts ...client.PatchOption) error {
+	if tracingDisabled(ctx) || !tc.sampler.sample(tc.scheme, "patch", obj, client.ObjectKeyFromObject(obj)) {
+		return tc.Client.Patch(ctx, obj, patch, untraced(opts)...)
+	}
+	kind := gvk.Kind
+	parent := trace.SpanFromContext(ctx)
+	opts, spanOpts := splitSpanOptions(opts)
+	ctx, span := startSpanFromContext(ctx, tc.Logger, tc.Tracer, obj, tc.scheme, tc.propagation, mutationSpanName(ctx, tc.spanNames, "Patch", gvk, client.ObjectKeyFromObject(obj)), spanOpts...)
+	defer endSpan(ctx, span, tc.onSpanEnd, "patch", kind, obj)
 
-	addTraceIDAnnotation(ctx, obj)
+	opts, writeOpts := splitWriteOptions(opts)
+	writeOpts.dryRun = recordDryRun(span, opts)
+	if isApply(patch) {
+		return tc.apply(ctx, parent, span, kind, obj, patch, writeOpts, opts...)
+	}
+
+	written := tc.writeTarget(obj)
+	tc.writeTraceMetadata(ctx, "patch", written, writeOpts)
+	recordPatch(span, patch, written)
 	tc.Logger.Info("Patching object", "object", obj.GetName())
-	err = tc.Client.Patch(ctx, obj, patch, opts...)
+	defer keepMetadataGVK(written)()
+	err := tc.Client.Patch(ctx, written, patch, opts...)
+	syncWriteResult(obj, written)
 	if err != nil {
-		span.RecordError(err)
+		tc.stackTraces.recordError(span, err)
+		tc.recordConflict(ctx, parent, span, "Patch", kind, obj, err)
 	}
 
 	return err
@@ -292,20 +767,35 @@ func (tc *tracingClient) Delete(ctx context.Context, obj client.Object, opts ...
 		return fmt.Errorf("problem getting the scheme: %w", err)
 	}
 
-	kind := gvk.GroupKind().Kind
+	return tc.delete(ctx, gvk, obj, opts...)
+}
 
-	ctx, span := startSpanFromContext(ctx, tc.Logger, tc.Tracer, obj, tc.scheme, fmt.Sprintf("Delete %s %s", kind, obj.GetName()))
-	defer span.End()
+// delete is Delete for an object of the given kind
+func (tc *tracingClient) delete(ctx context.Context, gvk schema.GroupVersionKind, obj client.Object, opts ...client.DeleteOption) error {
+	if tracingDisabled(ctx) || !tc.sampler.sample(tc.scheme, "delete", obj, client.ObjectKeyFromObject(obj)) {
+		return tc.Client.Delete(ctx, obj, untraced(opts)...)
+	}
+	kind := gvk.Kind
+	opts, spanOpts := splitSpanOptions(opts)
+	ctx, span := startSpanFromContext(ctx, tc.Logger, tc.Tracer, obj, tc.scheme, tc.propagation, mutationSpanName(ctx, tc.spanNames, "Delete", gvk, client.ObjectKeyFromObject(obj)), spanOpts...)
+	defer endSpan(ctx, span, tc.onSpanEnd, "delete", kind, obj)
 
+	recordDryRun(span, opts)
 	tc.Logger.Info("Deleting object", "object", obj.GetName())
-	err = tc.Client.Delete(ctx, obj, opts...)
+	err := tc.Client.Delete(ctx, obj, opts...)
 	if err != nil {
-		span.RecordError(err)
+		tc.stackTraces.recordError(span, err)
 	}
 	return err
 }
 
 func (tc *tracingClient) DeleteAllOf(ctx context.Context, obj client.Object, opts ...client.DeleteAllOfOption) error {
+	opts, count := splitAffectedCount(opts)
+	deleteOptions := (&client.DeleteAllOfOptions{}).ApplyOptions(opts)
+	namespace := deleteOptions.Namespace
+	if tracingDisabled(ctx) || !tc.sampler.sample(tc.scheme, "deleteallof", obj, client.ObjectKey{Namespace: namespace}) {
+		return tc.Client.DeleteAllOf(ctx, obj, opts...)
+	}
 	gvk, err := apiutil.GVKForObject(obj, tc.scheme)
 	if err != nil {
 		return fmt.Errorf("problem getting the scheme: %w", err)
@@ -313,28 +803,77 @@ func (tc *tracingClient) DeleteAllOf(ctx context.Context, obj client.Object, opt
 
 	kind := gvk.GroupKind().Kind
 
-	ctx, span := startSpanFromContext(ctx, tc.Logger, tc.Tracer, obj, tc.scheme, fmt.Sprintf("DeleteAllOf %s %s", kind, obj.GetName()))
-	defer span.End()
+	ctx, span := startSpanFromContext(ctx, tc.Logger, tc.Tracer, obj, tc.scheme, tc.propagation, tc.spanNames.spanName("DeleteAllOf", gvk, client.ObjectKeyFromObject(obj)))
+	defer endSpan(ctx, span, tc.onSpanEnd, "deleteallof", kind, obj)
+	span.SetAttributes(selectorAttributes(deleteOptions.ListOptions)...)
+	recordDryRun(span, opts)
+	if count {
+		tc.recordAffectedCount(ctx, span, gvk, deleteOptions.ListOptions)
+	}
 
 	tc.Logger.Info("Deleting all of object", "object", obj.GetName())
 	err = tc.Client.DeleteAllOf(ctx, obj, opts...)
 	if err != nil {
-		span.RecordError(err)
+		tc.stackTraces.recordError(span, err)
 	}
 	return err
 
 }
 
+// selectorAttributes returns the namespace and selectors of opts as span attributes, leaving out the unset ones
+func selectorAttributes(opts client.ListOptions) []attribute.KeyValue {
+	var attrs []attribute.KeyValue
+	if opts.Namespace != "" {
+		attrs = append(attrs, K8sNamespaceNameKey.String(opts.Namespace))
+	}
+	if opts.LabelSelector != nil && !opts.LabelSelector.Empty() {
+		attrs = append(attrs, LabelSelectorKey.String(opts.LabelSelector.String()))
+	}
+	if opts.FieldSelector != nil && !opts.FieldSelector.Empty() {
+		attrs = append(attrs, FieldSelectorKey.String(opts.FieldSelector.String()))
+	}
+	return attrs
+}
+
+// recordAffectedCount records on span the number of objects of kind gvk matching opts, nothing if they cannot
+// be listed
+func (tc *tracingClient) recordAffectedCount(ctx context.Context, span trace.Span, gvk schema.GroupVersionKind, opts client.ListOptions) {
+	obj, err := tc.scheme.New(gvk.GroupVersion().WithKind(gvk.Kind + "List"))
+	if err != nil {
+		return
+	}
+	list, ok := obj.(client.ObjectList)
+	if !ok {
+		return
+	}
+	if err := tc.Client.List(ctx, list, &opts); err != nil {
+		tc.Logger.Error(err, "Problem counting the objects to delete", "object", gvk.Kind)
+		return
+	}
+	span.SetAttributes(AffectedCountKey.Int(meta.LenList(list)))
+}
+
 func (tc *tracingClient) Status() client.StatusWriter {
 	return &tracingStatusClient{
 		scheme:       tc.scheme,
 		Logger:       tc.Logger,
 		StatusWriter: tc.Client.Status(),
 		Tracer:       tc.Tracer,
+		onSpanEnd:    tc.onSpanEnd,
+		stackTraces:  tc.stackTraces,
+		propagation:  tc.propagation,
+		policy:       tc.policy,
+		sampler:      tc.sampler,
+		spanNames:    tc.spanNames,
+
+		missingConditions: tc.missingConditions,
 	}
 }
 
 func (ts *tracingStatusClient) Update(ctx context.Context, obj client.Object, opts ...client.SubResourceUpdateOption) error {
+	if tracingDisabled(ctx) || !ts.sampler.sample(ts.scheme, "status-update", obj, client.ObjectKeyFromObject(obj)) {
+		return ts.StatusWriter.Update(ctx, obj, opts...)
+	}
 	gvk, err := apiutil.GVKForObject(obj, ts.scheme)
 	if err != nil {
 		return fmt.Errorf("problem getting the scheme: %w", err)
@@ -342,21 +881,26 @@ func (ts *tracingStatusClient) Update(ctx context.Context, obj client.Object, op
 
 	kind := gvk.GroupKind().Kind
 
-	ctx, span := startSpanFromContext(ctx, ts.Logger, ts.Tracer, obj, ts.scheme, fmt.Sprintf("StatusUpdate %s %s", kind, obj.GetName()))
-	defer span.End()
+	ctx, span := startSpanFromContext(ctx, ts.Logger, ts.Tracer, obj, ts.scheme, ts.propagation, ts.spanNames.spanName("StatusUpdate", gvk, client.ObjectKeyFromObject(obj)))
+	defer endSpan(ctx, span, ts.onSpanEnd, "status-update", kind, obj)
 
-	setConditionMessage("TraceID", span.SpanContext().TraceID().String(), obj, ts.scheme)
-	setConditionMessage("SpanID", span.SpanContext().SpanID().String(), obj, ts.scheme)
+	if err := ts.setTraceContext(span, obj, recordDryRun(span, opts)); err != nil {
+		ts.stackTraces.recordError(span, err)
+		return err
+	}
 
 	ts.Logger.Info("updating status object", "object", obj.GetName())
 	err = ts.StatusWriter.Update(ctx, obj, opts...)
 	if err != nil {
-		span.RecordError(err)
+		ts.stackTraces.recordError(span, err)
 	}
 	return err
 }
 
 func (ts *tracingStatusClient) Patch(ctx context.Context, obj client.Object, patch client.Patch, opts ...client.SubResourcePatchOption) error {
+	if tracingDisabled(ctx) || !ts.sampler.sample(ts.scheme, "status-patch", obj, client.ObjectKeyFromObject(obj)) {
+		return ts.StatusWriter.Patch(ctx, obj, patch, opts...)
+	}
 	gvk, err := apiutil.GVKForObject(obj, ts.scheme)
 	if err != nil {
 		return fmt.Errorf("problem getting the scheme: %w", err)
@@ -364,22 +908,32 @@ func (ts *tracingStatusClient) Patch(ctx context.Context, obj client.Object, pat
 
 	kind := gvk.GroupKind().Kind
 
-	ctx, span := startSpanFromContext(ctx, ts.Logger, ts.Tracer, obj, ts.scheme, fmt.Sprintf("StatusPatch %s %s", kind, obj.GetName()))
-	defer span.End()
+	ctx, span := startSpanFromContext(ctx, ts.Logger, ts.Tracer, obj, ts.scheme, ts.propagation, ts.spanNames.spanName("StatusPatch", gvk, client.ObjectKeyFromObject(obj)))
+	defer endSpan(ctx, span, ts.onSpanEnd, "status-patch", kind, obj)
 
-	setConditionMessage("TraceID", span.SpanContext().TraceID().String(), obj, ts.scheme)
-	setConditionMessage("SpanID", span.SpanContext().SpanID().String(), obj, ts.scheme)
+	dryRun := recordDryRun(span, opts)
+	// the trace conditions would be owned by the field manager of an apply, and removed by its next apply
+	if !isApply(patch) {
+		if err := ts.setTraceContext(span, obj, dryRun); err != nil {
+			ts.stackTraces.recordError(span, err)
+			return err
+		}
+	}
+	recordPatch(span, patch, obj)
 
 	ts.Logger.Info("patching status object", "object", obj.GetName())
 	err = ts.StatusWriter.Patch(ctx, obj, patch, opts...)
 	if err != nil {
-		span.RecordError(err)
+		ts.stackTraces.recordError(span, err)
 	}
 
 	return err
 }
 
 func (ts *tracingStatusClient) Create(ctx context.Context, obj client.Object, subResource client.Object, opts ...client.SubResourceCreateOption) error {
+	if tracingDisabled(ctx) || !ts.sampler.sample(ts.scheme, "status-create", obj, client.ObjectKeyFromObject(obj)) {
+		return ts.StatusWriter.Create(ctx, obj, subResource, opts...)
+	}
 	gvk, err := apiutil.GVKForObject(obj, ts.scheme)
 	if err != nil {
 		return fmt.Errorf("problem getting the scheme: %w", err)
@@ -387,22 +941,34 @@ func (ts *tracingStatusClient) Create(ctx context.Context, obj client.Object, su
 
 	kind := gvk.GroupKind().Kind
 
-	ctx, span := startSpanFromContext(ctx, ts.Logger, ts.Tracer, obj, ts.scheme, fmt.Sprintf("StatusCreate %s %s", kind, obj.GetName()))
-	defer span.End()
+	ctx, span := startSpanFromContext(ctx, ts.Logger, ts.Tracer, obj, ts.scheme, ts.propagation, ts.spanNames.spanName("StatusCreate", gvk, client.ObjectKeyFromObject(obj)))
+	defer endSpan(ctx, span, ts.onSpanEnd, "status-create", kind, obj)
 
-	setConditionMessage("TraceID", span.SpanContext().TraceID().String(), obj, ts.scheme)
-	setConditionMessage("SpanID", span.SpanContext().SpanID().String(), obj, ts.scheme)
+	if err := ts.setTraceContext(span, obj, recordDryRun(span, opts)); err != nil {
+		ts.stackTraces.recordError(span, err)
+		return err
+	}
 
 	ts.Logger.Info("creating status object", "object", obj.GetName())
 	err = ts.StatusWriter.Create(ctx, obj, subResource, opts...)
 	if err != nil {
-		span.RecordError(err)
+		ts.stackTraces.recordError(span, err)
 	}
 	return err
 }
 
+// setTraceContext stores the trace of span in the status of obj, if the status stores it, the policy writes it to
+// obj and the write is not a dry run
+func (ts *tracingStatusClient) setTraceContext(span trace.Span, obj client.Object, dryRun bool) error {
+	if dryRun || !ts.propagation.conditionStorage() || !ts.policy.writes(obj) {
+		return nil
+	}
+	return ts.missingConditions.setStatusTraceContext(span.SpanContext(), obj, ts.scheme, ts.propagation)
+}
+
 // startSpanFromContext starts a new span from the context and attaches trace information to the object
-func startSpanFromContext(ctx context.Context, logger logr.Logger, tracer trace.Tracer, obj client.Object, scheme *runtime.Scheme, operationName string) (context.Context, trace.Span) {
+func startSpanFromContext(ctx context.Context, logger logr.Logger, tracer trace.Tracer, obj client.Object, scheme *runtime.Scheme, prop *tracePropagation, operationName string, spanOpts ...trace.SpanStartOption) (context.Context, trace.Span) {
+	spanOpts = append(actorSpanOptions(ctx), spanOpts...)
 	span := trace.SpanFromContext(ctx)
 	if span.SpanContext().IsValid() {
 		spanContext := trace.NewSpanContext(trace.SpanContextConfig{
@@ -410,58 +976,17 @@ func startSpanFromContext(ctx context.Context, logger logr.Logger, tracer trace.
 			SpanID:  span.SpanContext().SpanID(),
 		})
 		ctx = trace.ContextWithRemoteSpanContext(ctx, spanContext)
-		ctx, span = tracer.Start(ctx, operationName)
+		ctx, span = tracer.Start(ctx, operationName, spanOpts...)
 		return ctx, span
 	}
 
-	if !span.SpanContext().IsValid() {
-		if obj != nil {
-			// no valid trace ID in context, check object conditions
-			if traceID, err := getConditionMessage("TraceID", obj, scheme); err == nil {
-				if traceIDValue, err := trace.TraceIDFromHex(traceID); err == nil {
-					spanContext := trace.NewSpanContext(trace.SpanContextConfig{})
-					if spanID, err := getConditionMessage("SpanID", obj, scheme); err == nil {
-						if spanIDValue, err := trace.SpanIDFromHex(spanID); err == nil {
-							spanContext = trace.NewSpanContext(trace.SpanContextConfig{
-								TraceID: traceIDValue,
-								SpanID:  spanIDValue,
-							})
-						} else {
-							spanContext = trace.NewSpanContext(trace.SpanContextConfig{
-								TraceID: traceIDValue,
-							})
-						}
-					}
-					ctx = trace.ContextWithRemoteSpanContext(ctx, spanContext)
-				}
-			} else {
-				// No valid trace ID in context, check object annotations
-				if traceID, ok := obj.GetAnnotations()[constants.TraceIDAnnotation]; ok {
-					if traceIDValue, err := trace.TraceIDFromHex(traceID); err == nil {
-						spanContext := trace.NewSpanContext(trace.SpanContextConfig{})
-						if spanID, ok := obj.GetAnnotations()[constants.SpanIDAnnotation]; ok {
-							if spanIDValue, err := trace.SpanIDFromHex(spanID); err == nil {
-								spanContext = trace.NewSpanContext(trace.SpanContextConfig{
-									TraceID: traceIDValue,
-									SpanID:  spanIDValue,
-								})
-							} else {
-								spanContext = trace.NewSpanContext(trace.SpanContextConfig{
-									TraceID: traceIDValue,
-								})
-							}
-						}
-						ctx = trace.ContextWithRemoteSpanContext(ctx, spanContext)
-					} else {
-						logger.Error(err, "Invalid trace ID", "traceID", traceID)
-					}
-				}
-			}
-		}
+	if obj != nil {
+		// no valid trace ID in context, check object conditions and annotations
+		ctx = prop.extract(ctx, logger, obj, scheme)
 	}
 
 	// Create a new span
-	ctx, span = tracer.Start(ctx, operationName)
+	ctx, span = tracer.Start(ctx, operationName, spanOpts...)
 	return ctx, span
 }
 
@@ -499,7 +1024,7 @@ func getNameFromNamespacedName(key client.ObjectKey) string {
 // if the key.Name looks like this: f620f5cad0af940c294f980c5366a6a1;45f359cdc1c8ab06;Configmap;pod-configmap01;default-pod
 // then we can extract the traceID and spanID from the key.Name
 // and override the traceID and spanID in the object annotations
-func overrideTraceIDFromNamespacedName(key client.ObjectKey, obj client.Object) error {
+func overrideTraceIDFromNamespacedName(key client.ObjectKey, obj client.Object, keys *annotationKeys) error {
 	keyNameParts := strings.Split(key.Name, ";")
 	if len(keyNameParts) != 5 {
 		return nil
@@ -512,9 +1037,11 @@ func overrideTraceIDFromNamespacedName(key client.ObjectKey, obj client.Object)
 		obj.SetAnnotations(map[string]string{})
 	}
 	annotations := obj.GetAnnotations()
-	annotations[constants.TraceIDAnnotation] = traceID
-	annotations[constants.SpanIDAnnotation] = spanID
+	annotations[keys.traceID] = traceID
+	annotations[keys.spanID] = spanID
 	obj.SetAnnotations(annotations)
+	// the trace embedded in the request is as recent as the event
+	stampTraceTime(obj, keys)
 	return nil
 }
 
@@ -526,75 +1053,91 @@ func startSpanFromContextList(ctx context.Context, logger logr.Logger, tracer tr
 			SpanID:  span.SpanContext().SpanID(),
 		})
 		ctx = trace.ContextWithRemoteSpanContext(ctx, spanContext)
-		ctx, span = tracer.Start(ctx, operationName)
+		ctx, span = tracer.Start(ctx, operationName, actorSpanOptions(ctx)...)
 		return trace.ContextWithSpan(ctx, span), span
 	}
 
 	// Create a new span
-	ctx, span = tracer.Start(ctx, operationName)
+	ctx, span = tracer.Start(ctx, operationName, actorSpanOptions(ctx)...)
 	return ctx, span
 }
 
-// addTraceIDAnnotation adds the traceID as an annotation to the object
-func addTraceIDAnnotation(ctx context.Context, obj client.Object) {
-	span := trace.SpanFromContext(ctx)
-	traceID := span.SpanContext().TraceID().String()
-	if traceID != "" {
-		if obj.GetAnnotations() == nil {
-			obj.SetAnnotations(map[string]string{})
+// addTraceIDAnnotation adds the traceID and spanID as annotations to the object.  Nothing is written when the
+// span context is invalid, e.g. with a noop tracer, as all-zero IDs are meaningless, or when the object already
+// got the trace earlier in the reconcile.
+func addTraceIDAnnotation(ctx context.Context, obj client.Object, prop *tracePropagation) {
+	spanContext := trace.SpanContextFromContext(ctx)
+	keys := prop.annotationKeys()
+	if !spanContext.IsValid() || alreadyInjected(ctx, spanContext, obj, keys) {
+		return
+	}
+
+	if prop.writes(IDAnnotationsFormat) {
+		if prop.labelPropagation() != LabelsOnly {
+			injectSpanContext(spanContext, obj, keys)
 		}
-		annotations := obj.GetAnnotations()
-		annotations[constants.TraceIDAnnotation] = traceID
-		obj.SetAnnotations(annotations)
-	}
-	spanID := span.SpanContext().SpanID().String()
-	if spanID != "" {
-		if obj.GetAnnotations() == nil {
-			obj.SetAnnotations(map[string]string{})
+		if prop.labelPropagation() != NoLabels {
+			injectSpanContextLabels(spanContext, obj, keys)
 		}
-		annotations := obj.GetAnnotations()
-		annotations[constants.SpanIDAnnotation] = spanID
-		obj.SetAnnotations(annotations)
 	}
-}
-
-// getConditions retrieves the "conditions" field from the status of a Kubernetes object using type casting and returns it as []metav1.Condition.
-func getConditions(obj client.Object, scheme *runtime.Scheme) ([]metav1.Condition, error) {
-	gvk, err := apiutil.GVKForObject(obj, scheme)
-	if err != nil {
-		return nil, fmt.Errorf("problem getting the GVK: %w", err)
+	addTraceRootAnnotations(ctx, obj, keys)
+	addActorAnnotation(ctx, obj, keys)
+	if prop.writes(PropagatorFormat) {
+		otel.GetTextMapPropagator().Inject(ctx, ObjectCarrier{obj: obj, prefix: keys.propagatorPrefix})
+		if !prop.writes(IDAnnotationsFormat) {
+			stampTraceTime(obj, keys)
+		}
 	}
+}
 
-	// Use the scheme to get the specific type of the object.
-	objTyped, err := scheme.New(gvk)
-	if err != nil {
-		return nil, fmt.Errorf("problem creating new object of kind %s: %w", gvk.Kind, err)
+// addTraceLabel mirrors the traceID into the trace label of the object.  A trace ID is 32 hex characters
+// which fits the 63 characters of a label value as is.
+func addTraceLabel(ctx context.Context, obj client.Object) {
+	spanContext := trace.SpanContextFromContext(ctx)
+	if !spanContext.HasTraceID() {
+		return
 	}
 
-	// Cast the object to its specific type.
-	if err := scheme.Convert(obj, objTyped, nil); err != nil {
-		return nil, fmt.Errorf("problem converting object to kind %s: %w", gvk.Kind, err)
+	if obj.GetLabels() == nil {
+		obj.SetLabels(map[string]string{})
 	}
+	labels := obj.GetLabels()
+	labels[constants.TraceLabel] = spanContext.TraceID().String()
+	obj.SetLabels(labels)
+}
 
-	// Use reflection to access the conditions field.
-	val := reflect.ValueOf(objTyped)
-	statusField := val.Elem().FieldByName("Status")
-	if !statusField.IsValid() {
-		return nil, fmt.Errorf("status field not found in kind %s", gvk.Kind)
+// addTraceparentData writes the W3C traceparent of the span in ctx into the TraceparentDataKey of a
+// ConfigMap or Secret
+func addTraceparentData(ctx context.Context, obj client.Object) {
+	spanContext := trace.SpanContextFromContext(ctx)
+	if !spanContext.IsValid() {
+		return
 	}
 
-	conditionsField := statusField.FieldByName("Conditions")
-	if !conditionsField.IsValid() {
-		return nil, fmt.Errorf("conditions field not found in kind %s", gvk.Kind)
+	traceparent := traceparentOf(spanContext)
+	switch o := obj.(type) {
+	case *corev1.ConfigMap:
+		if o.Data == nil {
+			o.Data = map[string]string{}
+		}
+		o.Data[constants.TraceparentDataKey] = traceparent
+	case *corev1.Secret:
+		if o.Data == nil {
+			o.Data = map[string][]byte{}
+		}
+		o.Data[constants.TraceparentDataKey] = []byte(traceparent)
 	}
+}
 
-	conditionsValue := conditionsField.Interface()
-	conditions, err := convertToMetaV1Conditions(conditionsValue)
-	if err != nil {
-		return nil, fmt.Errorf("error converting conditions for kind %s: %w", gvk.Kind, err)
-	}
+// traceparentOf formats spanContext as a W3C traceparent
+func traceparentOf(spanContext trace.SpanContext) string {
+	return fmt.Sprintf("00-%s-%s-%s", spanContext.TraceID(), spanContext.SpanID(), spanContext.TraceFlags())
+}
 
-	return conditions, nil
+// getConditions retrieves the "conditions" field from the status of a Kubernetes object using its ConditionsAccessor,
+// and returns it as []metav1.Condition.
+func getConditions(obj client.Object, scheme *runtime.Scheme) ([]metav1.Condition, error) {
+	return conditionsAccessorOf(obj, scheme).GetConditions(obj)
 }
 
 // getConditionMessage retrieves the message for a specific condition type from a Kubernetes object.
@@ -604,10 +1147,8 @@ func getConditionMessage(conditionType string, obj client.Object, scheme *runtim
 		return "", err
 	}
 
-	for _, condition := range conditions {
-		if condition.Type == conditionType {
-			return condition.Message, nil
-		}
+	if condition := meta.FindStatusCondition(conditions, conditionType); condition != nil {
+		return condition.Message, nil
 	}
 
 	return "", fmt.Errorf("condition of type %s not found", conditionType)
@@ -641,6 +1182,10 @@ func convertToMetaV1Conditions(conditionsValue interface{}) ([]metav1.Condition,
 				condition.Message = fieldValue.String()
 			case "LastTransitionTime":
 				condition.LastTransitionTime = fieldValue.Interface().(metav1.Time)
+			case "ObservedGeneration":
+				if fieldValue.Kind() == reflect.Int64 {
+					condition.ObservedGeneration = fieldValue.Int()
+				}
 			}
 		}
 		metav1Conditions = append(metav1Conditions, condition)
@@ -677,6 +1222,10 @@ func convertFromMetaV1(conditions []metav1.Condition, targetType reflect.Type) (
 				fieldValue.SetString(cond.Message)
 			case "LastTransitionTime":
 				fieldValue.Set(reflect.ValueOf(cond.LastTransitionTime))
+			case "ObservedGeneration":
+				if fieldValue.Kind() == reflect.Int64 {
+					fieldValue.SetInt(cond.ObservedGeneration)
+				}
 			}
 		}
 
@@ -690,87 +1239,84 @@ func convertFromMetaV1(conditions []metav1.Condition, targetType reflect.Type) (
 	return result.Interface(), nil
 }
 
-// setConditions sets the "conditions" field in the status of a Kubernetes object using type casting.
+// setConditions sets the "conditions" field in the status of a Kubernetes object using its ConditionsAccessor.
 func setConditions(obj client.Object, conditions []metav1.Condition, scheme *runtime.Scheme) error {
-	gvk, err := apiutil.GVKForObject(obj, scheme)
-	if err != nil {
-		return fmt.Errorf("problem getting the GVK: %w", err)
-	}
-
-	// Use the scheme to get the specific type of the object.
-	objTyped, err := scheme.New(gvk)
-	if err != nil {
-		return fmt.Errorf("problem creating new object of kind %s: %w", gvk.Kind, err)
-	}
-
-	// Cast the object to its specific type.
-	if err := scheme.Convert(obj, objTyped, nil); err != nil {
-		return fmt.Errorf("problem converting object to kind %s: %w", gvk.Kind, err)
-	}
-
-	// Use reflection to set the conditions field.
-	val := reflect.ValueOf(objTyped)
-	statusField := val.Elem().FieldByName("Status")
-	if !statusField.IsValid() {
-		return fmt.Errorf("status field not found in kind %s", gvk.Kind)
-	}
-
-	conditionsField := statusField.FieldByName("Conditions")
-	if !conditionsField.IsValid() {
-		return fmt.Errorf("conditions field not found in kind %s", gvk.Kind)
-	}
-
-	convertedConditions, err := convertFromMetaV1(conditions, conditionsField.Type())
-	if err != nil {
-		return fmt.Errorf("error converting conditions for kind %s: %w", gvk.Kind, err)
-	}
-
-	conditionsField.Set(reflect.ValueOf(convertedConditions))
-
-	// Convert the typed object back to the unstructured object.
-	if err := scheme.Convert(objTyped, obj, nil); err != nil {
-		return fmt.Errorf("problem converting object back to unstructured: %w", err)
-	}
-
-	return nil
+	return conditionsAccessorOf(obj, scheme).SetConditions(obj, conditions)
 }
 
 // setConditionMessage sets the message for a specific condition type in a Kubernetes object.
 func setConditionMessage(conditionType, message string, obj client.Object, scheme *runtime.Scheme) error {
-	// this prevents any accidental duplicates
-	deleteCondition(conditionType, obj, scheme)
+	return setConditionMessages([]conditionMessage{{conditionType: conditionType, message: message}}, obj, scheme)
+}
+
+// conditionMessage is the message of a condition type to set with setConditionMessages
+type conditionMessage struct {
+	conditionType string
+	message       string
+}
 
+// setConditionMessages sets the messages of several condition types in a Kubernetes object, reading and
+// writing the conditions once instead of once per condition.  The conditions are set as meta.SetStatusCondition
+// does, with status True, the TraceConditionReason and the generation of obj, so that they pass the validation
+// of CRDs using metav1.Condition; their lastTransitionTime is kept when they already existed.  The conditions of
+// the removed types are removed in the same pass.
+func setConditionMessages(messages []conditionMessage, obj client.Object, scheme *runtime.Scheme, removed ...string) error {
 	conditions, err := getConditions(obj, scheme)
 	if err != nil {
 		return err
 	}
+	conditions = removeConditions(conditions, removed...)
 
-	// Add the condition if it doesn't exist
-	newCondition := metav1.Condition{
-		Type:               conditionType,
-		Status:             metav1.ConditionUnknown,
-		LastTransitionTime: metav1.Now(),
-		Message:            message,
+	for _, m := range messages {
+		// this prevents any accidental duplicates, keeping the first condition of the type
+		existing := meta.FindStatusCondition(conditions, m.conditionType)
+		var kept *metav1.Condition
+		if existing != nil {
+			kept = existing.DeepCopy()
+		}
+		conditions = removeConditions(conditions, m.conditionType)
+		if kept != nil {
+			conditions = append(conditions, *kept)
+		}
+
+		meta.SetStatusCondition(&conditions, metav1.Condition{
+			Type:               m.conditionType,
+			Status:             metav1.ConditionTrue,
+			Reason:             TraceConditionReason,
+			Message:            m.message,
+			ObservedGeneration: obj.GetGeneration(),
+		})
 	}
-	conditions = append(conditions, newCondition)
 
 	// Set the updated conditions back to the object
 	return setConditions(obj, conditions, scheme)
 }
 
+// deleteCondition removes a condition type from a Kubernetes object
 func deleteCondition(conditionType string, obj client.Object, scheme *runtime.Scheme) error {
+	return deleteConditions(obj, scheme, conditionType)
+}
+
+// deleteConditions removes several condition types from a Kubernetes object in one pass
+func deleteConditions(obj client.Object, scheme *runtime.Scheme, conditionTypes ...string) error {
 	conditions, err := getConditions(obj, scheme)
 	if err != nil {
 		return err
 	}
 
-	outConditions := []metav1.Condition{}
-	for _, condition := range conditions {
-		if condition.Type != conditionType {
-			outConditions = append(outConditions, condition)
+	// Set the updated conditions back to the object
+	return setConditions(obj, removeConditions(conditions, conditionTypes...), scheme)
+}
+
+// removeConditions returns the conditions which are not of one of conditionTypes, keeping the order of the others.
+// It works on a copy, so the backing array of conditions, which may still be referenced by the object, is left
+// untouched; meta.RemoveStatusCondition only removes the first condition of a type, so it is repeated to drop
+// duplicates too.
+func removeConditions(conditions []metav1.Condition, conditionTypes ...string) []metav1.Condition {
+	outConditions := append([]metav1.Condition{}, conditions...)
+	for _, conditionType := range conditionTypes {
+		for meta.RemoveStatusCondition(&outConditions, conditionType) {
 		}
 	}
-
-	// Set the updated conditions back to the object
-	return setConditions(obj, outConditions, scheme)
+	return outConditions
 }
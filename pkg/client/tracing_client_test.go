@@ -2,17 +2,25 @@ package client
 
 import (
 	"context"
+	"fmt"
+	"sync"
 	"testing"
+	"time"
 
 	"github.com/go-logr/logr"
 	"github.com/go-logr/logr/testr"
 	"github.com/kubetracer/kubetracer-go/pkg/constants"
 	"github.com/stretchr/testify/assert"
 	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
 	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	"go.opentelemetry.io/otel/propagation"
 	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
 	"go.opentelemetry.io/otel/trace"
+	"go.opentelemetry.io/otel/trace/noop"
 	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
@@ -53,6 +61,26 @@ func TestNewTracingClient(t *testing.T) {
 	assert.NotNil(t, tracingClient)
 }
 
+func TestNewTracingClientWithoutReader(t *testing.T) {
+	// Create a fake Kubernetes client
+	k8sClient := fake.NewClientBuilder().WithObjects(&corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "pre-test-pod",
+			Namespace: "default",
+		},
+	}).Build()
+
+	// Initialize the TracingClient without a Reader
+	tracingClient := NewTracingClient(k8sClient, nil, initTracer(), logr.Discard())
+
+	pod := &corev1.Pod{}
+	_, span, err := tracingClient.StartTrace(context.Background(), client.ObjectKey{Name: "pre-test-pod", Namespace: "default"}, pod)
+	defer span.End()
+
+	assert.NoError(t, err)
+	assert.Equal(t, "pre-test-pod", pod.Name)
+}
+
 func TestEmbedTraceIDInNamespacedName(t *testing.T) {
 	// Set up the tracingClient
 	fakeClient := fake.NewClientBuilder().WithObjects().Build()
@@ -170,6 +198,534 @@ func TestPassingTraceIdInNamespacedName(t *testing.T) {
 	assert.Equal(t, "f620f5cad0af940c294f980c5366a6a1", traceID)
 }
 
+func TestStartTraceWithTriggeredByAnnotation(t *testing.T) {
+	// Create a fake Kubernetes client
+	k8sClient := fake.NewClientBuilder().WithObjects(&corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "pre-test-pod",
+			Namespace: "default",
+		},
+	}).Build()
+
+	// Create a real tracer
+	tracer := initTracer()
+
+	// Create a logger
+	logger := logr.Discard()
+	// Initialize the TracingClient
+	tracingClient := NewTracingClient(k8sClient, k8sClient, tracer, logger)
+
+	ctx := context.Background()
+
+	key := client.ObjectKey{Name: "f620f5cad0af940c294f980c5366a6a1;45f359cdc1c8ab06;ConfigMap;configmap-10;pre-test-pod", Namespace: "default"}
+
+	pod := &corev1.Pod{}
+	_, span, err := tracingClient.StartTrace(ctx, key, pod, WithTriggeredByAnnotation())
+	defer span.End()
+
+	assert.NoError(t, err)
+	assert.Equal(t, "ConfigMap/default/configmap-10", pod.Annotations[constants.TriggeredByAnnotation])
+	assert.Equal(t, "f620f5cad0af940c294f980c5366a6a1", pod.Annotations[constants.TraceIDAnnotation])
+
+	// The triggered-by annotation is persisted, the trace annotations taken from the key are not
+	retrievedPod := &corev1.Pod{}
+	err = k8sClient.Get(ctx, client.ObjectKey{Name: "pre-test-pod", Namespace: "default"}, retrievedPod)
+	assert.NoError(t, err)
+	assert.Equal(t, "ConfigMap/default/configmap-10", retrievedPod.Annotations[constants.TriggeredByAnnotation])
+	assert.Empty(t, retrievedPod.Annotations[constants.TraceIDAnnotation])
+}
+
+func TestStartTraceWithAPIReaderFallback(t *testing.T) {
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "new-pod",
+			Namespace: "default",
+		},
+	}
+	// The cache does not have the Pod yet, the API server does
+	cachedClient := fake.NewClientBuilder().Build()
+	apiReader := fake.NewClientBuilder().WithObjects(pod).Build()
+
+	// Create a real tracer
+	tracer := initTracer()
+
+	// Create a logger
+	logger := logr.Discard()
+	// Initialize the TracingClient
+	tracingClient := NewTracingClient(cachedClient, cachedClient, tracer, logger)
+
+	ctx := context.Background()
+	key := client.ObjectKey{Name: "new-pod", Namespace: "default"}
+
+	retrievedPod := &corev1.Pod{}
+	_, span, err := tracingClient.StartTrace(ctx, key, retrievedPod)
+	span.End()
+	assert.NoError(t, err)
+	assert.Empty(t, retrievedPod.Name)
+
+	retrievedPod = &corev1.Pod{}
+	_, span, err = tracingClient.StartTrace(ctx, key, retrievedPod, WithAPIReaderFallback(apiReader))
+	span.End()
+	assert.NoError(t, err)
+	assert.Equal(t, "new-pod", retrievedPod.Name)
+}
+
+func TestStartTraceWithStrictErrors(t *testing.T) {
+	k8sClient := fake.NewClientBuilder().Build()
+	tracer := initTracer()
+	logger := logr.Discard()
+	tracingClient := NewTracingClient(k8sClient, k8sClient, tracer, logger)
+
+	ctx := context.Background()
+	key := client.ObjectKey{Name: "missing-pod", Namespace: "default"}
+
+	_, span, err := tracingClient.StartTrace(ctx, key, &corev1.Pod{})
+	span.End()
+	assert.NoError(t, err)
+
+	_, span, err = tracingClient.StartTrace(ctx, key, &corev1.Pod{}, WithStrictErrors())
+	span.End()
+	assert.True(t, apierrors.IsNotFound(err))
+	assert.NoError(t, client.IgnoreNotFound(err))
+}
+
+func TestSpanOptions(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	tracer := sdktrace.NewTracerProvider(sdktrace.WithSampler(sdktrace.AlwaysSample()), sdktrace.WithSpanProcessor(recorder)).Tracer("kubetracer")
+	k8sClient := fake.NewClientBuilder().Build()
+	tracingClient := NewTracingClient(k8sClient, k8sClient, tracer, logr.Discard())
+
+	ctx := context.Background()
+	_, linked := tracer.Start(ctx, "Event")
+	linked.End()
+
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "test-pod",
+			Namespace: "default",
+		},
+	}
+	err := tracingClient.Create(ctx, pod,
+		WithAttributes(attribute.String("reason", "scale-up")),
+		WithSpanKind(trace.SpanKindProducer),
+		WithLinks(trace.Link{SpanContext: linked.SpanContext()}),
+	)
+	assert.NoError(t, err)
+
+	pod.Labels = map[string]string{"app": "test"}
+	err = tracingClient.Update(ctx, pod)
+	assert.NoError(t, err)
+
+	spans := recorder.Ended()
+	assert.Len(t, spans, 3)
+
+	create := spans[1]
+	assert.Equal(t, "Create Pod test-pod", create.Name())
+	assert.Contains(t, create.Attributes(), attribute.String("reason", "scale-up"))
+	assert.Equal(t, trace.SpanKindProducer, create.SpanKind())
+	assert.Len(t, create.Links(), 1)
+	assert.Equal(t, linked.SpanContext().SpanID(), create.Links()[0].SpanContext.SpanID())
+
+	update := spans[2]
+	assert.NotContains(t, update.Attributes(), attribute.String("reason", "scale-up"))
+	assert.Equal(t, trace.SpanKindInternal, update.SpanKind())
+	assert.Empty(t, update.Links())
+}
+
+func TestStartTraceMarksTraceRoot(t *testing.T) {
+	// Create a fake Kubernetes client
+	k8sClient := fake.NewClientBuilder().WithObjects(&corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "root-pod",
+			Namespace: "default",
+		},
+	}).Build()
+
+	// Create a real tracer
+	tracer := initTracer()
+
+	// Create a logger
+	logger := logr.Discard()
+	// Initialize the TracingClient
+	tracingClient := NewTracingClient(k8sClient, k8sClient, tracer, logger)
+
+	// The Pod has no trace, StartTrace begins a new one
+	rootPod := &corev1.Pod{}
+	ctx, span, err := tracingClient.StartTrace(context.Background(), client.ObjectKey{Name: "root-pod", Namespace: "default"}, rootPod)
+	defer span.End()
+	assert.NoError(t, err)
+
+	childPod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "child-pod",
+			Namespace: "default",
+		},
+	}
+	err = tracingClient.Create(ctx, childPod)
+	assert.NoError(t, err)
+	assert.Equal(t, "true", childPod.Annotations[constants.TraceRootAnnotation])
+	assert.Equal(t, "Pod", childPod.Annotations[constants.TraceRootKindAnnotation])
+	assert.Equal(t, "root-pod", childPod.Annotations[constants.TraceRootNameAnnotation])
+
+	// Reconciling the child keeps pointing at the same root
+	key := client.ObjectKey{Name: "child-pod", Namespace: "default"}
+	err = tracingClient.EmbedTraceIDInNamespacedName(&key, childPod)
+	assert.NoError(t, err)
+
+	retrievedPod := &corev1.Pod{}
+	ctx, span, err = tracingClient.StartTrace(context.Background(), key, retrievedPod)
+	defer span.End()
+	assert.NoError(t, err)
+
+	grandchildPod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "grandchild-pod",
+			Namespace: "default",
+		},
+	}
+	err = tracingClient.Create(ctx, grandchildPod)
+	assert.NoError(t, err)
+	assert.Equal(t, "Pod", grandchildPod.Annotations[constants.TraceRootKindAnnotation])
+	assert.Equal(t, "root-pod", grandchildPod.Annotations[constants.TraceRootNameAnnotation])
+}
+
+func TestActorAttribution(t *testing.T) {
+	// Create a fake Kubernetes client
+	k8sClient := fake.NewClientBuilder().WithObjects(&corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "actor-pod",
+			Namespace: "default",
+			Annotations: map[string]string{
+				constants.ActorAnnotation: "system:serviceaccount:default:deployer",
+			},
+		},
+	}).Build()
+
+	// Record the spans to check their attributes
+	recorder := tracetest.NewSpanRecorder()
+	tracer := sdktrace.NewTracerProvider(sdktrace.WithSampler(sdktrace.AlwaysSample()), sdktrace.WithSpanProcessor(recorder)).Tracer("kubetracer")
+
+	// Initialize the TracingClient
+	tracingClient := NewTracingClient(k8sClient, k8sClient, tracer, logr.Discard())
+
+	pod := &corev1.Pod{}
+	ctx, span, err := tracingClient.StartTrace(context.Background(), client.ObjectKey{Name: "actor-pod", Namespace: "default"}, pod)
+	assert.NoError(t, err)
+
+	childPod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "child-pod",
+			Namespace: "default",
+		},
+	}
+	err = tracingClient.Create(ctx, childPod)
+	assert.NoError(t, err)
+	span.End()
+
+	// The actor is carried over to the objects written downstream
+	assert.Equal(t, "system:serviceaccount:default:deployer", childPod.Annotations[constants.ActorAnnotation])
+
+	spans := recorder.Ended()
+	assert.Len(t, spans, 2)
+	for _, s := range spans {
+		assert.Contains(t, s.Attributes(), ActorAttributeKey.String("system:serviceaccount:default:deployer"), s.Name())
+	}
+}
+
+func TestGlobalTextMapPropagator(t *testing.T) {
+	previous := otel.GetTextMapPropagator()
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+	t.Cleanup(func() { otel.SetTextMapPropagator(previous) })
+
+	// The Pod only carries the W3C traceparent, e.g. written by another tool
+	k8sClient := fake.NewClientBuilder().WithObjects(&corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "w3c-pod",
+			Namespace: "default",
+			Annotations: map[string]string{
+				"otel.kubetracer.io/traceparent": "00-0af7651916cd43dd8448eb211c80319c-b7ad6b7169203331-01",
+			},
+		},
+	}).Build()
+
+	// Create a real tracer
+	tracer := initTracer()
+
+	// Initialize the TracingClient
+	tracingClient := NewTracingClient(k8sClient, k8sClient, tracer, logr.Discard())
+
+	pod := &corev1.Pod{}
+	ctx, span, err := tracingClient.StartTrace(context.Background(), client.ObjectKey{Name: "w3c-pod", Namespace: "default"}, pod)
+	defer span.End()
+	assert.NoError(t, err)
+	assert.Equal(t, "0af7651916cd43dd8448eb211c80319c", span.SpanContext().TraceID().String())
+
+	childPod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "child-pod",
+			Namespace: "default",
+		},
+	}
+	err = tracingClient.Create(ctx, childPod)
+	assert.NoError(t, err)
+	assert.Equal(t, "0af7651916cd43dd8448eb211c80319c", childPod.Annotations[constants.TraceIDAnnotation])
+	assert.Contains(t, childPod.Annotations["otel.kubetracer.io/traceparent"], "0af7651916cd43dd8448eb211c80319c")
+}
+
+func TestWithTraceLabel(t *testing.T) {
+	// Create a fake Kubernetes client
+	k8sClient := fake.NewClientBuilder().Build()
+
+	// Create a real tracer
+	tracer := initTracer()
+
+	// Initialize the TracingClient
+	tracingClient := NewTracingClient(k8sClient, k8sClient, tracer, logr.Discard())
+
+	ctx, span := tracingClient.StartSpan(context.Background(), "TestWithTraceLabel")
+	defer span.End()
+
+	labeledPod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "labeled-pod",
+			Namespace: "default",
+		},
+	}
+	err := tracingClient.Create(ctx, labeledPod, WithTraceLabel())
+	assert.NoError(t, err)
+
+	unlabeledPod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "unlabeled-pod",
+			Namespace: "default",
+		},
+	}
+	err = tracingClient.Create(ctx, unlabeledPod)
+	assert.NoError(t, err)
+
+	traceID := span.SpanContext().TraceID().String()
+	assert.Equal(t, traceID, labeledPod.Labels[constants.TraceLabel])
+	assert.Equal(t, traceID, labeledPod.Annotations[constants.TraceIDAnnotation])
+
+	pods := &corev1.PodList{}
+	err = tracingClient.List(ctx, pods, MatchingTraceLabel(traceID))
+	assert.NoError(t, err)
+	assert.Len(t, pods.Items, 1)
+	assert.Equal(t, "labeled-pod", pods.Items[0].Name)
+}
+
+func TestWithTraceparentData(t *testing.T) {
+	// Create a fake Kubernetes client
+	k8sClient := fake.NewClientBuilder().Build()
+
+	// Create a real tracer
+	tracer := initTracer()
+
+	// Initialize the TracingClient
+	tracingClient := NewTracingClient(k8sClient, k8sClient, tracer, logr.Discard())
+
+	ctx, span := tracingClient.StartSpan(context.Background(), "TestWithTraceparentData")
+	defer span.End()
+
+	configMap := &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: "config", Namespace: "default"}}
+	err := tracingClient.Create(ctx, configMap, WithTraceparentData())
+	assert.NoError(t, err)
+
+	secret := &corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: "secret", Namespace: "default"}}
+	err = tracingClient.Create(ctx, secret, WithTraceparentData())
+	assert.NoError(t, err)
+
+	// The traceparent is the one of the Create span, which is a child of span
+	traceID := span.SpanContext().TraceID().String()
+	assert.Regexp(t, "^00-"+traceID+"-"+configMap.Annotations[constants.SpanIDAnnotation]+"-0[01]$", configMap.Data[constants.TraceparentDataKey])
+	assert.Regexp(t, "^00-"+traceID+"-"+secret.Annotations[constants.SpanIDAnnotation]+"-0[01]$", string(secret.Data[constants.TraceparentDataKey]))
+
+	// Without the option no data is written
+	plain := &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: "plain", Namespace: "default"}}
+	err = tracingClient.Create(ctx, plain)
+	assert.NoError(t, err)
+	assert.Empty(t, plain.Data)
+}
+
+func TestNoopTracerWritesNoAnnotations(t *testing.T) {
+	// Create a fake Kubernetes client
+	k8sClient := fake.NewClientBuilder().Build()
+
+	// A noop tracer creates invalid span contexts
+	tracingClient := NewTracingClient(k8sClient, k8sClient, noop.NewTracerProvider().Tracer("kubetracer"), logr.Discard())
+
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "test-pod",
+			Namespace: "default",
+		},
+	}
+	err := tracingClient.Create(context.Background(), pod)
+	assert.NoError(t, err)
+	assert.NotContains(t, pod.Annotations, constants.TraceIDAnnotation)
+	assert.NotContains(t, pod.Annotations, constants.SpanIDAnnotation)
+}
+
+// TestConcurrentReconciles runs reconciles of different objects through one shared TracingClient, as the workers
+// of a controller do.  Run with -race.
+func TestConcurrentReconciles(t *testing.T) {
+	const workers = 8
+
+	builder := fake.NewClientBuilder()
+	for i := 0; i < workers; i++ {
+		pod := &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      fmt.Sprintf("pod-%d", i),
+				Namespace: "default",
+				Annotations: map[string]string{
+					constants.TraceIDAnnotation: "f620f5cad0af940c294f980c5366a6a1",
+					constants.SpanIDAnnotation:  "45f359cdc1c8ab06",
+				},
+			},
+		}
+		builder = builder.WithObjects(pod).WithStatusSubresource(pod)
+	}
+	k8sClient := builder.Build()
+
+	// Spans are not exported, the tracer is shared as well
+	tracer := sdktrace.NewTracerProvider(sdktrace.WithSampler(sdktrace.AlwaysSample())).Tracer("kubetracer")
+	tracingClient := NewTracingClient(k8sClient, k8sClient, tracer, logr.Discard())
+
+	var wg sync.WaitGroup
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+
+			pod := &corev1.Pod{}
+			ctx, span, err := tracingClient.StartTrace(context.Background(), client.ObjectKey{Name: fmt.Sprintf("pod-%d", i), Namespace: "default"}, pod)
+			defer span.End()
+			assert.NoError(t, err)
+
+			child := &corev1.ConfigMap{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      fmt.Sprintf("configmap-%d", i),
+					Namespace: "default",
+				},
+			}
+			assert.NoError(t, tracingClient.Create(ctx, child))
+			assert.Equal(t, "f620f5cad0af940c294f980c5366a6a1", child.Annotations[constants.TraceIDAnnotation])
+
+			assert.NoError(t, tracingClient.Status().Update(ctx, pod))
+			_, err = tracingClient.EndTrace(ctx, pod)
+			assert.NoError(t, err)
+		}(i)
+	}
+	wg.Wait()
+}
+
+func TestRepeatedWritesCoalesceAnnotations(t *testing.T) {
+	// Create a fake Kubernetes client
+	k8sClient := fake.NewClientBuilder().WithObjects(&corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "pre-test-pod",
+			Namespace: "default",
+		},
+	}).Build()
+
+	// Initialize the TracingClient
+	tracingClient := NewTracingClient(k8sClient, k8sClient, initTracer(), logr.Discard())
+
+	ctx, span, err := tracingClient.StartTrace(context.Background(), client.ObjectKey{Name: "pre-test-pod", Namespace: "default"}, &corev1.Pod{})
+	defer span.End()
+	assert.NoError(t, err)
+
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "test-pod",
+			Namespace: "default",
+		},
+	}
+	err = tracingClient.Create(ctx, pod)
+	assert.NoError(t, err)
+	spanID := pod.Annotations[constants.SpanIDAnnotation]
+
+	// Writing the Pod again in the same reconcile keeps the annotations
+	podPatch := client.MergeFrom(pod.DeepCopy())
+	pod.Labels = map[string]string{"updated": "true"}
+	err = tracingClient.Patch(ctx, pod, podPatch)
+	assert.NoError(t, err)
+	assert.Equal(t, spanID, pod.Annotations[constants.SpanIDAnnotation])
+
+	err = tracingClient.Update(ctx, pod)
+	assert.NoError(t, err)
+	assert.Equal(t, spanID, pod.Annotations[constants.SpanIDAnnotation])
+
+	// A new reconcile writes its own span
+	ctx, span, err = tracingClient.StartTrace(context.Background(), client.ObjectKey{Name: "pre-test-pod", Namespace: "default"}, &corev1.Pod{})
+	defer span.End()
+	assert.NoError(t, err)
+
+	err = tracingClient.Update(ctx, pod)
+	assert.NoError(t, err)
+	assert.NotEqual(t, spanID, pod.Annotations[constants.SpanIDAnnotation])
+}
+
+func TestRequeueWithTrace(t *testing.T) {
+	// Create a fake Kubernetes client
+	k8sClient := fake.NewClientBuilder().WithObjects(&corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "requeued-pod",
+			Namespace: "default",
+		},
+	}).Build()
+
+	// Initialize the TracingClient
+	tracingClient := NewTracingClient(k8sClient, k8sClient, initTracer(), logr.Discard())
+
+	// The reconcile was triggered by a change embedded in the key
+	key := client.ObjectKey{Name: "f620f5cad0af940c294f980c5366a6a1;45f359cdc1c8ab06;ConfigMap;configmap-10;requeued-pod", Namespace: "default"}
+	pod := &corev1.Pod{}
+	ctx, span, err := tracingClient.StartTrace(context.Background(), key, pod)
+	assert.NoError(t, err)
+	result := RequeueAfterWithTrace(ctx, pod, time.Minute)
+	span.End()
+	assert.Equal(t, time.Minute, result.RequeueAfter)
+
+	// The follow-up reconcile only gets the name of the Pod
+	pod = &corev1.Pod{}
+	_, span, err = tracingClient.StartTrace(context.Background(), client.ObjectKey{Name: "requeued-pod", Namespace: "default"}, pod)
+	span.End()
+	assert.NoError(t, err)
+	assert.Equal(t, "f620f5cad0af940c294f980c5366a6a1", span.SpanContext().TraceID().String())
+
+	// The trace is only used once
+	pod = &corev1.Pod{}
+	_, span, err = tracingClient.StartTrace(context.Background(), client.ObjectKey{Name: "requeued-pod", Namespace: "default"}, pod)
+	span.End()
+	assert.NoError(t, err)
+	assert.NotEqual(t, "f620f5cad0af940c294f980c5366a6a1", span.SpanContext().TraceID().String())
+}
+
+func TestRequeueWithTraceCustomKeys(t *testing.T) {
+	k8sClient := fake.NewClientBuilder().WithObjects(&corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "requeued-custom-pod",
+			Namespace: "default",
+		},
+	}).Build()
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSampler(sdktrace.AlwaysSample()))
+	tracingClient := NewTracingClientWithOptions(k8sClient, WithTracerProvider(tp), WithAnnotationPrefix("example.com/"))
+
+	traceID, _ := trace.TraceIDFromHex("f620f5cad0af940c294f980c5366a6a1")
+	spanID, _ := trace.SpanIDFromHex("45f359cdc1c8ab06")
+	ctx := trace.ContextWithSpanContext(context.Background(), trace.NewSpanContext(trace.SpanContextConfig{TraceID: traceID, SpanID: spanID}))
+	RequeueWithTrace(ctx, &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "requeued-custom-pod", Namespace: "default"}})
+
+	// The follow-up reconcile continues the trace, carried in the configured annotations
+	pod := &corev1.Pod{}
+	_, span, err := tracingClient.StartTrace(context.Background(), client.ObjectKey{Name: "requeued-custom-pod", Namespace: "default"}, pod)
+	span.End()
+	assert.NoError(t, err)
+	assert.Equal(t, "f620f5cad0af940c294f980c5366a6a1", span.SpanContext().TraceID().String())
+	assert.NotContains(t, pod.Annotations, constants.TraceIDAnnotation)
+}
+
 func TestChainReactionTracing(t *testing.T) {
 	// Create a fake Kubernetes client
 	k8sClient := fake.NewClientBuilder().WithObjects(&corev1.Pod{
@@ -460,6 +1016,74 @@ func TestEndTrace(t *testing.T) {
 	assert.Empty(t, finalPod.Annotations[constants.SpanIDAnnotation])
 }
 
+func TestEndTraceRemovesConditions(t *testing.T) {
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "test-pod",
+			Namespace: "default",
+			Annotations: map[string]string{
+				constants.TraceIDAnnotation: "f620f5cad0af940c294f980c5366a6a1",
+				constants.SpanIDAnnotation:  "45f359cdc1c8ab06",
+			},
+		},
+		Status: corev1.PodStatus{
+			Conditions: []corev1.PodCondition{
+				{Type: corev1.PodReady, Status: corev1.ConditionTrue},
+				{Type: "TraceID", Message: "f620f5cad0af940c294f980c5366a6a1"},
+				{Type: "SpanID", Message: "45f359cdc1c8ab06"},
+			},
+		},
+	}
+	k8sClient := fake.NewClientBuilder().WithObjects(pod).WithStatusSubresource(pod).Build()
+
+	// Initialize the TracingClient
+	tracingClient := NewTracingClient(k8sClient, k8sClient, initTracer(), logr.Discard())
+
+	_, err := tracingClient.EndTrace(context.Background(), pod.DeepCopy())
+	assert.NoError(t, err)
+
+	finalPod := &corev1.Pod{}
+	err = k8sClient.Get(context.Background(), client.ObjectKeyFromObject(pod), finalPod)
+	assert.NoError(t, err)
+	assert.Empty(t, finalPod.Annotations[constants.TraceIDAnnotation])
+	assert.Len(t, finalPod.Status.Conditions, 1)
+	assert.Equal(t, corev1.PodReady, finalPod.Status.Conditions[0].Type)
+}
+
+func BenchmarkEndTrace(b *testing.B) {
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "test-pod",
+			Namespace: "default",
+		},
+		Spec: corev1.PodSpec{
+			Containers: []corev1.Container{{Name: "app", Image: "app:latest"}},
+		},
+	}
+	k8sClient := fake.NewClientBuilder().WithObjects(pod).Build()
+
+	// Spans are not exported, only EndTrace is measured
+	tracer := sdktrace.NewTracerProvider().Tracer("kubetracer")
+	tracingClient := NewTracingClient(k8sClient, k8sClient, tracer, logr.Discard())
+	ctx := context.Background()
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		b.StopTimer()
+		tracedPod := &corev1.Pod{}
+		k8sClient.Get(ctx, client.ObjectKeyFromObject(pod), tracedPod)
+		tracedPod.Annotations = map[string]string{
+			constants.TraceIDAnnotation: "f620f5cad0af940c294f980c5366a6a1",
+			constants.SpanIDAnnotation:  "45f359cdc1c8ab06",
+		}
+		k8sClient.Update(ctx, tracedPod)
+		b.StartTimer()
+
+		tracingClient.EndTrace(ctx, tracedPod)
+	}
+}
+
 func TestEndTraceChangedAnnotation(t *testing.T) {
 	// Create a fake Kubernetes client
 	k8sClient := fake.NewClientBuilder().WithObjects(&corev1.Pod{
@@ -641,6 +1265,35 @@ func TestDeleteAllOfWithTracing(t *testing.T) {
 
 }
 
+func TestDeleteAllOfAttributes(t *testing.T) {
+	pods := []client.Object{
+		&corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "web-1", Namespace: "default", Labels: map[string]string{"app": "web"}}},
+		&corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "web-2", Namespace: "default", Labels: map[string]string{"app": "web"}}},
+		&corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "db", Namespace: "default", Labels: map[string]string{"app": "db"}}},
+	}
+	k8sClient := fake.NewClientBuilder().WithObjects(pods...).Build()
+	recorder := tracetest.NewSpanRecorder()
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSampler(sdktrace.AlwaysSample()), sdktrace.WithSpanProcessor(recorder))
+	tracingClient := NewTracingClientWithOptions(k8sClient, WithTracerProvider(tp))
+
+	selector := client.MatchingLabels{"app": "web"}
+	assert.NoError(t, tracingClient.DeleteAllOf(context.Background(), &corev1.Pod{}, client.InNamespace("default"), selector))
+	attrs := recorder.Ended()[0].Attributes()
+	assert.Contains(t, attrs, K8sNamespaceNameKey.String("default"))
+	assert.Contains(t, attrs, LabelSelectorKey.String("app=web"))
+	for _, attr := range attrs {
+		assert.NotEqual(t, AffectedCountKey, attr.Key)
+		assert.NotEqual(t, FieldSelectorKey, attr.Key)
+	}
+
+	assert.NoError(t, tracingClient.DeleteAllOf(context.Background(), &corev1.Pod{}, client.InNamespace("default"), WithAffectedCount()))
+	assert.Contains(t, recorder.Ended()[1].Attributes(), AffectedCountKey.Int(1))
+
+	remaining := &corev1.PodList{}
+	assert.NoError(t, k8sClient.List(context.Background(), remaining))
+	assert.Empty(t, remaining.Items)
+}
+
 func TestGetConditions(t *testing.T) {
 	// Create a scheme
 	scheme := runtime.NewScheme()
@@ -771,6 +1424,49 @@ func TestSetConditionMessage(t *testing.T) {
 	assert.Equal(t, expectedMessage, message)
 }
 
+func TestSetTraceConditions(t *testing.T) {
+	// Create a scheme
+	scheme := runtime.NewScheme()
+	corev1.AddToScheme(scheme)
+
+	// Create a Pod object with an outdated trace condition
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "test-pod",
+			Namespace: "default",
+		},
+		Status: corev1.PodStatus{
+			Conditions: []corev1.PodCondition{
+				{Type: corev1.PodScheduled, Status: corev1.ConditionTrue},
+				{Type: "TraceID", Message: "0af7651916cd43dd8448eb211c80319c"},
+			},
+		},
+	}
+
+	traceID, _ := trace.TraceIDFromHex("f620f5cad0af940c294f980c5366a6a1")
+	spanID, _ := trace.SpanIDFromHex("45f359cdc1c8ab06")
+	spanContext := trace.NewSpanContext(trace.SpanContextConfig{TraceID: traceID, SpanID: spanID})
+
+	// Set both conditions in one pass
+	err := setTraceConditions(spanContext, pod, scheme, TraceAndSpanIDConditions)
+	assert.NoError(t, err)
+	assert.Len(t, pod.Status.Conditions, 3)
+
+	message, err := getConditionMessage("TraceID", pod, scheme)
+	assert.NoError(t, err)
+	assert.Equal(t, "f620f5cad0af940c294f980c5366a6a1", message)
+
+	message, err = getConditionMessage("SpanID", pod, scheme)
+	assert.NoError(t, err)
+	assert.Equal(t, "45f359cdc1c8ab06", message)
+
+	// Delete both conditions in one pass
+	err = deleteConditions(pod, scheme, "TraceID", "SpanID")
+	assert.NoError(t, err)
+	assert.Len(t, pod.Status.Conditions, 1)
+	assert.Equal(t, corev1.PodScheduled, pod.Status.Conditions[0].Type)
+}
+
 func TestDeleteCondition(t *testing.T) {
 	// Create a scheme
 	scheme := runtime.NewScheme()
@@ -811,3 +1507,111 @@ func TestDeleteCondition(t *testing.T) {
 	expectedConditions := []metav1.Condition(nil)
 	assert.Equal(t, expectedConditions, conditions)
 }
+
+func TestWithOnSpanEnd(t *testing.T) {
+	// Create a fake Kubernetes client
+	k8sClient := fake.NewClientBuilder().Build()
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSampler(sdktrace.AlwaysSample()))
+
+	// Count the ended spans per verb
+	verbs := map[string]int{}
+	var ended []string
+	tracingClient := NewTracingClientWithOptions(k8sClient, WithTracerProvider(tp), WithOnSpanEnd(func(span sdktrace.ReadOnlySpan, verb string, obj client.Object) {
+		verbs[verb]++
+		ended = append(ended, span.Name())
+		if verb != "list" {
+			assert.Equal(t, "hook-pod", obj.GetName())
+		}
+	}))
+
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "hook-pod",
+			Namespace: "default",
+		},
+	}
+	assert.NoError(t, tracingClient.Create(context.Background(), pod))
+	assert.NoError(t, tracingClient.Get(context.Background(), client.ObjectKeyFromObject(pod), pod))
+	assert.NoError(t, tracingClient.List(context.Background(), &corev1.PodList{}))
+	assert.NoError(t, tracingClient.Status().Update(context.Background(), pod))
+	assert.NoError(t, tracingClient.Delete(context.Background(), pod))
+
+	assert.Equal(t, map[string]int{"create": 1, "get": 1, "list": 1, "status-update": 1, "delete": 1}, verbs)
+	assert.Len(t, ended, 5)
+}
+
+func TestConflictSpanEvents(t *testing.T) {
+	// Create a fake Kubernetes client
+	k8sClient := fake.NewClientBuilder().WithObjects(&corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "conflict-pod",
+			Namespace: "default",
+		},
+	}).Build()
+
+	// Record the spans to check their events
+	recorder := tracetest.NewSpanRecorder()
+	tracer := sdktrace.NewTracerProvider(sdktrace.WithSampler(sdktrace.AlwaysSample()), sdktrace.WithSpanProcessor(recorder)).Tracer("kubetracer")
+	tracingClient := NewTracingClient(k8sClient, k8sClient, tracer, logr.Discard())
+
+	pod := &corev1.Pod{}
+	ctx, span, err := tracingClient.StartTrace(context.Background(), client.ObjectKey{Name: "conflict-pod", Namespace: "default"}, pod)
+	assert.NoError(t, err)
+
+	// Write the pod with a stale resourceVersion
+	stale := pod.DeepCopy()
+	stale.ResourceVersion = "1"
+	assert.NoError(t, k8sClient.Update(context.Background(), pod))
+	err = tracingClient.Update(ctx, stale)
+	assert.True(t, apierrors.IsConflict(err))
+	span.End()
+
+	// Both the Update span and the reconcile span carry the conflict
+	spans := recorder.Ended()
+	assert.Len(t, spans, 2)
+	for _, s := range spans {
+		var conflicts []sdktrace.Event
+		for _, event := range s.Events() {
+			if event.Name == "Conflict Update Pod conflict-pod" {
+				conflicts = append(conflicts, event)
+			}
+		}
+		assert.Len(t, conflicts, 1, s.Name())
+		assert.Contains(t, conflicts[0].Attributes, ConflictResourceVersionKey.String("1"))
+		assert.Contains(t, conflicts[0].Attributes, ConflictCurrentResourceVersionKey.String(pod.ResourceVersion))
+	}
+}
+
+func TestMutationSpanNamesTriggeredBy(t *testing.T) {
+	// Create a fake Kubernetes client
+	k8sClient := fake.NewClientBuilder().WithObjects(&corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "deploy01",
+			Namespace: "default",
+		},
+	}).Build()
+
+	// Record the spans to check their names
+	recorder := tracetest.NewSpanRecorder()
+	tracer := sdktrace.NewTracerProvider(sdktrace.WithSampler(sdktrace.AlwaysSample()), sdktrace.WithSpanProcessor(recorder)).Tracer("kubetracer")
+	tracingClient := NewTracingClient(k8sClient, k8sClient, tracer, logr.Discard())
+
+	// The request embeds the ConfigMap whose change caused the reconcile
+	key := client.ObjectKey{Namespace: "default", Name: "f620f5cad0af940c294f980c5366a6a1;45f359cdc1c8ab06;ConfigMap;deploy01-configs;deploy01"}
+	pod := &corev1.Pod{}
+	ctx, span, err := tracingClient.StartTrace(context.Background(), key, pod)
+	assert.NoError(t, err)
+
+	assert.NoError(t, tracingClient.Update(ctx, pod))
+	span.End()
+
+	// A write outside of the reconcile is not attributed
+	assert.NoError(t, tracingClient.Update(context.Background(), pod))
+
+	var names []string
+	for _, s := range recorder.Ended() {
+		names = append(names, s.Name())
+	}
+	assert.Contains(t, names, "Update Pod deploy01 Triggered By ConfigMap deploy01-configs")
+	assert.Contains(t, names, "Update Pod deploy01")
+}
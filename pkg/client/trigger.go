@@ -0,0 +1,33 @@
+package client
+
+import (
+	"context"
+	"fmt"
+
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// triggerKey is the context key holding the trigger of the current reconcile
+type triggerKey struct{}
+
+// trigger is the object whose change, embedded in the request, caused the reconcile
+type trigger struct {
+	kind string
+	name string
+}
+
+// contextWithTrigger stores the object that caused the reconcile in ctx
+func contextWithTrigger(ctx context.Context, kind, name string) context.Context {
+	return context.WithValue(ctx, triggerKey{}, trigger{kind: kind, name: name})
+}
+
+// mutationSpanName names the span of a write of the object at key, e.g. "Update Deployment deploy01", followed
+// by "Triggered By ConfigMap deploy01-configs" when the reconcile in ctx was caused by another object
+func mutationSpanName(ctx context.Context, format SpanNameFormatter, verb string, gvk schema.GroupVersionKind, key client.ObjectKey) string {
+	spanName := format.spanName(verb, gvk, key)
+	if t, ok := ctx.Value(triggerKey{}).(trigger); ok {
+		spanName = fmt.Sprintf("%s Triggered By %s %s", spanName, t.kind, t.name)
+	}
+	return spanName
+}
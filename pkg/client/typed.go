@@ -0,0 +1,98 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+
+	"go.opentelemetry.io/otel/trace"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/apiutil"
+)
+
+// TypedTracingClient is a TracingClient for objects of type T, e.g. *corev1.Pod.  It gives compile-time type
+// safety, and resolves the kind of T once instead of on every call.
+type TypedTracingClient[T client.Object] struct {
+	tracingClient *tracingClient
+	gvk           schema.GroupVersionKind
+}
+
+// NewTypedTracingClient returns a TypedTracingClient for T, which must be a pointer to a type registered in
+// the scheme of tc
+func NewTypedTracingClient[T client.Object](tc TracingClient) (*TypedTracingClient[T], error) {
+	tracingClient, ok := tc.(*tracingClient)
+	if !ok {
+		return nil, fmt.Errorf("unsupported TracingClient %T", tc)
+	}
+
+	obj, err := newTyped[T]()
+	if err != nil {
+		return nil, err
+	}
+	gvk, err := apiutil.GVKForObject(obj, tracingClient.scheme)
+	if err != nil {
+		return nil, fmt.Errorf("problem getting the scheme: %w", err)
+	}
+
+	return &TypedTracingClient[T]{tracingClient: tracingClient, gvk: gvk}, nil
+}
+
+// Get adds tracing around the original client's Get method and returns the object read
+func (c *TypedTracingClient[T]) Get(ctx context.Context, key client.ObjectKey, opts ...client.GetOption) (T, error) {
+	obj, err := newTyped[T]()
+	if err != nil {
+		return obj, err
+	}
+	return obj, c.tracingClient.get(ctx, c.gvk, key, obj, opts...)
+}
+
+// Create adds tracing and traceID annotation around the original client's Create method
+func (c *TypedTracingClient[T]) Create(ctx context.Context, obj T, opts ...client.CreateOption) error {
+	return c.tracingClient.create(ctx, c.gvk, obj, opts...)
+}
+
+// Update adds tracing and traceID annotation around the original client's Update method
+func (c *TypedTracingClient[T]) Update(ctx context.Context, obj T, opts ...client.UpdateOption) error {
+	return c.tracingClient.update(ctx, c.gvk, obj, opts...)
+}
+
+// Patch adds tracing and traceID annotation around the original client's Patch method
+func (c *TypedTracingClient[T]) Patch(ctx context.Context, obj T, patch client.Patch, opts ...client.PatchOption) error {
+	return c.tracingClient.patch(ctx, c.gvk, obj, patch, opts...)
+}
+
+// Delete adds tracing around the original client's Delete method
+func (c *TypedTracingClient[T]) Delete(ctx context.Context, obj T, opts ...client.DeleteOption) error {
+	return c.tracingClient.delete(ctx, c.gvk, obj, opts...)
+}
+
+// StartTrace reads the object of T at key and starts the span of the reconcile, see TracingClient.StartTrace.
+// IMPORTANT: Caller MUST call `defer span.End()` to end the trace from the calling function
+func (c *TypedTracingClient[T]) StartTrace(ctx context.Context, key client.ObjectKey, opts ...client.GetOption) (context.Context, T, trace.Span, error) {
+	obj, err := newTyped[T]()
+	if err != nil {
+		return ctx, obj, trace.SpanFromContext(ctx), err
+	}
+	ctx, span, err := c.tracingClient.StartTrace(ctx, key, obj, opts...)
+	return ctx, obj, span, err
+}
+
+// EndTrace ends the trace by clearing the trace from the object, see TracingClient.EndTrace
+func (c *TypedTracingClient[T]) EndTrace(ctx context.Context, obj T, opts ...client.PatchOption) (T, error) {
+	ended, err := c.tracingClient.EndTrace(ctx, obj, opts...)
+	if typed, ok := ended.(T); ok {
+		return typed, err
+	}
+	return obj, err
+}
+
+// newTyped allocates the object T points to
+func newTyped[T client.Object]() (T, error) {
+	var zero T
+	objType := reflect.TypeOf(zero)
+	if objType == nil || objType.Kind() != reflect.Pointer {
+		return zero, fmt.Errorf("type %T must be a pointer to a struct", zero)
+	}
+	return reflect.New(objType.Elem()).Interface().(T), nil
+}
@@ -0,0 +1,131 @@
+package client
+
+import (
+	"context"
+	"testing"
+
+	"github.com/go-logr/logr"
+	constants "github.com/kubetracer/kubetracer-go/pkg/constants"
+	"github.com/stretchr/testify/assert"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestTypedTracingClient(t *testing.T) {
+	// Create a fake Kubernetes client
+	k8sClient := fake.NewClientBuilder().Build()
+
+	// Record the spans to check their names
+	recorder := tracetest.NewSpanRecorder()
+	tracer := sdktrace.NewTracerProvider(sdktrace.WithSampler(sdktrace.AlwaysSample()), sdktrace.WithSpanProcessor(recorder)).Tracer("kubetracer")
+
+	pods, err := NewTypedTracingClient[*corev1.Pod](NewTracingClient(k8sClient, k8sClient, tracer, logr.Discard()))
+	assert.NoError(t, err)
+
+	ctx := context.Background()
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "typed-pod",
+			Namespace: "default",
+		},
+	}
+	assert.NoError(t, pods.Create(ctx, pod))
+	assert.NotEmpty(t, pod.Annotations[constants.TraceIDAnnotation])
+
+	got, err := pods.Get(ctx, client.ObjectKeyFromObject(pod))
+	assert.NoError(t, err)
+	assert.Equal(t, "typed-pod", got.Name)
+
+	got.Labels = map[string]string{"app": "typed"}
+	assert.NoError(t, pods.Update(ctx, got))
+	assert.NoError(t, pods.Patch(ctx, got, client.MergeFrom(got.DeepCopy())))
+	assert.NoError(t, pods.Delete(ctx, got))
+
+	var names []string
+	for _, span := range recorder.Ended() {
+		names = append(names, span.Name())
+	}
+	assert.Equal(t, []string{"Create Pod typed-pod", "Get Pod typed-pod", "Update Pod typed-pod", "Patch Pod typed-pod", "Delete Pod typed-pod"}, names)
+}
+
+func TestTypedTracingClientTrace(t *testing.T) {
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "typed-pod",
+			Namespace: "default",
+			Annotations: map[string]string{
+				constants.TraceIDAnnotation: "f620f5cad0af940c294f980c5366a6a1",
+				constants.SpanIDAnnotation:  "45f359cdc1c8ab06",
+			},
+		},
+	}
+	k8sClient := fake.NewClientBuilder().WithObjects(pod).Build()
+	recorder := tracetest.NewSpanRecorder()
+	tracer := sdktrace.NewTracerProvider(sdktrace.WithSampler(sdktrace.AlwaysSample()), sdktrace.WithSpanProcessor(recorder)).Tracer("kubetracer")
+
+	pods, err := NewTypedTracingClient[*corev1.Pod](NewTracingClient(k8sClient, k8sClient, tracer, logr.Discard()))
+	assert.NoError(t, err)
+
+	ctx, got, span, err := pods.StartTrace(context.Background(), client.ObjectKeyFromObject(pod))
+	assert.NoError(t, err)
+	assert.Equal(t, "typed-pod", got.Name)
+	assert.Equal(t, "f620f5cad0af940c294f980c5366a6a1", span.SpanContext().TraceID().String())
+
+	ended, err := pods.EndTrace(ctx, got)
+	assert.NoError(t, err)
+	assert.NotContains(t, ended.Annotations, constants.TraceIDAnnotation)
+	span.End()
+}
+
+func TestTypedTracingClientWithoutTracing(t *testing.T) {
+	k8sClient := fake.NewClientBuilder().Build()
+	recorder := tracetest.NewSpanRecorder()
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSampler(sdktrace.AlwaysSample()), sdktrace.WithSpanProcessor(recorder))
+	tracingClient := NewTracingClientWithOptions(k8sClient, WithTracerProvider(tp), WithSampler(func(verb string, gvk schema.GroupVersionKind, key client.ObjectKey) bool {
+		return verb != "update"
+	}))
+	pods, err := NewTypedTracingClient[*corev1.Pod](tracingClient)
+	assert.NoError(t, err)
+
+	pod := &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "typed-pod", Namespace: "default"}}
+	assert.NoError(t, pods.Create(WithoutTracing(context.Background()), pod))
+	assert.NoError(t, pods.Update(context.Background(), pod))
+	assert.Empty(t, pod.Annotations)
+	assert.Empty(t, recorder.Ended())
+}
+
+func TestTypedTracingClientWithoutTracingVerbs(t *testing.T) {
+	k8sClient := fake.NewClientBuilder().Build()
+	recorder := tracetest.NewSpanRecorder()
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSampler(sdktrace.AlwaysSample()), sdktrace.WithSpanProcessor(recorder))
+	pods, err := NewTypedTracingClient[*corev1.Pod](NewTracingClientWithOptions(k8sClient, WithTracerProvider(tp)))
+	assert.NoError(t, err)
+
+	ctx := WithoutTracing(context.Background())
+	pod := &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "typed-pod", Namespace: "default"}}
+	assert.NoError(t, pods.Create(ctx, pod))
+
+	got, err := pods.Get(ctx, client.ObjectKeyFromObject(pod))
+	assert.NoError(t, err)
+	assert.Equal(t, "typed-pod", got.Name)
+
+	got.Labels = map[string]string{"app": "typed"}
+	assert.NoError(t, pods.Update(ctx, got))
+	patch := client.MergeFrom(got.DeepCopy())
+	got.Labels["tier"] = "backend"
+	assert.NoError(t, pods.Patch(ctx, got, patch))
+
+	// nothing was traced nor written to the object
+	stored := &corev1.Pod{}
+	assert.NoError(t, k8sClient.Get(context.Background(), client.ObjectKeyFromObject(pod), stored))
+	assert.Empty(t, stored.Annotations)
+	assert.Equal(t, map[string]string{"app": "typed", "tier": "backend"}, stored.Labels)
+
+	assert.NoError(t, pods.Delete(ctx, got))
+	assert.Empty(t, recorder.Ended())
+}
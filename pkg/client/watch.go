@@ -0,0 +1,139 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/go-logr/logr"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/watch"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/apiutil"
+)
+
+const (
+	// WatchEventTraceIDKey is the span event attribute holding the trace ID carried by the object of a watch event
+	WatchEventTraceIDKey = attribute.Key("kubetracer.watch.trace_id")
+	// WatchEventSpanIDKey is the span event attribute holding the span ID carried by the object of a watch event
+	WatchEventSpanIDKey = attribute.Key("kubetracer.watch.span_id")
+)
+
+// TracingClientWithWatch is a TracingClient which can also watch objects, see NewTracingClientWithWatch
+type TracingClientWithWatch interface {
+	TracingClient
+	Watch(ctx context.Context, obj client.ObjectList, opts ...client.ListOption) (watch.Interface, error)
+}
+
+// NewTracingClientWithWatch wraps c like NewTracingClient, and records a span for the lifetime of every watch
+// with an event per object received.  The events carry the trace of the object, so watch-driven workflows can
+// be correlated with the controllers writing the objects.
+func NewTracingClientWithWatch(c client.WithWatch, r client.Reader, t trace.Tracer, l logr.Logger, scheme ...*runtime.Scheme) TracingClientWithWatch {
+	return NewTracingClient(c, r, t, l, scheme...).(*tracingClient)
+}
+
+// Watch adds tracing around the original client's Watch method.  The span ends when the watch is stopped or
+// its result channel is closed.  An error is returned if the wrapped client cannot watch.
+func (tc *tracingClient) Watch(ctx context.Context, obj client.ObjectList, opts ...client.ListOption) (watch.Interface, error) {
+	watcher, ok := tc.Client.(client.WithWatch)
+	if !ok {
+		return nil, fmt.Errorf("client %T does not support watch", tc.Client)
+	}
+	if tracingDisabled(ctx) {
+		return watcher.Watch(ctx, obj, opts...)
+	}
+
+	gvk, err := apiutil.GVKForObject(obj, tc.scheme)
+	if err != nil {
+		return nil, fmt.Errorf("problem getting the scheme: %w", err)
+	}
+
+	kind := strings.TrimSuffix(gvk.Kind, "List")
+	operationName := fmt.Sprintf("Watch %s", kind)
+	if tc.spanNames != nil {
+		namespace := (&client.ListOptions{}).ApplyOptions(opts).Namespace
+		operationName = tc.spanNames("Watch", gvk.GroupVersion().WithKind(kind), client.ObjectKey{Namespace: namespace})
+	}
+	ctx, span := startSpanFromContextList(ctx, tc.Logger, tc.Tracer, obj, operationName)
+
+	tc.Logger.Info("Watching objects", "kind", kind)
+	w, err := watcher.Watch(ctx, obj, opts...)
+	if err != nil {
+		tc.stackTraces.recordError(span, err)
+		endSpan(ctx, span, tc.onSpanEnd, "watch", kind, nil)
+		return nil, err
+	}
+
+	traced := &tracedWatch{
+		Interface: w,
+		result:    make(chan watch.Event),
+		done:      make(chan struct{}),
+		span:      span,
+		kind:      kind,
+		scheme:    tc.propagation.conditionsScheme(tc.scheme),
+		keys:      tc.propagation.annotationKeys(),
+	}
+	go traced.forward(ctx, tc.onSpanEnd)
+	return traced, nil
+}
+
+// tracedWatch forwards the events of a watch.Interface, adding them to the span of the watch
+type tracedWatch struct {
+	watch.Interface
+	result   chan watch.Event
+	done     chan struct{}
+	stopOnce sync.Once
+
+	span trace.Span
+	kind string
+
+	// scheme reads the trace of the objects from their status, only from their metadata when nil
+	scheme *runtime.Scheme
+	keys   *annotationKeys
+}
+
+// ResultChan implements watch.Interface
+func (w *tracedWatch) ResultChan() <-chan watch.Event {
+	return w.result
+}
+
+// Stop implements watch.Interface
+func (w *tracedWatch) Stop() {
+	w.stopOnce.Do(func() {
+		close(w.done)
+		w.Interface.Stop()
+	})
+}
+
+// forward records and forwards the events of the wrapped watch until it is stopped or closed
+func (w *tracedWatch) forward(ctx context.Context, hook OnSpanEndFunc) {
+	defer endSpan(ctx, w.span, hook, "watch", w.kind, nil)
+	defer close(w.result)
+
+	for event := range w.Interface.ResultChan() {
+		w.recordEvent(event)
+		select {
+		case w.result <- event:
+		case <-w.done:
+			return
+		}
+	}
+}
+
+// recordEvent adds a span event for event, with the trace of its object if it carries one
+func (w *tracedWatch) recordEvent(event watch.Event) {
+	obj, ok := event.Object.(client.Object)
+	if !ok {
+		w.span.AddEvent(string(event.Type))
+		return
+	}
+
+	var attrs []attribute.KeyValue
+	if spanContext, err := traceContextFromObject(obj, w.scheme, w.keys); err == nil && spanContext.IsValid() {
+		attrs = append(attrs, WatchEventTraceIDKey.String(spanContext.TraceID().String()), WatchEventSpanIDKey.String(spanContext.SpanID().String()))
+	}
+	w.span.AddEvent(fmt.Sprintf("%s %s", event.Type, obj.GetName()), trace.WithAttributes(attrs...))
+}
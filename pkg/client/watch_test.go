@@ -0,0 +1,51 @@
+package client
+
+import (
+	"context"
+	"testing"
+
+	"github.com/go-logr/logr"
+	constants "github.com/kubetracer/kubetracer-go/pkg/constants"
+	"github.com/stretchr/testify/assert"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/watch"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestWatch(t *testing.T) {
+	k8sClient := fake.NewClientBuilder().Build()
+	recorder := tracetest.NewSpanRecorder()
+	tracer := sdktrace.NewTracerProvider(sdktrace.WithSampler(sdktrace.AlwaysSample()), sdktrace.WithSpanProcessor(recorder)).Tracer("kubetracer")
+	tracingClient := NewTracingClientWithWatch(k8sClient, k8sClient, tracer, logr.Discard())
+
+	ctx := context.Background()
+	w, err := tracingClient.Watch(ctx, &corev1.PodList{})
+	assert.NoError(t, err)
+
+	pod := &corev1.Pod{ObjectMeta: metav1.ObjectMeta{
+		Name:      "test-pod",
+		Namespace: "default",
+		Annotations: map[string]string{
+			constants.TraceIDAnnotation: "f620f5cad0af940c294f980c5366a6a1",
+			constants.SpanIDAnnotation:  "45f359cdc1c8ab06",
+		},
+	}}
+	assert.NoError(t, k8sClient.Create(ctx, pod))
+
+	event := <-w.ResultChan()
+	assert.Equal(t, watch.Added, event.Type)
+	w.Stop()
+	for range w.ResultChan() {
+	}
+
+	spans := recorder.Ended()
+	assert.Len(t, spans, 1)
+	assert.Equal(t, "Watch Pod", spans[0].Name())
+	assert.Len(t, spans[0].Events(), 1)
+	assert.Equal(t, "ADDED test-pod", spans[0].Events()[0].Name)
+	assert.Contains(t, spans[0].Events()[0].Attributes, WatchEventTraceIDKey.String("f620f5cad0af940c294f980c5366a6a1"))
+	assert.Contains(t, spans[0].Events()[0].Attributes, WatchEventSpanIDKey.String("45f359cdc1c8ab06"))
+}
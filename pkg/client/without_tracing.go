@@ -0,0 +1,30 @@
+package client
+
+import (
+	"context"
+)
+
+type withoutTracingKey struct{}
+
+// WithoutTracing returns a copy of ctx making the TracingClient pass the calls made with it straight to the
+// underlying Client, without spans and without writing the trace to the objects.  Use it for high-frequency
+// housekeeping writes, such as heartbeats, which should neither show up in traces nor churn annotations.
+// It applies to the operations of client.Client, its StatusWriter and SubResourceClients, and Watch.
+func WithoutTracing(ctx context.Context) context.Context {
+	return context.WithValue(ctx, withoutTracingKey{}, true)
+}
+
+// tracingDisabled reports whether ctx was returned by WithoutTracing
+func tracingDisabled(ctx context.Context) bool {
+	disabled, _ := ctx.Value(withoutTracingKey{}).(bool)
+	return disabled
+}
+
+// untraced drops the options of the TracingClient from opts, for calls passed straight to the Client
+func untraced[O any](opts []O) []O {
+	opts, _ = splitSpanOptions(opts)
+	opts, _ = splitWriteOptions(opts)
+	opts, _ = splitReadOptions(opts)
+	opts, _ = splitAffectedCount(opts)
+	return opts
+}
@@ -0,0 +1,48 @@
+package client
+
+import (
+	"context"
+	"testing"
+
+	"github.com/go-logr/logr"
+	"github.com/stretchr/testify/assert"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestWithoutTracing(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	tracer := sdktrace.NewTracerProvider(sdktrace.WithSampler(sdktrace.AlwaysSample()), sdktrace.WithSpanProcessor(recorder)).Tracer("kubetracer")
+	k8sClient := fake.NewClientBuilder().Build()
+	tracingClient := NewTracingClient(k8sClient, k8sClient, tracer, logr.Discard())
+
+	// a trace in the context is not carried over to the objects either
+	ctx, parent := tracer.Start(context.Background(), "Reconcile")
+	ctx = WithoutTracing(ctx)
+
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "heartbeat-pod",
+			Namespace: "default",
+		},
+	}
+	assert.NoError(t, tracingClient.Create(ctx, pod, WithTraceLabel()))
+	pod.Labels = map[string]string{"heartbeat": "1"}
+	assert.NoError(t, tracingClient.Update(ctx, pod))
+	assert.NoError(t, tracingClient.Status().Update(ctx, pod))
+
+	retrievedPod := &corev1.Pod{}
+	assert.NoError(t, tracingClient.Get(ctx, client.ObjectKeyFromObject(pod), retrievedPod))
+	assert.Empty(t, retrievedPod.Annotations)
+	assert.Equal(t, map[string]string{"heartbeat": "1"}, retrievedPod.Labels)
+	assert.NoError(t, tracingClient.List(ctx, &corev1.PodList{}))
+	parent.End()
+
+	spans := recorder.Ended()
+	assert.Len(t, spans, 1)
+	assert.Equal(t, "Reconcile", spans[0].Name())
+}
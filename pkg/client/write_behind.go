@@ -0,0 +1,106 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"go.opentelemetry.io/otel/trace"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// DefaultWriteBehindInterval is how often WriteBehind flushes the queued EndTrace cleanups
+const DefaultWriteBehindInterval = time.Second
+
+// WriteBehind queues the EndTrace cleanup of objects and performs it in the background, so reconciles of
+// write-heavy controllers do not wait on the cleanup patches.  Cleanups queued for the same object before a
+// flush are coalesced, the latest wins.  The trade-off is that the trace annotations stay on the objects for
+// up to the flush interval.
+//
+// WriteBehind is a manager Runnable, the queue is flushed one last time when the manager stops:
+//
+//	writeBehind := kubetracer.NewWriteBehind(tracingClient, 0)
+//	mgr.Add(writeBehind)
+//	...
+//	writeBehind.EndTrace(ctx, obj)
+type WriteBehind struct {
+	tracingClient TracingClient
+	interval      time.Duration
+
+	mu      sync.Mutex
+	pending map[objectIdentity]pendingEndTrace
+}
+
+// pendingEndTrace is a queued EndTrace, with the span context of the caller to parent its span
+type pendingEndTrace struct {
+	obj         client.Object
+	spanContext trace.SpanContext
+}
+
+// NewWriteBehind returns a WriteBehind flushing through tc every interval, DefaultWriteBehindInterval if zero
+func NewWriteBehind(tc TracingClient, interval time.Duration) *WriteBehind {
+	if interval == 0 {
+		interval = DefaultWriteBehindInterval
+	}
+	return &WriteBehind{
+		tracingClient: tc,
+		interval:      interval,
+		pending:       map[objectIdentity]pendingEndTrace{},
+	}
+}
+
+// EndTrace queues the EndTrace of obj and returns immediately.  obj is copied, the caller keeps ownership.
+func (w *WriteBehind) EndTrace(ctx context.Context, obj client.Object) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.pending[identityOf(obj)] = pendingEndTrace{
+		obj:         obj.DeepCopyObject().(client.Object),
+		spanContext: trace.SpanContextFromContext(ctx),
+	}
+}
+
+// Flush performs the queued EndTraces
+func (w *WriteBehind) Flush(ctx context.Context) error {
+	w.mu.Lock()
+	pending := w.pending
+	w.pending = map[objectIdentity]pendingEndTrace{}
+	w.mu.Unlock()
+
+	var errs []error
+	for _, p := range pending {
+		if ctx.Err() != nil {
+			errs = append(errs, ctx.Err())
+			break
+		}
+		if _, err := w.tracingClient.EndTrace(trace.ContextWithSpanContext(ctx, p.spanContext), p.obj); err != nil {
+			errs = append(errs, fmt.Errorf("problem ending the trace of %s: %w", p.obj.GetName(), err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Start implements manager.Runnable.  It flushes the queue every interval until ctx is done, then once more.
+func (w *WriteBehind) Start(ctx context.Context) error {
+	ticker := time.NewTicker(w.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			// failed cleanups are not retried, the annotations are replaced by the next trace
+			w.Flush(ctx)
+		case <-ctx.Done():
+			// ctx is already done, the last flush gets its own deadline
+			shutdownCtx, cancel := context.WithTimeout(context.Background(), DefaultShutdownTimeout)
+			defer cancel()
+			return w.Flush(shutdownCtx)
+		}
+	}
+}
+
+// NeedLeaderElection implements manager.LeaderElectionRunnable.  Every replica queues cleanups.
+func (w *WriteBehind) NeedLeaderElection() bool {
+	return false
+}
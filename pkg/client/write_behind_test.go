@@ -0,0 +1,51 @@
+package client
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/go-logr/logr"
+	"github.com/kubetracer/kubetracer-go/pkg/constants"
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestWriteBehind(t *testing.T) {
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "test-pod",
+			Namespace: "default",
+			Annotations: map[string]string{
+				constants.TraceIDAnnotation: "f620f5cad0af940c294f980c5366a6a1",
+				constants.SpanIDAnnotation:  "45f359cdc1c8ab06",
+			},
+		},
+	}
+	k8sClient := fake.NewClientBuilder().WithObjects(pod).Build()
+	tracingClient := NewTracingClient(k8sClient, k8sClient, initTracer(), logr.Discard())
+
+	// The interval is long enough for the test to only flush on shutdown
+	writeBehind := NewWriteBehind(tracingClient, time.Hour)
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+	go func() { done <- writeBehind.Start(ctx) }()
+
+	writeBehind.EndTrace(context.Background(), pod)
+	writeBehind.EndTrace(context.Background(), pod)
+
+	// The cleanup is queued
+	retrievedPod := &corev1.Pod{}
+	assert.NoError(t, k8sClient.Get(context.Background(), client.ObjectKeyFromObject(pod), retrievedPod))
+	assert.Equal(t, "f620f5cad0af940c294f980c5366a6a1", retrievedPod.Annotations[constants.TraceIDAnnotation])
+	assert.Len(t, writeBehind.pending, 1)
+
+	// Stopping flushes the queue
+	cancel()
+	assert.NoError(t, <-done)
+	assert.NoError(t, k8sClient.Get(context.Background(), client.ObjectKeyFromObject(pod), retrievedPod))
+	assert.Empty(t, retrievedPod.Annotations[constants.TraceIDAnnotation])
+}
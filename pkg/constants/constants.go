@@ -1,7 +1,26 @@
 package constants
 
 const (
-	TraceIDAnnotation  = "kubetracer.io/trace-id"
-	SpanIDAnnotation   = "kubetracer.io/span-id"
-	ResourceVersionKey = "resourceVersion"
+	TraceIDAnnotation       = "kubetracer.io/trace-id"
+	SpanIDAnnotation        = "kubetracer.io/span-id"
+	TriggeredByAnnotation   = "kubetracer.io/triggered-by"
+	TraceRootAnnotation     = "kubetracer.io/trace-root"
+	TraceRootKindAnnotation = "kubetracer.io/trace-root-kind"
+	TraceRootNameAnnotation = "kubetracer.io/trace-root-name"
+	ActorAnnotation         = "kubetracer.io/actor"
+	LastOpsAnnotation       = "kubetracer.io/last-ops"
+	TraceLabel              = "kubetracer.io/trace"
+	// TraceTimeAnnotation holds when the trace was attached to the object, in RFC 3339 format
+	TraceTimeAnnotation = "kubetracer.io/trace-time"
+	// AnnotationPrefix prefixes the annotations above
+	AnnotationPrefix = "kubetracer.io/"
+	// PropagatorAnnotationPrefix prefixes the fields of the OTel propagator, e.g. otel.kubetracer.io/traceparent.
+	// It is distinct from AnnotationPrefix so that only the fields of the propagator carry it.
+	PropagatorAnnotationPrefix = "otel.kubetracer.io/"
+	ResourceVersionKey         = "resourceVersion"
+	// TraceContextCondition is the type of the status condition holding the W3C traceparent, written instead of
+	// the TraceID and SpanID conditions given the SingleTraceContextCondition format
+	TraceContextCondition = "kubetracer.io/TraceContext"
+	// TraceparentDataKey holds the traceparent in the data of ConfigMaps and Secrets
+	TraceparentDataKey = "kubetracer.traceparent"
 )
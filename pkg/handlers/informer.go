@@ -0,0 +1,68 @@
+package handler
+
+import (
+	"context"
+	"fmt"
+
+	kubetracer "github.com/kubetracer/kubetracer-go/pkg/client"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+	toolscache "k8s.io/client-go/tools/cache"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// InitialListAttributeKey is the span attribute telling whether an Add comes from the initial list of the informer
+const InitialListAttributeKey = attribute.Key("kubetracer.informer.initial_list")
+
+var _ toolscache.ResourceEventHandler = &TracedResourceEventHandler{}
+
+// TracedResourceEventHandler wraps the ResourceEventHandler of a raw client-go informer, run alongside
+// controller-runtime, with a short span per Add, Update and Delete callback.  The span continues the trace
+// carried by the annotations of the object, so the informer shows up in the same trace as the controllers.
+type TracedResourceEventHandler struct {
+	Handler toolscache.ResourceEventHandler
+	Tracer  trace.Tracer
+}
+
+// OnAdd implements toolscache.ResourceEventHandler
+func (h *TracedResourceEventHandler) OnAdd(obj interface{}, isInInitialList bool) {
+	span := h.startSpan("OnAdd", obj, InitialListAttributeKey.Bool(isInInitialList))
+	defer span.End()
+	h.Handler.OnAdd(obj, isInInitialList)
+}
+
+// OnUpdate implements toolscache.ResourceEventHandler
+func (h *TracedResourceEventHandler) OnUpdate(oldObj, newObj interface{}) {
+	span := h.startSpan("OnUpdate", newObj)
+	defer span.End()
+	h.Handler.OnUpdate(oldObj, newObj)
+}
+
+// OnDelete implements toolscache.ResourceEventHandler
+func (h *TracedResourceEventHandler) OnDelete(obj interface{}) {
+	span := h.startSpan("OnDelete", obj)
+	defer span.End()
+	h.Handler.OnDelete(obj)
+}
+
+// startSpan starts the span of a callback for obj, continuing the trace of obj if it carries one
+func (h *TracedResourceEventHandler) startSpan(callback string, obj interface{}, attrs ...attribute.KeyValue) trace.Span {
+	if tombstone, ok := obj.(toolscache.DeletedFinalStateUnknown); ok {
+		obj = tombstone.Obj
+	}
+
+	ctx := context.Background()
+	name := callback
+	if clientObj, ok := obj.(client.Object); ok {
+		if spanContext, err := kubetracer.TraceContextFromObject(clientObj, nil); err == nil {
+			ctx = trace.ContextWithRemoteSpanContext(ctx, spanContext)
+		}
+		name = fmt.Sprintf("%s %s", callback, clientObj.GetName())
+		if kind := clientObj.GetObjectKind().GroupVersionKind().Kind; kind != "" {
+			name = fmt.Sprintf("%s %s %s", callback, kind, clientObj.GetName())
+		}
+	}
+
+	_, span := h.Tracer.Start(ctx, name, trace.WithAttributes(attrs...))
+	return span
+}
@@ -0,0 +1,56 @@
+package handler_test
+
+import (
+	"testing"
+
+	"github.com/kubetracer/kubetracer-go/pkg/constants"
+	handler "github.com/kubetracer/kubetracer-go/pkg/handlers"
+	"github.com/stretchr/testify/assert"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	toolscache "k8s.io/client-go/tools/cache"
+)
+
+func TestTracedResourceEventHandler(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	tracer := sdktrace.NewTracerProvider(sdktrace.WithSampler(sdktrace.AlwaysSample()), sdktrace.WithSpanProcessor(recorder)).Tracer("kubetracer")
+
+	var calls []string
+	tracedHandler := &handler.TracedResourceEventHandler{
+		Handler: toolscache.ResourceEventHandlerFuncs{
+			AddFunc:    func(interface{}) { calls = append(calls, "add") },
+			UpdateFunc: func(interface{}, interface{}) { calls = append(calls, "update") },
+			DeleteFunc: func(interface{}) { calls = append(calls, "delete") },
+		},
+		Tracer: tracer,
+	}
+
+	pod := &corev1.Pod{
+		TypeMeta: metav1.TypeMeta{Kind: "Pod", APIVersion: "v1"},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "informer-pod",
+			Namespace: "default",
+			Annotations: map[string]string{
+				constants.TraceIDAnnotation: "f620f5cad0af940c294f980c5366a6a1",
+				constants.SpanIDAnnotation:  "45f359cdc1c8ab06",
+			},
+		},
+	}
+	tracedHandler.OnAdd(pod, true)
+	tracedHandler.OnUpdate(pod, pod)
+	tracedHandler.OnDelete(toolscache.DeletedFinalStateUnknown{Key: "default/informer-pod", Obj: pod})
+	assert.Equal(t, []string{"add", "update", "delete"}, calls)
+
+	spans := recorder.Ended()
+	assert.Len(t, spans, 3)
+	var names []string
+	for _, span := range spans {
+		names = append(names, span.Name())
+		assert.Equal(t, "f620f5cad0af940c294f980c5366a6a1", span.SpanContext().TraceID().String())
+		assert.Equal(t, "45f359cdc1c8ab06", span.Parent().SpanID().String())
+	}
+	assert.Equal(t, []string{"OnAdd Pod informer-pod", "OnUpdate Pod informer-pod", "OnDelete Pod informer-pod"}, names)
+	assert.Contains(t, spans[0].Attributes(), handler.InitialListAttributeKey.Bool(true))
+}
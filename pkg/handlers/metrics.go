@@ -0,0 +1,38 @@
+package handler
+
+import (
+	"context"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+)
+
+const meterName = "github.com/kubetracer/kubetracer-go/pkg/handlers"
+
+// Reasons for which a handler could not enqueue a Request for an Event.
+const (
+	dropReasonParseGroupVersion = "parse_group_version"
+	dropReasonGVK               = "gvk"
+	dropReasonRESTMapping       = "rest_mapping"
+	dropReasonOwnerLookup       = "owner_lookup"
+	dropReasonList              = "list"
+)
+
+// droppedRequests counts the Events for which a handler could not enqueue a Request, by reason.
+// Instruments created from the global MeterProvider are forwarded to the provider set with
+// otel.SetMeterProvider, so this is a no-op until the binary configures one.
+var droppedRequests, _ = otel.Meter(meterName).Int64Counter(
+	"kubetracer.handler.dropped_requests",
+	metric.WithDescription("Number of events for which a kubetracer handler could not enqueue a request."),
+	metric.WithUnit("{event}"),
+)
+
+// recordDroppedRequest increments droppedRequests.  It is only called on failure paths.
+func recordDroppedRequest(ctx context.Context, handler, kind, reason string) {
+	droppedRequests.Add(ctx, 1, metric.WithAttributes(
+		attribute.String("handler", handler),
+		attribute.String("kind", kind),
+		attribute.String("reason", reason),
+	))
+}
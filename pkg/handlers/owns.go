@@ -9,6 +9,7 @@ import (
 	"context"
 	"fmt"
 
+	"github.com/go-logr/logr"
 	"github.com/kubetracer/kubetracer-go/pkg/constants"
 	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -66,6 +67,10 @@ func TypedEnqueueRequestForOwner[object client.Object](scheme *runtime.Scheme, m
 		ownerType: ownerType,
 		mapper:    mapper,
 		scheme:    scheme,
+		logger:    logr.Discard(),
+
+		traceIDKey: constants.TraceIDAnnotation,
+		spanIDKey:  constants.SpanIDAnnotation,
 	}
 	if err := e.parseOwnerTypeGroupKind(scheme); err != nil {
 		panic(err)
@@ -83,8 +88,56 @@ func OnlyControllerOwner() OwnerOption {
 	}
 }
 
+// WithTransitiveOwners if provided will walk up to maxDepth levels of OwnerReferences looking for an
+// Owner that matches OwnerType, using reader to fetch the intermediate Owners.  The enqueued Request
+// carries the trace of the object that was the source of the Event.
+//
+// If a Deployment controller watches Pods, the Pod's ReplicaSet is fetched and its Deployment Owner
+// is enqueued with a maxDepth of 2.
+func WithTransitiveOwners(reader client.Reader, maxDepth int) OwnerOption {
+	return func(e enqueueRequestForOwnerInterface) {
+		e.setTransitiveOwners(reader, maxDepth)
+	}
+}
+
+// InNamespaces if provided will only enqueue Requests for Events on objects in one of namespaces.  No namespaces
+// means no restriction.
+func InNamespaces(namespaces ...string) OwnerOption {
+	return func(e enqueueRequestForOwnerInterface) {
+		e.setAllowedNamespaces(namespaces)
+	}
+}
+
+// NotInNamespaces if provided will skip Events on objects in any of namespaces.
+func NotInNamespaces(namespaces ...string) OwnerOption {
+	return func(e enqueueRequestForOwnerInterface) {
+		e.setDeniedNamespaces(namespaces)
+	}
+}
+
+// WithLogger if provided will log the Events for which a Request could not be enqueued.
+func WithLogger(logger logr.Logger) OwnerOption {
+	return func(e enqueueRequestForOwnerInterface) {
+		e.setLogger(logger)
+	}
+}
+
+// WithTraceAnnotations if provided reads the trace of the object that was the source of the Event from the
+// traceIDKey and spanIDKey annotations instead of kubetracer.io/trace-id and kubetracer.io/span-id, for
+// TracingClients configured with WithAnnotationPrefix, WithTraceIDAnnotation or WithSpanIDAnnotation.
+func WithTraceAnnotations(traceIDKey, spanIDKey string) OwnerOption {
+	return func(e enqueueRequestForOwnerInterface) {
+		e.setTraceAnnotations(traceIDKey, spanIDKey)
+	}
+}
+
 type enqueueRequestForOwnerInterface interface {
 	setIsController(bool)
+	setLogger(logr.Logger)
+	setTraceAnnotations(string, string)
+	setTransitiveOwners(client.Reader, int)
+	setAllowedNamespaces([]string)
+	setDeniedNamespaces([]string)
 }
 
 type enqueueRequestForOwner[object client.Object] struct {
@@ -102,16 +155,57 @@ type enqueueRequestForOwner[object client.Object] struct {
 
 	// scheme is used to get the GroupVersionKind of the object
 	scheme *runtime.Scheme
+
+	// ownerReader if set is used to fetch intermediate Owners when walking up the OwnerReferences
+	ownerReader client.Reader
+
+	// maxOwnerDepth is the number of OwnerReference levels to walk up looking for OwnerType
+	maxOwnerDepth int
+
+	// allowedNamespaces if set restricts Events to objects in these namespaces
+	allowedNamespaces map[string]empty
+
+	// deniedNamespaces if set skips Events on objects in these namespaces
+	deniedNamespaces map[string]empty
+
+	// logger logs the Events for which a Request could not be enqueued
+	logger logr.Logger
+
+	// traceIDKey and spanIDKey are the annotations the trace is read from
+	traceIDKey string
+	spanIDKey  string
 }
 
 func (e *enqueueRequestForOwner[object]) setIsController(isController bool) {
 	e.isController = isController
 }
 
+func (e *enqueueRequestForOwner[object]) setLogger(logger logr.Logger) {
+	e.logger = logger
+}
+
+func (e *enqueueRequestForOwner[object]) setTraceAnnotations(traceIDKey, spanIDKey string) {
+	e.traceIDKey = traceIDKey
+	e.spanIDKey = spanIDKey
+}
+
+func (e *enqueueRequestForOwner[object]) setTransitiveOwners(reader client.Reader, maxDepth int) {
+	e.ownerReader = reader
+	e.maxOwnerDepth = maxDepth
+}
+
+func (e *enqueueRequestForOwner[object]) setAllowedNamespaces(namespaces []string) {
+	e.allowedNamespaces = toSet(namespaces)
+}
+
+func (e *enqueueRequestForOwner[object]) setDeniedNamespaces(namespaces []string) {
+	e.deniedNamespaces = toSet(namespaces)
+}
+
 // Create implements EventHandler.
 func (e *enqueueRequestForOwner[object]) Create(ctx context.Context, evt event.TypedCreateEvent[object], q workqueue.TypedRateLimitingInterface[reconcile.Request]) {
 	reqs := map[requestWithTraceID]empty{}
-	e.getOwnerReconcileRequest(evt.Object, reqs, "new")
+	e.getOwnerReconcileRequest(ctx, evt.Object, reqs, "new")
 	res := requestWithTraceIDToRequest(reqs)
 	for req := range res {
 		q.Add(req)
@@ -121,8 +215,8 @@ func (e *enqueueRequestForOwner[object]) Create(ctx context.Context, evt event.T
 // Update implements EventHandler.
 func (e *enqueueRequestForOwner[object]) Update(ctx context.Context, evt event.TypedUpdateEvent[object], q workqueue.TypedRateLimitingInterface[reconcile.Request]) {
 	reqs := map[requestWithTraceID]empty{}
-	e.getOwnerReconcileRequest(evt.ObjectOld, reqs, "old")
-	e.getOwnerReconcileRequest(evt.ObjectNew, reqs, "new")
+	e.getOwnerReconcileRequest(ctx, evt.ObjectOld, reqs, "old")
+	e.getOwnerReconcileRequest(ctx, evt.ObjectNew, reqs, "new")
 	res := requestWithTraceIDToRequest(reqs)
 	for req := range res {
 		q.Add(req)
@@ -132,7 +226,7 @@ func (e *enqueueRequestForOwner[object]) Update(ctx context.Context, evt event.T
 // Delete implements EventHandler.
 func (e *enqueueRequestForOwner[object]) Delete(ctx context.Context, evt event.TypedDeleteEvent[object], q workqueue.TypedRateLimitingInterface[reconcile.Request]) {
 	reqs := map[requestWithTraceID]empty{}
-	e.getOwnerReconcileRequest(evt.Object, reqs, "new")
+	e.getOwnerReconcileRequest(ctx, evt.Object, reqs, "new")
 	res := requestWithTraceIDToRequest(reqs)
 	for req := range res {
 		q.Add(req)
@@ -142,7 +236,7 @@ func (e *enqueueRequestForOwner[object]) Delete(ctx context.Context, evt event.T
 // Generic implements EventHandler.
 func (e *enqueueRequestForOwner[object]) Generic(ctx context.Context, evt event.TypedGenericEvent[object], q workqueue.TypedRateLimitingInterface[reconcile.Request]) {
 	reqs := map[requestWithTraceID]empty{}
-	e.getOwnerReconcileRequest(evt.Object, reqs, "new")
+	e.getOwnerReconcileRequest(ctx, evt.Object, reqs, "new")
 	res := requestWithTraceIDToRequest(reqs)
 	for req := range res {
 		q.Add(req)
@@ -169,28 +263,57 @@ func (e *enqueueRequestForOwner[object]) parseOwnerTypeGroupKind(scheme *runtime
 
 // getOwnerReconcileRequest looks at object and builds a map of reconcile.Request to reconcile
 // owners of object that match e.OwnerType.
-func (e *enqueueRequestForOwner[object]) getOwnerReconcileRequest(obj metav1.Object, result map[requestWithTraceID]empty, eventKind string) {
+func (e *enqueueRequestForOwner[object]) getOwnerReconcileRequest(ctx context.Context, obj metav1.Object, result map[requestWithTraceID]empty, eventKind string) {
+	if obj == nil || !e.isNamespaceAllowed(obj.GetNamespace()) {
+		return
+	}
+
+	runtimeObj, _ := obj.(runtime.Object)
+	gvk, err := apiutil.GVKForObject(runtimeObj, e.scheme)
+	if err != nil {
+		e.logger.Error(err, "Could not retrieve GVK for object", "object", obj.GetName())
+		e.recordDroppedRequest(ctx, dropReasonGVK)
+		return
+	}
+	kind := gvk.GroupKind().Kind
+
+	e.getOwnerReconcileRequestFromOwners(ctx, obj, kind, e.getOwnersReferences(obj), result, eventKind, 1)
+}
+
+// recordDroppedRequest records an Event for which a Request could not be enqueued.
+func (e *enqueueRequestForOwner[object]) recordDroppedRequest(ctx context.Context, reason string) {
+	recordDroppedRequest(ctx, "EnqueueRequestForOwner", e.groupKind.Kind, reason)
+}
+
+// isNamespaceAllowed checks namespace against the allowed and denied namespaces.
+func (e *enqueueRequestForOwner[object]) isNamespaceAllowed(namespace string) bool {
+	if _, denied := e.deniedNamespaces[namespace]; denied {
+		return false
+	}
+	if e.allowedNamespaces == nil {
+		return true
+	}
+	_, allowed := e.allowedNamespaces[namespace]
+	return allowed
+}
+
+// getOwnerReconcileRequestFromOwners builds a reconcile.Request for each of refs that match e.OwnerType.
+// The requests carry the trace of obj, the object that was the source of the Event.  If transitive
+// owners are enabled, refs that do not match are fetched and their own OwnerReferences are searched
+// until maxOwnerDepth is reached.
+func (e *enqueueRequestForOwner[object]) getOwnerReconcileRequestFromOwners(ctx context.Context, obj metav1.Object, kind string, refs []metav1.OwnerReference, result map[requestWithTraceID]empty, eventKind string, depth int) {
 	// Iterate through the OwnerReferences looking for a match on Group and Kind against what was requested
 	// by the user
-	for _, ref := range e.getOwnersReferences(obj) {
+	for _, ref := range refs {
 		// Parse the Group out of the OwnerReference to compare it to what was parsed out of the requested OwnerType
 		refGV, err := schema.ParseGroupVersion(ref.APIVersion)
 		if err != nil {
-			// log.Error(err, "Could not parse OwnerReference APIVersion",
-			// 	"api version", ref.APIVersion)
+			e.logger.Error(err, "Could not parse OwnerReference APIVersion",
+				"api version", ref.APIVersion)
+			e.recordDroppedRequest(ctx, dropReasonParseGroupVersion)
 			return
 		}
 
-		runtimeObj, _ := obj.(runtime.Object)
-		gvk, err := apiutil.GVKForObject(runtimeObj, e.scheme)
-		kind := ""
-		if err != nil {
-			// log.Error(err, "Could not retrieve GVK for object", "object", obj)
-			return
-		} else {
-			kind = gvk.GroupKind().Kind
-		}
-
 		// Compare the OwnerReference Group and Kind against the OwnerType Group and Kind specified by the user.
 		// If the two match, create a Request for the objected referred to by
 		// the OwnerReference.  Use the Name from the OwnerReference and the Namespace from the
@@ -208,15 +331,16 @@ func (e *enqueueRequestForOwner[object]) getOwnerReconcileRequest(obj metav1.Obj
 			// if owner is not namespaced then we should not set the namespace
 			mapping, err := e.mapper.RESTMapping(e.groupKind, refGV.Version)
 			if err != nil {
-				// log.Error(err, "Could not retrieve rest mapping", "kind", e.groupKind)
+				e.logger.Error(err, "Could not retrieve rest mapping", "kind", e.groupKind)
+				e.recordDroppedRequest(ctx, dropReasonRESTMapping)
 				return
 			}
 			if mapping.Scope.Name() != meta.RESTScopeNameRoot {
 				request.NamespacedName.Namespace = obj.GetNamespace()
 			}
 
-			traceId := obj.GetAnnotations()[constants.TraceIDAnnotation]
-			spanId := obj.GetAnnotations()[constants.SpanIDAnnotation]
+			traceId := obj.GetAnnotations()[e.traceIDKey]
+			spanId := obj.GetAnnotations()[e.spanIDKey]
 			senderName := obj.GetName()
 			senderKind := kind
 
@@ -230,10 +354,41 @@ func (e *enqueueRequestForOwner[object]) getOwnerReconcileRequest(obj metav1.Obj
 			request.SenderKind = senderKind
 
 			result[request] = empty{}
+		} else if e.ownerReader != nil && depth < e.maxOwnerDepth {
+			// No match - look for OwnerType among the Owners of the object referred to in the OwnerReference
+			owner, err := e.getOwner(ctx, obj, ref, refGV)
+			if err != nil {
+				e.logger.Error(err, "Could not retrieve owner", "owner", ref.Name, "kind", ref.Kind)
+				e.recordDroppedRequest(ctx, dropReasonOwnerLookup)
+				continue
+			}
+			e.getOwnerReconcileRequestFromOwners(ctx, obj, kind, e.getOwnersReferences(owner), result, eventKind, depth+1)
 		}
 	}
 }
 
+// getOwner fetches the metadata of the object referred to by ref.  Namespaced Owners are looked up in
+// the Namespace of obj, since an OwnerReference cannot cross Namespaces.
+func (e *enqueueRequestForOwner[object]) getOwner(ctx context.Context, obj metav1.Object, ref metav1.OwnerReference, refGV schema.GroupVersion) (metav1.Object, error) {
+	refGK := schema.GroupKind{Group: refGV.Group, Kind: ref.Kind}
+	mapping, err := e.mapper.RESTMapping(refGK, refGV.Version)
+	if err != nil {
+		return nil, err
+	}
+
+	key := client.ObjectKey{Name: ref.Name}
+	if mapping.Scope.Name() != meta.RESTScopeNameRoot {
+		key.Namespace = obj.GetNamespace()
+	}
+
+	owner := &metav1.PartialObjectMetadata{}
+	owner.SetGroupVersionKind(refGV.WithKind(ref.Kind))
+	if err := e.ownerReader.Get(ctx, key, owner); err != nil {
+		return nil, err
+	}
+	return owner, nil
+}
+
 // Converts the reqeustWithTraceID map to a request map and uses the EmbedTraceIDInNamespacedName function to set the name
 func requestWithTraceIDToRequest(requests map[requestWithTraceID]empty) map[reconcile.Request]empty {
 	result := map[reconcile.Request]empty{}
@@ -284,3 +439,15 @@ func (e *enqueueRequestForOwner[object]) getOwnersReferences(obj metav1.Object)
 	// No Controller OwnerReference found
 	return nil
 }
+
+// toSet converts a list of strings into a set, nil if values is empty
+func toSet(values []string) map[string]empty {
+	if len(values) == 0 {
+		return nil
+	}
+	set := make(map[string]empty, len(values))
+	for _, value := range values {
+		set[value] = empty{}
+	}
+	return set
+}
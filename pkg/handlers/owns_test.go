@@ -0,0 +1,192 @@
+package handler_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/go-logr/logr/funcr"
+	kubetracer "github.com/kubetracer/kubetracer-go/pkg/client"
+	"github.com/kubetracer/kubetracer-go/pkg/constants"
+	handler "github.com/kubetracer/kubetracer-go/pkg/handlers"
+	"github.com/kubetracer/kubetracer-go/pkg/predicates"
+	"github.com/stretchr/testify/assert"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/util/workqueue"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+func newRESTMapper() meta.RESTMapper {
+	mapper := meta.NewDefaultRESTMapper(nil)
+	mapper.Add(corev1.SchemeGroupVersion.WithKind("Pod"), meta.RESTScopeNamespace)
+	mapper.Add(appsv1.SchemeGroupVersion.WithKind("ReplicaSet"), meta.RESTScopeNamespace)
+	mapper.Add(appsv1.SchemeGroupVersion.WithKind("Deployment"), meta.RESTScopeNamespace)
+	return mapper
+}
+
+func newQueue() workqueue.TypedRateLimitingInterface[reconcile.Request] {
+	return workqueue.NewTypedRateLimitingQueue(workqueue.DefaultTypedControllerRateLimiter[reconcile.Request]())
+}
+
+func ownerReference(apiVersion, kind, name string) metav1.OwnerReference {
+	isController := true
+	return metav1.OwnerReference{
+		APIVersion: apiVersion,
+		Kind:       kind,
+		Name:       name,
+		Controller: &isController,
+	}
+}
+
+func TestEnqueueRequestForOwner(t *testing.T) {
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "test-pod",
+			Namespace: "default",
+			Annotations: map[string]string{
+				constants.TraceIDAnnotation: "1234",
+				constants.SpanIDAnnotation:  "5678",
+			},
+			OwnerReferences: []metav1.OwnerReference{ownerReference("apps/v1", "ReplicaSet", "test-replicaset")},
+		},
+	}
+
+	t.Run("direct owner", func(t *testing.T) {
+		h := handler.EnqueueRequestForOwner(clientgoscheme.Scheme, newRESTMapper(), &appsv1.ReplicaSet{}, handler.OnlyControllerOwner())
+		q := newQueue()
+		h.Create(context.Background(), event.CreateEvent{Object: pod}, q)
+
+		assert.Equal(t, 1, q.Len())
+		req, _ := q.Get()
+		assert.Equal(t, "1234;5678;Pod;test-pod;test-replicaset", req.Name)
+		assert.Equal(t, "default", req.Namespace)
+	})
+
+	t.Run("transitive owner", func(t *testing.T) {
+		replicaSet := &appsv1.ReplicaSet{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:            "test-replicaset",
+				Namespace:       "default",
+				OwnerReferences: []metav1.OwnerReference{ownerReference("apps/v1", "Deployment", "test-deployment")},
+			},
+		}
+		reader := fake.NewClientBuilder().WithObjects(replicaSet).Build()
+
+		h := handler.EnqueueRequestForOwner(clientgoscheme.Scheme, newRESTMapper(), &appsv1.Deployment{}, handler.WithTransitiveOwners(reader, 2))
+		q := newQueue()
+		h.Create(context.Background(), event.CreateEvent{Object: pod}, q)
+
+		assert.Equal(t, 1, q.Len())
+		req, _ := q.Get()
+		assert.Equal(t, "1234;5678;Pod;test-pod;test-deployment", req.Name)
+		assert.Equal(t, "default", req.Namespace)
+	})
+
+	t.Run("transitive owner beyond max depth", func(t *testing.T) {
+		replicaSet := &appsv1.ReplicaSet{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:            "test-replicaset",
+				Namespace:       "default",
+				OwnerReferences: []metav1.OwnerReference{ownerReference("apps/v1", "Deployment", "test-deployment")},
+			},
+		}
+		reader := fake.NewClientBuilder().WithObjects(replicaSet).Build()
+
+		h := handler.EnqueueRequestForOwner(clientgoscheme.Scheme, newRESTMapper(), &appsv1.Deployment{}, handler.WithTransitiveOwners(reader, 1))
+		q := newQueue()
+		h.Create(context.Background(), event.CreateEvent{Object: pod}, q)
+
+		assert.Equal(t, 0, q.Len())
+	})
+
+	t.Run("namespace not allowed", func(t *testing.T) {
+		h := handler.EnqueueRequestForOwner(clientgoscheme.Scheme, newRESTMapper(), &appsv1.ReplicaSet{}, handler.InNamespaces("other"))
+		q := newQueue()
+		h.Create(context.Background(), event.CreateEvent{Object: pod}, q)
+
+		assert.Equal(t, 0, q.Len())
+	})
+
+	t.Run("namespace denied", func(t *testing.T) {
+		h := handler.EnqueueRequestForOwner(clientgoscheme.Scheme, newRESTMapper(), &appsv1.ReplicaSet{}, handler.NotInNamespaces("default"))
+		q := newQueue()
+		h.Create(context.Background(), event.CreateEvent{Object: pod}, q)
+
+		assert.Equal(t, 0, q.Len())
+	})
+
+	t.Run("namespace allowed", func(t *testing.T) {
+		h := handler.EnqueueRequestForOwner(clientgoscheme.Scheme, newRESTMapper(), &appsv1.ReplicaSet{}, handler.InNamespaces("default"), handler.NotInNamespaces("kube-system"))
+		q := newQueue()
+		h.Create(context.Background(), event.CreateEvent{Object: pod}, q)
+
+		assert.Equal(t, 1, q.Len())
+	})
+
+	t.Run("no namespaces", func(t *testing.T) {
+		h := handler.EnqueueRequestForOwner(clientgoscheme.Scheme, newRESTMapper(), &appsv1.ReplicaSet{}, handler.InNamespaces(), handler.NotInNamespaces())
+		q := newQueue()
+		h.Create(context.Background(), event.CreateEvent{Object: pod}, q)
+
+		assert.Equal(t, 1, q.Len())
+	})
+
+	t.Run("missing rest mapping is logged", func(t *testing.T) {
+		var logged []string
+		logger := funcr.New(func(prefix, args string) {
+			logged = append(logged, args)
+		}, funcr.Options{})
+
+		h := handler.EnqueueRequestForOwner(clientgoscheme.Scheme, meta.NewDefaultRESTMapper(nil), &appsv1.ReplicaSet{}, handler.WithLogger(logger))
+		q := newQueue()
+		h.Create(context.Background(), event.CreateEvent{Object: pod}, q)
+
+		assert.Equal(t, 0, q.Len())
+		assert.Len(t, logged, 1)
+		assert.Contains(t, logged[0], "Could not retrieve rest mapping")
+	})
+}
+
+func TestEnqueueRequestForOwnerCustomAnnotations(t *testing.T) {
+	k8sClient := fake.NewClientBuilder().Build()
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSampler(sdktrace.AlwaysSample()))
+	tracingClient := kubetracer.NewTracingClientWithOptions(k8sClient, kubetracer.WithTracerProvider(tp), kubetracer.WithAnnotationPrefix("example.com/"))
+
+	ctx, span := tp.Tracer("kubetracer").Start(context.Background(), "reconcile")
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:            "test-pod",
+			Namespace:       "default",
+			OwnerReferences: []metav1.OwnerReference{ownerReference("apps/v1", "ReplicaSet", "test-replicaset")},
+		},
+	}
+	assert.NoError(t, tracingClient.Create(ctx, pod))
+	span.End()
+	assert.NoError(t, k8sClient.Get(context.Background(), client.ObjectKeyFromObject(pod), pod))
+	traceID := pod.Annotations["example.com/trace-id"]
+	assert.Equal(t, span.SpanContext().TraceID().String(), traceID)
+	assert.NotContains(t, pod.Annotations, constants.TraceIDAnnotation)
+
+	t.Run("predicate", func(t *testing.T) {
+		assert.False(t, predicates.TraceOnlyPredicate{}.Create(event.CreateEvent{Object: pod}))
+		assert.True(t, predicates.TraceOnlyPredicate{AnnotationPrefix: "example.com/"}.Create(event.CreateEvent{Object: pod}))
+	})
+
+	t.Run("handler", func(t *testing.T) {
+		h := handler.EnqueueRequestForOwner(clientgoscheme.Scheme, newRESTMapper(), &appsv1.ReplicaSet{},
+			handler.WithTraceAnnotations("example.com/trace-id", "example.com/span-id"))
+		q := newQueue()
+		h.Create(context.Background(), event.CreateEvent{Object: pod}, q)
+
+		assert.Equal(t, 1, q.Len())
+		req, _ := q.Get()
+		assert.Equal(t, traceID+";"+pod.Annotations["example.com/span-id"]+";Pod;test-pod;test-replicaset", req.Name)
+	})
+}
@@ -0,0 +1,220 @@
+package handler
+
+import (
+	"context"
+	"strings"
+
+	"github.com/go-logr/logr"
+	"github.com/kubetracer/kubetracer-go/pkg/constants"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/util/workqueue"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/apiutil"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+var _ handler.EventHandler = &enqueueRequestForReferencedObject[client.Object]{}
+
+// EnqueueRequestForReferencedObject enqueues Requests for the objects that reference the object that was the
+// source of the Event by name.  E.g. the custom resources naming a ConfigMap in their spec.
+//
+// The referencing objects are found by listing referencingList with a field index on indexField, whose values
+// must be the names of the referenced objects.  Only objects in the Namespace of the referenced object are listed.
+//
+// If a custom resource references a ConfigMap by name, users may reconcile the custom resource in response to
+// ConfigMap Events using:
+//
+// - a field index on the custom resource that extracts spec.configMapRef.name.
+//
+// - a handler.EnqueueRequestForReferencedObject EventHandler with a referencingList of the custom resource list.
+func EnqueueRequestForReferencedObject(scheme *runtime.Scheme, reader client.Reader, referencingList client.ObjectList, indexField string, opts ...ReferenceOption) handler.EventHandler {
+	return TypedEnqueueRequestForReferencedObject[client.Object](scheme, reader, referencingList, indexField, opts...)
+}
+
+// TypedEnqueueRequestForReferencedObject enqueues Requests for the objects that reference the object that was
+// the source of the Event by name.  E.g. the custom resources naming a ConfigMap in their spec.
+//
+// TypedEnqueueRequestForReferencedObject is experimental and subject to future change.
+func TypedEnqueueRequestForReferencedObject[object client.Object](scheme *runtime.Scheme, reader client.Reader, referencingList client.ObjectList, indexField string, opts ...ReferenceOption) handler.TypedEventHandler[object, reconcile.Request] {
+	e := &enqueueRequestForReferencedObject[object]{
+		scheme:          scheme,
+		reader:          reader,
+		referencingList: referencingList,
+		indexField:      indexField,
+		logger:          logr.Discard(),
+		traceIDKey:      constants.TraceIDAnnotation,
+		spanIDKey:       constants.SpanIDAnnotation,
+	}
+	if gvk, err := apiutil.GVKForObject(referencingList, scheme); err == nil {
+		e.referencingKind = strings.TrimSuffix(gvk.Kind, "List")
+	}
+	for _, opt := range opts {
+		opt(e)
+	}
+	return e
+}
+
+// ReferenceOption modifies an EnqueueRequestForReferencedObject EventHandler.
+type ReferenceOption func(e enqueueRequestForReferencedObjectInterface)
+
+// WithReferenceLogger if provided will log the Events for which the Requests could not be enqueued.
+func WithReferenceLogger(logger logr.Logger) ReferenceOption {
+	return func(e enqueueRequestForReferencedObjectInterface) {
+		e.setLogger(logger)
+	}
+}
+
+// WithReferenceTraceAnnotations if provided reads the trace of the referenced object from the traceIDKey and
+// spanIDKey annotations instead of kubetracer.io/trace-id and kubetracer.io/span-id, see WithTraceAnnotations.
+func WithReferenceTraceAnnotations(traceIDKey, spanIDKey string) ReferenceOption {
+	return func(e enqueueRequestForReferencedObjectInterface) {
+		e.setTraceAnnotations(traceIDKey, spanIDKey)
+	}
+}
+
+type enqueueRequestForReferencedObjectInterface interface {
+	setLogger(logr.Logger)
+	setTraceAnnotations(string, string)
+}
+
+type enqueueRequestForReferencedObject[object client.Object] struct {
+	// scheme is used to get the GroupVersionKind of the object
+	scheme *runtime.Scheme
+
+	// reader is used to list the referencing objects
+	reader client.Reader
+
+	// referencingList is the type of list used to look up the referencing objects
+	referencingList client.ObjectList
+
+	// indexField is the field index holding the names of the referenced objects
+	indexField string
+
+	// referencingKind is the Kind of the referencing objects, the items of referencingList
+	referencingKind string
+
+	// logger logs the Events for which the Requests could not be enqueued
+	logger logr.Logger
+
+	// traceIDKey and spanIDKey are the annotations the trace is read from
+	traceIDKey string
+	spanIDKey  string
+}
+
+func (e *enqueueRequestForReferencedObject[object]) setLogger(logger logr.Logger) {
+	e.logger = logger
+}
+
+func (e *enqueueRequestForReferencedObject[object]) setTraceAnnotations(traceIDKey, spanIDKey string) {
+	e.traceIDKey = traceIDKey
+	e.spanIDKey = spanIDKey
+}
+
+// Create implements EventHandler.
+func (e *enqueueRequestForReferencedObject[object]) Create(ctx context.Context, evt event.TypedCreateEvent[object], q workqueue.TypedRateLimitingInterface[reconcile.Request]) {
+	reqs := map[requestWithTraceID]empty{}
+	e.getReferencingReconcileRequest(ctx, evt.Object, reqs, "new")
+	res := requestWithTraceIDToRequest(reqs)
+	for req := range res {
+		q.Add(req)
+	}
+}
+
+// Update implements EventHandler.
+func (e *enqueueRequestForReferencedObject[object]) Update(ctx context.Context, evt event.TypedUpdateEvent[object], q workqueue.TypedRateLimitingInterface[reconcile.Request]) {
+	reqs := map[requestWithTraceID]empty{}
+	e.getReferencingReconcileRequest(ctx, evt.ObjectOld, reqs, "old")
+	e.getReferencingReconcileRequest(ctx, evt.ObjectNew, reqs, "new")
+	res := requestWithTraceIDToRequest(reqs)
+	for req := range res {
+		q.Add(req)
+	}
+}
+
+// Delete implements EventHandler.
+func (e *enqueueRequestForReferencedObject[object]) Delete(ctx context.Context, evt event.TypedDeleteEvent[object], q workqueue.TypedRateLimitingInterface[reconcile.Request]) {
+	reqs := map[requestWithTraceID]empty{}
+	e.getReferencingReconcileRequest(ctx, evt.Object, reqs, "new")
+	res := requestWithTraceIDToRequest(reqs)
+	for req := range res {
+		q.Add(req)
+	}
+}
+
+// Generic implements EventHandler.
+func (e *enqueueRequestForReferencedObject[object]) Generic(ctx context.Context, evt event.TypedGenericEvent[object], q workqueue.TypedRateLimitingInterface[reconcile.Request]) {
+	reqs := map[requestWithTraceID]empty{}
+	e.getReferencingReconcileRequest(ctx, evt.Object, reqs, "new")
+	res := requestWithTraceIDToRequest(reqs)
+	for req := range res {
+		q.Add(req)
+	}
+}
+
+// getReferencingReconcileRequest lists the objects referencing obj through the field index and builds a map
+// of reconcile.Request to reconcile them, carrying the trace of obj.
+func (e *enqueueRequestForReferencedObject[object]) getReferencingReconcileRequest(ctx context.Context, obj metav1.Object, result map[requestWithTraceID]empty, eventKind string) {
+	runtimeObj, ok := obj.(runtime.Object)
+	if !ok {
+		return
+	}
+	gvk, err := apiutil.GVKForObject(runtimeObj, e.scheme)
+	if err != nil {
+		e.logger.Error(err, "Could not retrieve GVK for object", "object", obj.GetName())
+		e.recordDroppedRequest(ctx, dropReasonGVK)
+		return
+	}
+	kind := gvk.GroupKind().Kind
+
+	list := e.referencingList.DeepCopyObject().(client.ObjectList)
+	if err := e.reader.List(ctx, list, client.InNamespace(obj.GetNamespace()), client.MatchingFields{e.indexField: obj.GetName()}); err != nil {
+		e.logger.Error(err, "Could not list referencing objects", "field", e.indexField, "object", obj.GetName())
+		e.recordDroppedRequest(ctx, dropReasonList)
+		return
+	}
+	items, err := meta.ExtractList(list)
+	if err != nil {
+		e.logger.Error(err, "Could not extract referencing objects", "field", e.indexField, "object", obj.GetName())
+		e.recordDroppedRequest(ctx, dropReasonList)
+		return
+	}
+
+	traceId := obj.GetAnnotations()[e.traceIDKey]
+	spanId := obj.GetAnnotations()[e.spanIDKey]
+
+	for _, item := range items {
+		referencing, err := meta.Accessor(item)
+		if err != nil {
+			continue
+		}
+
+		request := requestWithTraceID{
+			NamespacedName: reconcile.Request{
+				NamespacedName: types.NamespacedName{
+					Name:      referencing.GetName(),
+					Namespace: referencing.GetNamespace(),
+				},
+			},
+			SenderName: obj.GetName(),
+			SenderKind: kind,
+			EventKind:  eventKind,
+		}
+
+		if traceId != "" && spanId != "" {
+			request.TraceID = traceId
+			request.SpanID = spanId
+		}
+
+		result[request] = empty{}
+	}
+}
+
+// recordDroppedRequest records an Event for which the Requests could not be enqueued.
+func (e *enqueueRequestForReferencedObject[object]) recordDroppedRequest(ctx context.Context, reason string) {
+	recordDroppedRequest(ctx, "EnqueueRequestForReferencedObject", e.referencingKind, reason)
+}
@@ -0,0 +1,116 @@
+package handler_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/go-logr/logr/funcr"
+	"github.com/kubetracer/kubetracer-go/pkg/constants"
+	handler "github.com/kubetracer/kubetracer-go/pkg/handlers"
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+)
+
+const configMapVolumeIndex = "spec.volumes.configMap.name"
+
+func configMapVolumeNames(obj client.Object) []string {
+	var names []string
+	for _, volume := range obj.(*corev1.Pod).Spec.Volumes {
+		if volume.ConfigMap != nil {
+			names = append(names, volume.ConfigMap.Name)
+		}
+	}
+	return names
+}
+
+func podWithConfigMapVolume(name, namespace, configMapName string) *corev1.Pod {
+	return &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: namespace,
+		},
+		Spec: corev1.PodSpec{
+			Volumes: []corev1.Volume{
+				{
+					Name: "config",
+					VolumeSource: corev1.VolumeSource{
+						ConfigMap: &corev1.ConfigMapVolumeSource{
+							LocalObjectReference: corev1.LocalObjectReference{Name: configMapName},
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func TestEnqueueRequestForReferencedObject(t *testing.T) {
+	reader := fake.NewClientBuilder().
+		WithObjects(
+			podWithConfigMapVolume("referencing-pod", "default", "test-configmap"),
+			podWithConfigMapVolume("other-pod", "default", "other-configmap"),
+			podWithConfigMapVolume("other-namespace-pod", "other", "test-configmap"),
+		).
+		WithIndex(&corev1.Pod{}, configMapVolumeIndex, configMapVolumeNames).
+		Build()
+
+	h := handler.EnqueueRequestForReferencedObject(clientgoscheme.Scheme, reader, &corev1.PodList{}, configMapVolumeIndex)
+
+	t.Run("referenced object with trace", func(t *testing.T) {
+		configMap := &corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "test-configmap",
+				Namespace: "default",
+				Annotations: map[string]string{
+					constants.TraceIDAnnotation: "1234",
+					constants.SpanIDAnnotation:  "5678",
+				},
+			},
+		}
+
+		q := newQueue()
+		h.Create(context.Background(), event.CreateEvent{Object: configMap}, q)
+
+		assert.Equal(t, 1, q.Len())
+		req, _ := q.Get()
+		assert.Equal(t, "1234;5678;ConfigMap;test-configmap;referencing-pod", req.Name)
+		assert.Equal(t, "default", req.Namespace)
+	})
+
+	t.Run("referenced object without trace", func(t *testing.T) {
+		configMap := &corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "test-configmap",
+				Namespace: "default",
+			},
+		}
+
+		q := newQueue()
+		h.Create(context.Background(), event.CreateEvent{Object: configMap}, q)
+
+		assert.Equal(t, 1, q.Len())
+		req, _ := q.Get()
+		assert.Equal(t, "referencing-pod", req.Name)
+	})
+
+	t.Run("failed list is logged", func(t *testing.T) {
+		var logged []string
+		logger := funcr.New(func(prefix, args string) {
+			logged = append(logged, args)
+		}, funcr.Options{})
+
+		// the field index is missing
+		h := handler.EnqueueRequestForReferencedObject(clientgoscheme.Scheme, fake.NewClientBuilder().Build(), &corev1.PodList{}, configMapVolumeIndex, handler.WithReferenceLogger(logger))
+		q := newQueue()
+		h.Create(context.Background(), event.CreateEvent{Object: &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: "test-configmap", Namespace: "default"}}}, q)
+
+		assert.Equal(t, 0, q.Len())
+		assert.Len(t, logged, 1)
+		assert.Contains(t, logged[0], "Could not list referencing objects")
+	})
+}
@@ -0,0 +1,258 @@
+package observers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	kubetracer "github.com/kubetracer/kubetracer-go/pkg/client"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+	autoscalingv2 "k8s.io/api/autoscaling/v2"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+// Attributes of the autoscaling spans
+const (
+	ScaleSourceKey     = attribute.Key("kubetracer.scale.source")
+	ScaleAutoscalerKey = attribute.Key("kubetracer.scale.autoscaler")
+	ScaleFromKey       = attribute.Key("kubetracer.scale.from")
+	ScaleToKey         = attribute.Key("kubetracer.scale.to")
+)
+
+var _ reconcile.Reconciler = &AutoscalingReconciler{}
+
+// AutoscalingReconciler watches HorizontalPodAutoscalers and, when one scales a traced workload, emits a
+// span under the trace of the workload.  The spans carry kubetracer.scale.source=HorizontalPodAutoscaler,
+// so that autoscaler-induced churn is distinguishable from operator-induced changes inside a trace.
+//
+// The first desired replicas seen for an autoscaler are only recorded, as they may predate the trace.  Use a
+// ScaleReconciler to also follow the scales not done by autoscalers, e.g. kubectl scale.
+type AutoscalingReconciler struct {
+	// Reader reads the autoscalers and the metadata of their targets
+	Reader client.Reader
+
+	// Tracer creates the scale spans
+	Tracer trace.Tracer
+
+	mu       sync.Mutex
+	replicas map[types.NamespacedName]int32
+}
+
+// NewAutoscalingReconciler returns an AutoscalingReconciler
+func NewAutoscalingReconciler(reader client.Reader, tracer trace.Tracer) *AutoscalingReconciler {
+	return &AutoscalingReconciler{
+		Reader: reader,
+		Tracer: tracer,
+	}
+}
+
+// SetupWithManager registers the AutoscalingReconciler with the manager
+func (r *AutoscalingReconciler) SetupWithManager(mgr manager.Manager) error {
+	return builder.ControllerManagedBy(mgr).
+		Named("kubetracer-autoscaling").
+		For(&autoscalingv2.HorizontalPodAutoscaler{}).
+		Complete(r)
+}
+
+// Reconcile emits a span if the desired replicas of the autoscaler changed since the last event
+func (r *AutoscalingReconciler) Reconcile(ctx context.Context, req reconcile.Request) (reconcile.Result, error) {
+	hpa := &autoscalingv2.HorizontalPodAutoscaler{}
+	if err := r.Reader.Get(ctx, req.NamespacedName, hpa); err != nil {
+		if apierrors.IsNotFound(err) {
+			r.forget(req.NamespacedName)
+			return reconcile.Result{}, nil
+		}
+		return reconcile.Result{}, err
+	}
+
+	from, changed := r.recordReplicas(req.NamespacedName, hpa.Status.DesiredReplicas)
+	if !changed {
+		return reconcile.Result{}, nil
+	}
+
+	targetRef := hpa.Spec.ScaleTargetRef
+	groupVersion, err := schema.ParseGroupVersion(targetRef.APIVersion)
+	if err != nil {
+		return reconcile.Result{}, err
+	}
+	target := &metav1.PartialObjectMetadata{}
+	target.SetGroupVersionKind(groupVersion.WithKind(targetRef.Kind))
+	if err := r.Reader.Get(ctx, client.ObjectKey{Namespace: hpa.Namespace, Name: targetRef.Name}, target); err != nil {
+		return reconcile.Result{}, kubetracer.IgnoreNotFound(err)
+	}
+
+	spanContext, err := kubetracer.TraceContextFromObject(target, nil)
+	if err != nil {
+		// the target is not traced
+		return reconcile.Result{}, nil
+	}
+
+	scaleTime := time.Now()
+	if hpa.Status.LastScaleTime != nil {
+		scaleTime = hpa.Status.LastScaleTime.Time
+	}
+	ctx = trace.ContextWithRemoteSpanContext(ctx, spanContext)
+	_, span := r.Tracer.Start(ctx,
+		fmt.Sprintf("Autoscale %s %s: %d -> %d replicas", targetRef.Kind, targetRef.Name, from, hpa.Status.DesiredReplicas),
+		trace.WithTimestamp(scaleTime),
+		trace.WithAttributes(
+			ScaleSourceKey.String("HorizontalPodAutoscaler"),
+			ScaleAutoscalerKey.String(hpa.Name),
+			ScaleFromKey.Int64(int64(from)),
+			ScaleToKey.Int64(int64(hpa.Status.DesiredReplicas)),
+		),
+	)
+	span.End(trace.WithTimestamp(scaleTime))
+	return reconcile.Result{}, nil
+}
+
+// recordReplicas records the desired replicas of the autoscaler and returns the previous ones if they changed
+func (r *AutoscalingReconciler) recordReplicas(key types.NamespacedName, replicas int32) (int32, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.replicas == nil {
+		r.replicas = map[types.NamespacedName]int32{}
+	}
+	previous, ok := r.replicas[key]
+	r.replicas[key] = replicas
+	return previous, ok && previous != replicas
+}
+
+func (r *AutoscalingReconciler) forget(key types.NamespacedName) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(r.replicas, key)
+}
+
+var _ reconcile.Reconciler = &ScaleReconciler{}
+
+// ScaleReconciler watches the replicas of Deployments or StatefulSets and, when those of a traced workload
+// change, emits a span under the trace of the workload.  It covers the scales done through the spec or the
+// scale subresource by anyone, e.g. kubectl scale, which the AutoscalingReconciler does not see.  The spans
+// carry the field manager that wrote the replicas in kubetracer.scale.source, e.g. kubectl or the manager of
+// the operator, so scales by hand, by autoscalers and by the operator are told apart.
+//
+// The first replicas seen for a workload are only recorded, as they may predate the trace.
+type ScaleReconciler struct {
+	// Reader reads the workloads
+	Reader client.Reader
+
+	// Tracer creates the scale spans
+	Tracer trace.Tracer
+
+	// Object is the workload type to follow, *appsv1.Deployment or *appsv1.StatefulSet
+	Object client.Object
+
+	mu       sync.Mutex
+	replicas map[types.NamespacedName]int32
+}
+
+// NewScaleReconciler returns a ScaleReconciler following the workloads of the type of obj
+func NewScaleReconciler(reader client.Reader, tracer trace.Tracer, obj client.Object) *ScaleReconciler {
+	return &ScaleReconciler{
+		Reader: reader,
+		Tracer: tracer,
+		Object: obj,
+	}
+}
+
+// SetupWithManager registers the ScaleReconciler with the manager
+func (r *ScaleReconciler) SetupWithManager(mgr manager.Manager) error {
+	kind, _ := getRolloutStatus(r.Object)
+	return builder.ControllerManagedBy(mgr).
+		Named("kubetracer-scale-" + strings.ToLower(kind.kind)).
+		For(r.Object).
+		Complete(r)
+}
+
+// Reconcile emits a span if the replicas of the workload changed since the last event
+func (r *ScaleReconciler) Reconcile(ctx context.Context, req reconcile.Request) (reconcile.Result, error) {
+	obj := r.Object.DeepCopyObject().(client.Object)
+	if err := r.Reader.Get(ctx, req.NamespacedName, obj); err != nil {
+		if apierrors.IsNotFound(err) {
+			r.forget(req.NamespacedName)
+			return reconcile.Result{}, nil
+		}
+		return reconcile.Result{}, err
+	}
+
+	status, ok := getRolloutStatus(obj)
+	if !ok {
+		return reconcile.Result{}, fmt.Errorf("unsupported workload type %T", obj)
+	}
+	from, changed := r.recordReplicas(req.NamespacedName, status.replicas)
+	if !changed {
+		return reconcile.Result{}, nil
+	}
+
+	spanContext, err := kubetracer.TraceContextFromObject(obj, nil)
+	if err != nil {
+		// the workload is not traced
+		return reconcile.Result{}, nil
+	}
+
+	source, scaleTime := replicasManager(obj)
+	ctx = trace.ContextWithRemoteSpanContext(ctx, spanContext)
+	_, span := r.Tracer.Start(ctx,
+		fmt.Sprintf("Scale %s %s: %d -> %d replicas", status.kind, req.Name, from, status.replicas),
+		trace.WithTimestamp(scaleTime),
+		trace.WithAttributes(
+			ScaleSourceKey.String(source),
+			ScaleFromKey.Int64(int64(from)),
+			ScaleToKey.Int64(int64(status.replicas)),
+		),
+	)
+	span.End(trace.WithTimestamp(scaleTime))
+	return reconcile.Result{}, nil
+}
+
+// replicasManager returns the field manager which last wrote the replicas of the spec of obj and when, or
+// unknown and now if the managed fields do not tell
+func replicasManager(obj client.Object) (string, time.Time) {
+	source, scaleTime := "unknown", time.Now()
+	var latest *metav1.Time
+	for _, entry := range obj.GetManagedFields() {
+		if entry.FieldsV1 == nil || entry.Time == nil || (latest != nil && entry.Time.Before(latest)) {
+			continue
+		}
+		fields := map[string]map[string]interface{}{}
+		if err := json.Unmarshal(entry.FieldsV1.Raw, &fields); err != nil {
+			continue
+		}
+		if _, ok := fields["f:spec"]["f:replicas"]; ok {
+			source, scaleTime, latest = entry.Manager, entry.Time.Time, entry.Time
+		}
+	}
+	return source, scaleTime
+}
+
+// recordReplicas records the replicas of the workload and returns the previous ones if they changed
+func (r *ScaleReconciler) recordReplicas(key types.NamespacedName, replicas int32) (int32, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.replicas == nil {
+		r.replicas = map[types.NamespacedName]int32{}
+	}
+	previous, ok := r.replicas[key]
+	r.replicas[key] = replicas
+	return previous, ok && previous != replicas
+}
+
+func (r *ScaleReconciler) forget(key types.NamespacedName) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(r.replicas, key)
+}
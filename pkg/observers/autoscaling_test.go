@@ -0,0 +1,143 @@
+package observers_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/kubetracer/kubetracer-go/pkg/constants"
+	"github.com/kubetracer/kubetracer-go/pkg/observers"
+	"github.com/stretchr/testify/assert"
+	appsv1 "k8s.io/api/apps/v1"
+	autoscalingv2 "k8s.io/api/autoscaling/v2"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+func TestAutoscalingReconciler(t *testing.T) {
+	deployment := &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "test-deployment",
+			Namespace: "default",
+			Annotations: map[string]string{
+				constants.TraceIDAnnotation: "f620f5cad0af940c294f980c5366a6a1",
+				constants.SpanIDAnnotation:  "45f359cdc1c8ab06",
+			},
+		},
+	}
+	hpa := &autoscalingv2.HorizontalPodAutoscaler{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "test-hpa",
+			Namespace: "default",
+		},
+		Spec: autoscalingv2.HorizontalPodAutoscalerSpec{
+			ScaleTargetRef: autoscalingv2.CrossVersionObjectReference{
+				APIVersion: "apps/v1",
+				Kind:       "Deployment",
+				Name:       "test-deployment",
+			},
+		},
+		Status: autoscalingv2.HorizontalPodAutoscalerStatus{
+			LastScaleTime:   &metav1.Time{Time: time.Now().Add(-time.Hour)},
+			CurrentReplicas: 1,
+			DesiredReplicas: 2,
+		},
+	}
+	k8sClient := fake.NewClientBuilder().WithObjects(deployment, hpa).WithStatusSubresource(hpa).Build()
+
+	recorder, tp := newRecorder()
+	r := observers.NewAutoscalingReconciler(k8sClient, tp.Tracer("autoscaling"))
+	req := reconcile.Request{NamespacedName: types.NamespacedName{Name: "test-hpa", Namespace: "default"}}
+
+	// The first desired replicas may predate the trace
+	_, err := r.Reconcile(context.Background(), req)
+	assert.NoError(t, err)
+	assert.Empty(t, recorder.Ended())
+
+	// The current replicas caught up already, the scale is from the previously desired ones
+	hpa.Status.LastScaleTime = &metav1.Time{Time: time.Now()}
+	hpa.Status.CurrentReplicas = 4
+	hpa.Status.DesiredReplicas = 4
+	assert.NoError(t, k8sClient.Status().Update(context.Background(), hpa))
+
+	_, err = r.Reconcile(context.Background(), req)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"Autoscale Deployment test-deployment: 2 -> 4 replicas"}, spanNames(recorder))
+
+	span := recorder.Ended()[0]
+	assert.Equal(t, "f620f5cad0af940c294f980c5366a6a1", span.SpanContext().TraceID().String())
+	assert.Contains(t, span.Attributes(), observers.ScaleSourceKey.String("HorizontalPodAutoscaler"))
+	assert.Contains(t, span.Attributes(), observers.ScaleAutoscalerKey.String("test-hpa"))
+	assert.Contains(t, span.Attributes(), observers.ScaleFromKey.Int64(2))
+	assert.Contains(t, span.Attributes(), observers.ScaleToKey.Int64(4))
+
+	// The desired replicas did not change
+	hpa.Status.CurrentReplicas = 3
+	assert.NoError(t, k8sClient.Status().Update(context.Background(), hpa))
+	_, err = r.Reconcile(context.Background(), req)
+	assert.NoError(t, err)
+	assert.Len(t, recorder.Ended(), 1)
+}
+
+func TestScaleReconciler(t *testing.T) {
+	replicas := int32(2)
+	deployment := &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "test-deployment",
+			Namespace: "default",
+			Annotations: map[string]string{
+				constants.TraceIDAnnotation: "f620f5cad0af940c294f980c5366a6a1",
+				constants.SpanIDAnnotation:  "45f359cdc1c8ab06",
+			},
+		},
+		Spec: appsv1.DeploymentSpec{Replicas: &replicas},
+	}
+	k8sClient := fake.NewClientBuilder().WithObjects(deployment).Build()
+
+	recorder, tp := newRecorder()
+	r := observers.NewScaleReconciler(k8sClient, tp.Tracer("scale"), &appsv1.Deployment{})
+	req := reconcile.Request{NamespacedName: types.NamespacedName{Name: "test-deployment", Namespace: "default"}}
+
+	// The first replicas may predate the trace
+	_, err := r.Reconcile(context.Background(), req)
+	assert.NoError(t, err)
+	assert.Empty(t, recorder.Ended())
+
+	// kubectl scale
+	scaleTime := metav1.NewTime(time.Now().Truncate(time.Second))
+	assert.NoError(t, k8sClient.Get(context.Background(), req.NamespacedName, deployment))
+	replicas = 5
+	deployment.Spec.Replicas = &replicas
+	deployment.ManagedFields = []metav1.ManagedFieldsEntry{
+		{
+			Manager:  "operator",
+			Time:     &metav1.Time{Time: scaleTime.Add(-time.Hour)},
+			FieldsV1: &metav1.FieldsV1{Raw: []byte(`{"f:spec":{"f:replicas":{},"f:template":{}}}`)},
+		},
+		{
+			Manager:     "kubectl",
+			Subresource: "scale",
+			Time:        &scaleTime,
+			FieldsV1:    &metav1.FieldsV1{Raw: []byte(`{"f:spec":{"f:replicas":{}}}`)},
+		},
+	}
+	assert.NoError(t, k8sClient.Update(context.Background(), deployment))
+
+	_, err = r.Reconcile(context.Background(), req)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"Scale Deployment test-deployment: 2 -> 5 replicas"}, spanNames(recorder))
+
+	span := recorder.Ended()[0]
+	assert.Equal(t, "f620f5cad0af940c294f980c5366a6a1", span.SpanContext().TraceID().String())
+	assert.Contains(t, span.Attributes(), observers.ScaleSourceKey.String("kubectl"))
+	assert.Contains(t, span.Attributes(), observers.ScaleFromKey.Int64(2))
+	assert.Contains(t, span.Attributes(), observers.ScaleToKey.Int64(5))
+	assert.True(t, scaleTime.Time.Equal(span.StartTime()))
+
+	// Nothing new happened
+	_, err = r.Reconcile(context.Background(), req)
+	assert.NoError(t, err)
+	assert.Len(t, recorder.Ended(), 1)
+}
@@ -0,0 +1,245 @@
+package observers
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	kubetracer "github.com/kubetracer/kubetracer-go/pkg/client"
+	"go.opentelemetry.io/otel/trace"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/util/workqueue"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/apiutil"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+const (
+	// DefaultDeletionRetention is how long a deleted owner is remembered to attribute the deletion of its children
+	DefaultDeletionRetention = 5 * time.Minute
+
+	// DefaultOwnerTTL is how long an owner which is not deleted is remembered since it was last seen
+	DefaultOwnerTTL = time.Hour
+
+	// DefaultMaxOwners is the number of owners remembered at most
+	DefaultMaxOwners = 10000
+)
+
+// DeletionObserver emits a span when the garbage collector deletes the children of a traced owner, parented to
+// the trace of the owner.  Cascading deletions are otherwise invisible as no TracingClient performs them.
+//
+// It remembers the trace of the owners it sees through OwnerHandler.  When a child is deleted through
+// ChildHandler after its owner was deleted, or while the owner is being deleted, a "GC Delete" span is emitted.
+// An owner is forgotten when its trace ends, when it was not seen for OwnerTTL, or when it is the least recently
+// seen one beyond MaxOwners.
+type DeletionObserver struct {
+	// Tracer creates the deletion spans
+	Tracer trace.Tracer
+
+	// Scheme is used to get the Kind of the children
+	Scheme *runtime.Scheme
+
+	// Retention is how long a deleted owner is remembered, DefaultDeletionRetention if zero
+	Retention time.Duration
+
+	// OwnerTTL is how long an owner which is not deleted is remembered since it was last seen, DefaultOwnerTTL
+	// if zero
+	OwnerTTL time.Duration
+
+	// MaxOwners is the number of owners remembered at most, DefaultMaxOwners if zero
+	MaxOwners int
+
+	mu     sync.Mutex
+	owners map[types.UID]*ownerTrace
+}
+
+// ownerTrace is the trace of an owner the DeletionObserver saw
+type ownerTrace struct {
+	name        string
+	spanContext trace.SpanContext
+	deleting    bool
+	deletedAt   time.Time
+	seenAt      time.Time
+}
+
+// NewDeletionObserver returns a DeletionObserver
+// optional scheme.  If not, it will use client-go scheme
+func NewDeletionObserver(tracer trace.Tracer, scheme ...*runtime.Scheme) *DeletionObserver {
+	observerScheme := clientgoscheme.Scheme
+	if len(scheme) > 0 {
+		observerScheme = scheme[0]
+	}
+
+	return &DeletionObserver{
+		Tracer: tracer,
+		Scheme: observerScheme,
+	}
+}
+
+// SetupWithManager watches the owners of type owner and their children of type child with the manager
+func (o *DeletionObserver) SetupWithManager(mgr manager.Manager, owner, child client.Object) error {
+	ownerGVK, err := apiutil.GVKForObject(owner, o.Scheme)
+	if err != nil {
+		return fmt.Errorf("problem getting the scheme: %w", err)
+	}
+	childGVK, err := apiutil.GVKForObject(child, o.Scheme)
+	if err != nil {
+		return fmt.Errorf("problem getting the scheme: %w", err)
+	}
+
+	return builder.ControllerManagedBy(mgr).
+		Named(strings.ToLower(fmt.Sprintf("kubetracer-deletion-%s-%s", ownerGVK.Kind, childGVK.Kind))).
+		Watches(owner, o.OwnerHandler()).
+		Watches(child, o.ChildHandler()).
+		Complete(reconcile.Func(func(context.Context, reconcile.Request) (reconcile.Result, error) {
+			// the handlers do the work and never enqueue
+			return reconcile.Result{}, nil
+		}))
+}
+
+// OwnerHandler returns the handler recording the trace of the owners.  It never enqueues Requests.
+func (o *DeletionObserver) OwnerHandler() handler.EventHandler {
+	return handler.Funcs{
+		CreateFunc: func(_ context.Context, e event.CreateEvent, _ workqueue.TypedRateLimitingInterface[reconcile.Request]) {
+			o.recordOwner(e.Object, false)
+		},
+		UpdateFunc: func(_ context.Context, e event.UpdateEvent, _ workqueue.TypedRateLimitingInterface[reconcile.Request]) {
+			o.recordOwner(e.ObjectNew, false)
+		},
+		DeleteFunc: func(_ context.Context, e event.DeleteEvent, _ workqueue.TypedRateLimitingInterface[reconcile.Request]) {
+			o.recordOwner(e.Object, true)
+		},
+		GenericFunc: func(_ context.Context, e event.GenericEvent, _ workqueue.TypedRateLimitingInterface[reconcile.Request]) {
+			o.recordOwner(e.Object, false)
+		},
+	}
+}
+
+// ChildHandler returns the handler emitting the deletion spans of the children.  It never enqueues Requests.
+func (o *DeletionObserver) ChildHandler() handler.EventHandler {
+	return handler.Funcs{
+		DeleteFunc: func(ctx context.Context, e event.DeleteEvent, _ workqueue.TypedRateLimitingInterface[reconcile.Request]) {
+			o.childDeleted(ctx, e.Object)
+		},
+	}
+}
+
+// recordOwner remembers the trace of obj, and whether it is deleted or being deleted
+func (o *DeletionObserver) recordOwner(obj client.Object, deleted bool) {
+	if obj == nil {
+		return
+	}
+
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	defer o.expire()
+
+	if o.owners == nil {
+		o.owners = map[types.UID]*ownerTrace{}
+	}
+	now := time.Now()
+	spanContext, err := kubetracer.TraceContextFromObject(obj, nil)
+	if err != nil {
+		// keep the last trace of an owner being deleted, finalizers may remove its annotations
+		if owner, ok := o.owners[obj.GetUID()]; ok && (deleted || obj.GetDeletionTimestamp() != nil) {
+			owner.deleting = true
+			owner.seenAt = now
+			if deleted {
+				owner.deletedAt = now
+			}
+			return
+		}
+		// the trace of the owner ended
+		delete(o.owners, obj.GetUID())
+		return
+	}
+
+	owner := &ownerTrace{
+		name:        obj.GetName(),
+		spanContext: spanContext,
+		deleting:    deleted || obj.GetDeletionTimestamp() != nil,
+		seenAt:      now,
+	}
+	if deleted {
+		owner.deletedAt = now
+	}
+	o.owners[obj.GetUID()] = owner
+}
+
+// childDeleted emits a deletion span if obj is deleted along with a traced owner
+func (o *DeletionObserver) childDeleted(ctx context.Context, obj client.Object) {
+	if obj == nil {
+		return
+	}
+
+	o.mu.Lock()
+	var owner ownerTrace
+	ownerKind := ""
+	for _, ref := range obj.GetOwnerReferences() {
+		if candidate, ok := o.owners[ref.UID]; ok && candidate.deleting {
+			owner = *candidate
+			ownerKind = ref.Kind
+			break
+		}
+	}
+	o.mu.Unlock()
+	if ownerKind == "" {
+		return
+	}
+
+	kind := ""
+	if gvk, err := apiutil.GVKForObject(obj, o.Scheme); err == nil {
+		kind = gvk.Kind
+	}
+
+	ctx = trace.ContextWithRemoteSpanContext(ctx, owner.spanContext)
+	_, span := o.Tracer.Start(ctx, fmt.Sprintf("GC Delete %s %s Owned By %s/%s", kind, obj.GetName(), ownerKind, owner.name))
+	span.End()
+}
+
+// expire forgets the owners deleted longer than the retention ago, those not seen for the owner TTL, and the
+// least recently seen ones beyond the maximum number of owners.  o.mu must be held.
+func (o *DeletionObserver) expire() {
+	retention := o.Retention
+	if retention == 0 {
+		retention = DefaultDeletionRetention
+	}
+	ttl := o.OwnerTTL
+	if ttl == 0 {
+		ttl = DefaultOwnerTTL
+	}
+	for uid, owner := range o.owners {
+		if !owner.deletedAt.IsZero() && time.Since(owner.deletedAt) > retention {
+			delete(o.owners, uid)
+		} else if owner.deletedAt.IsZero() && time.Since(owner.seenAt) > ttl {
+			delete(o.owners, uid)
+		}
+	}
+
+	maxOwners := o.MaxOwners
+	if maxOwners == 0 {
+		maxOwners = DefaultMaxOwners
+	}
+	if len(o.owners) <= maxOwners {
+		return
+	}
+	uids := make([]types.UID, 0, len(o.owners))
+	for uid := range o.owners {
+		uids = append(uids, uid)
+	}
+	sort.Slice(uids, func(i, j int) bool {
+		return o.owners[uids[i]].seenAt.Before(o.owners[uids[j]].seenAt)
+	})
+	for _, uid := range uids[:len(uids)-maxOwners] {
+		delete(o.owners, uid)
+	}
+}
@@ -0,0 +1,94 @@
+package observers_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/kubetracer/kubetracer-go/pkg/constants"
+	"github.com/kubetracer/kubetracer-go/pkg/observers"
+	"github.com/stretchr/testify/assert"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+)
+
+func TestDeletionObserver(t *testing.T) {
+	replicaSet := &appsv1.ReplicaSet{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "test-replicaset",
+			Namespace: "default",
+			UID:       "replicaset-uid",
+			Annotations: map[string]string{
+				constants.TraceIDAnnotation: "f620f5cad0af940c294f980c5366a6a1",
+				constants.SpanIDAnnotation:  "45f359cdc1c8ab06",
+			},
+		},
+	}
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "test-pod",
+			Namespace: "default",
+			OwnerReferences: []metav1.OwnerReference{{
+				APIVersion: "apps/v1",
+				Kind:       "ReplicaSet",
+				Name:       "test-replicaset",
+				UID:        "replicaset-uid",
+			}},
+		},
+	}
+
+	recorder, tp := newRecorder()
+	o := observers.NewDeletionObserver(tp.Tracer("deletion"))
+	ctx := context.Background()
+
+	o.OwnerHandler().Create(ctx, event.CreateEvent{Object: replicaSet}, nil)
+
+	// The owner still exists, e.g. the pod was evicted
+	o.ChildHandler().Delete(ctx, event.DeleteEvent{Object: pod}, nil)
+	assert.Empty(t, recorder.Ended())
+
+	// The owner is deleted, the garbage collector deletes the pod
+	o.OwnerHandler().Delete(ctx, event.DeleteEvent{Object: replicaSet}, nil)
+	o.ChildHandler().Delete(ctx, event.DeleteEvent{Object: pod}, nil)
+	assert.Equal(t, []string{"GC Delete Pod test-pod Owned By ReplicaSet/test-replicaset"}, spanNames(recorder))
+
+	span := recorder.Ended()[0]
+	assert.Equal(t, "f620f5cad0af940c294f980c5366a6a1", span.SpanContext().TraceID().String())
+	assert.Equal(t, "45f359cdc1c8ab06", span.Parent().SpanID().String())
+
+	t.Run("trace ended", func(t *testing.T) {
+		recorder, tp := newRecorder()
+		o := observers.NewDeletionObserver(tp.Tracer("deletion"))
+		o.OwnerHandler().Create(ctx, event.CreateEvent{Object: replicaSet}, nil)
+
+		// the owner is deleted after its trace ended
+		untraced := replicaSet.DeepCopy()
+		untraced.Annotations = nil
+		o.OwnerHandler().Update(ctx, event.UpdateEvent{ObjectOld: replicaSet, ObjectNew: untraced}, nil)
+		o.OwnerHandler().Delete(ctx, event.DeleteEvent{Object: untraced}, nil)
+		o.ChildHandler().Delete(ctx, event.DeleteEvent{Object: pod}, nil)
+		assert.Empty(t, recorder.Ended())
+	})
+
+	t.Run("too many owners", func(t *testing.T) {
+		recorder, tp := newRecorder()
+		o := observers.NewDeletionObserver(tp.Tracer("deletion"))
+		o.MaxOwners = 1
+		o.OwnerHandler().Create(ctx, event.CreateEvent{Object: replicaSet}, nil)
+
+		other := replicaSet.DeepCopy()
+		other.Name = "other-replicaset"
+		other.UID = "other-uid"
+		o.OwnerHandler().Create(ctx, event.CreateEvent{Object: other}, nil)
+
+		// the first owner was forgotten before a finalizer removed its trace
+		deleting := replicaSet.DeepCopy()
+		deleting.Annotations = nil
+		deleting.DeletionTimestamp = &metav1.Time{Time: time.Now()}
+		o.OwnerHandler().Update(ctx, event.UpdateEvent{ObjectOld: replicaSet, ObjectNew: deleting}, nil)
+		o.ChildHandler().Delete(ctx, event.DeleteEvent{Object: pod}, nil)
+		assert.Empty(t, recorder.Ended())
+	})
+}
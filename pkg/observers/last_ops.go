@@ -0,0 +1,314 @@
+package observers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	kubetracer "github.com/kubetracer/kubetracer-go/pkg/client"
+	"github.com/kubetracer/kubetracer-go/pkg/constants"
+	"github.com/kubetracer/kubetracer-go/pkg/query"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/trace"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/apiutil"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+// Attributes of the spans exported by the LastOpsReplayer
+const (
+	// ReplayedKey marks the spans exported by the LastOpsReplayer
+	ReplayedKey = attribute.Key("kubetracer.replayed")
+	// ReplayedControllerKey is the controller which recorded the operation
+	ReplayedControllerKey = attribute.Key("kubetracer.replayed.controller")
+)
+
+// DefaultReplayTimeout bounds the replay of the operations of an object, queries to the backend included
+const DefaultReplayTimeout = 10 * time.Second
+
+// LastOpsReplayer exports the operations recorded in the kubetracer.io/last-ops annotation of objects, see
+// WithLastOpsAnnotation, as retroactive spans when the tracing backend does not have them, e.g. the operations
+// performed while the exporter was down.  Chains then have no holes after incidents.
+//
+// The spans keep the trace, span and parent IDs of the operations, so they take the place of the lost spans in
+// their traces.  Their start and end are the time of the operation, to the second.  Operations recorded without
+// a trace ID are skipped.  A replay which fails, or takes longer than Timeout, is retried with backoff.
+type LastOpsReplayer struct {
+	// Exporter exports the retroactive spans
+	Exporter sdktrace.SpanExporter
+
+	// Backend is queried for the spans of the traces, only the spans it does not have are exported
+	Backend query.Client
+
+	// Scheme is used to get the Kind of the objects
+	Scheme *runtime.Scheme
+
+	// Resource describes the entity the spans are exported for, resource.Default() if nil
+	Resource *resource.Resource
+
+	// Annotation is the annotation the operations are read from, kubetracer.io/last-ops if empty, e.g.
+	// example.com/last-ops for TracingClients configured with WithAnnotationPrefix("example.com/")
+	Annotation string
+
+	// Reader reads the objects, SetupWithManager sets it to the client of the manager if nil
+	Reader client.Reader
+
+	// Timeout bounds the replay of the operations of an object, DefaultReplayTimeout if zero
+	Timeout time.Duration
+
+	mu sync.Mutex
+	// seen are the last-ops annotations already replayed, per object
+	seen map[types.NamespacedName]string
+}
+
+// NewLastOpsReplayer returns a LastOpsReplayer
+// optional scheme.  If not, it will use client-go scheme
+func NewLastOpsReplayer(exporter sdktrace.SpanExporter, backend query.Client, scheme ...*runtime.Scheme) *LastOpsReplayer {
+	replayerScheme := clientgoscheme.Scheme
+	if len(scheme) > 0 {
+		replayerScheme = scheme[0]
+	}
+
+	return &LastOpsReplayer{
+		Exporter: exporter,
+		Backend:  backend,
+		Scheme:   replayerScheme,
+	}
+}
+
+// SetupWithManager watches the objects of the type of obj with the manager
+func (r *LastOpsReplayer) SetupWithManager(mgr manager.Manager, obj client.Object) error {
+	gvk, err := apiutil.GVKForObject(obj, r.Scheme)
+	if err != nil {
+		return fmt.Errorf("problem getting the scheme: %w", err)
+	}
+	if r.Reader == nil {
+		r.Reader = mgr.GetClient()
+	}
+
+	return builder.ControllerManagedBy(mgr).
+		Named("kubetracer-last-ops-" + strings.ToLower(gvk.Kind)).
+		For(obj).
+		Complete(reconcile.Func(func(ctx context.Context, req reconcile.Request) (reconcile.Result, error) {
+			return r.reconcile(ctx, req, obj)
+		}))
+}
+
+// reconcile replays the operations of the object of req, of the type of obj, within the timeout.  The error of
+// the replay is returned so the Request is retried.
+func (r *LastOpsReplayer) reconcile(ctx context.Context, req reconcile.Request, obj client.Object) (reconcile.Result, error) {
+	obj = obj.DeepCopyObject().(client.Object)
+	if err := r.Reader.Get(ctx, req.NamespacedName, obj); err != nil {
+		if apierrors.IsNotFound(err) {
+			r.forget(req.NamespacedName)
+			return reconcile.Result{}, nil
+		}
+		return reconcile.Result{}, err
+	}
+
+	timeout := r.Timeout
+	if timeout == 0 {
+		timeout = DefaultReplayTimeout
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	return reconcile.Result{}, r.Replay(ctx, obj)
+}
+
+// Replay exports the operations recorded on obj which the backend does not have.  Each last-ops annotation is
+// replayed once, unless replaying it failed.
+func (r *LastOpsReplayer) Replay(ctx context.Context, obj client.Object) error {
+	if obj == nil {
+		return nil
+	}
+	key := client.ObjectKeyFromObject(obj)
+	annotation := r.Annotation
+	if annotation == "" {
+		annotation = constants.LastOpsAnnotation
+	}
+	value := obj.GetAnnotations()[annotation]
+	r.mu.Lock()
+	replayed := value == "" || r.seen[key] == value
+	r.mu.Unlock()
+	if replayed {
+		return nil
+	}
+
+	ops, err := kubetracer.ParseLastOps(value)
+	if err != nil {
+		return err
+	}
+
+	kind := ""
+	if gvk, err := apiutil.GVKForObject(obj, r.Scheme); err == nil {
+		kind = gvk.Kind
+	}
+
+	// the spans are recorded by a tracer of their own, generating the IDs of the operations
+	ids := &replayIDGenerator{}
+	recorder := &replayedSpans{}
+	providerOptions := []sdktrace.TracerProviderOption{
+		sdktrace.WithSampler(sdktrace.AlwaysSample()),
+		sdktrace.WithIDGenerator(ids),
+		sdktrace.WithSpanProcessor(recorder),
+	}
+	if r.Resource != nil {
+		providerOptions = append(providerOptions, sdktrace.WithResource(r.Resource))
+	}
+	tp := sdktrace.NewTracerProvider(providerOptions...)
+	defer func() {
+		// the recorder has nothing to flush
+		_ = tp.Shutdown(context.Background())
+	}()
+	tracer := tp.Tracer(kubetracer.TracerName)
+
+	// the spans the backend has, per trace
+	known := map[string]map[string]bool{}
+	for _, op := range ops {
+		if op.TraceID == "" || op.SpanID == "" {
+			continue
+		}
+		if _, ok := known[op.TraceID]; !ok {
+			if known[op.TraceID], err = r.backendSpans(ctx, op.TraceID); err != nil {
+				return err
+			}
+		}
+		if known[op.TraceID][op.SpanID] {
+			continue
+		}
+		replaySpan(tracer, ids, op, kind, obj)
+	}
+
+	if len(recorder.spans) > 0 {
+		if err := r.Exporter.ExportSpans(ctx, recorder.spans); err != nil {
+			return fmt.Errorf("problem exporting the replayed spans: %w", err)
+		}
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.seen == nil {
+		r.seen = map[types.NamespacedName]string{}
+	}
+	r.seen[key] = value
+	return nil
+}
+
+// backendSpans returns the IDs of the spans of the trace the backend has
+func (r *LastOpsReplayer) backendSpans(ctx context.Context, traceID string) (map[string]bool, error) {
+	roots, err := r.Backend.Trace(ctx, traceID)
+	if errors.Is(err, query.ErrTraceNotFound) {
+		return map[string]bool{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	spanIDs := map[string]bool{}
+	var walk func(spans []*query.Span)
+	walk = func(spans []*query.Span) {
+		for _, span := range spans {
+			spanIDs[span.SpanID] = true
+			walk(span.Children)
+		}
+	}
+	walk(roots)
+	return spanIDs, nil
+}
+
+// replaySpan records the retroactive span of op on obj of kind with tracer, ids generating its IDs.  Invalid
+// operations are skipped.
+func replaySpan(tracer trace.Tracer, ids *replayIDGenerator, op kubetracer.LastOp, kind string, obj client.Object) {
+	traceID, err := trace.TraceIDFromHex(op.TraceID)
+	if err != nil {
+		return
+	}
+	spanID, err := trace.SpanIDFromHex(op.SpanID)
+	if err != nil {
+		return
+	}
+	timestamp, err := time.Parse(time.RFC3339, op.Time)
+	if err != nil {
+		return
+	}
+
+	ctx := context.Background()
+	if parentSpanID, err := trace.SpanIDFromHex(op.ParentSpanID); err == nil {
+		parent := trace.NewSpanContext(trace.SpanContextConfig{TraceID: traceID, SpanID: parentSpanID, TraceFlags: trace.FlagsSampled, Remote: true})
+		ctx = trace.ContextWithRemoteSpanContext(ctx, parent)
+	}
+
+	verb := op.Verb
+	if verb != "" {
+		verb = strings.ToUpper(verb[:1]) + verb[1:]
+	}
+	ids.traceID, ids.spanID = traceID, spanID
+	_, span := tracer.Start(ctx, fmt.Sprintf("%s %s %s", verb, kind, obj.GetName()),
+		trace.WithSpanKind(trace.SpanKindInternal),
+		trace.WithTimestamp(timestamp),
+		trace.WithAttributes(
+			kubetracer.OperationKey.String(op.Verb),
+			kubetracer.K8sObjectKindKey.String(kind),
+			kubetracer.K8sNamespaceNameKey.String(obj.GetNamespace()),
+			kubetracer.K8sObjectNameKey.String(obj.GetName()),
+			ReplayedKey.Bool(true),
+			ReplayedControllerKey.String(op.Controller),
+		),
+	)
+	span.End(trace.WithTimestamp(timestamp))
+}
+
+// forget drops the annotation replayed for the object of key
+func (r *LastOpsReplayer) forget(key types.NamespacedName) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(r.seen, key)
+}
+
+var _ sdktrace.IDGenerator = &replayIDGenerator{}
+
+// replayIDGenerator generates the recorded IDs of the operation being replayed
+type replayIDGenerator struct {
+	traceID trace.TraceID
+	spanID  trace.SpanID
+}
+
+func (g *replayIDGenerator) NewIDs(context.Context) (trace.TraceID, trace.SpanID) {
+	return g.traceID, g.spanID
+}
+
+func (g *replayIDGenerator) NewSpanID(context.Context, trace.TraceID) trace.SpanID {
+	return g.spanID
+}
+
+var _ sdktrace.SpanProcessor = &replayedSpans{}
+
+// replayedSpans collects the replayed spans to export them at once
+type replayedSpans struct {
+	spans []sdktrace.ReadOnlySpan
+}
+
+func (p *replayedSpans) OnStart(context.Context, sdktrace.ReadWriteSpan) {}
+
+func (p *replayedSpans) OnEnd(s sdktrace.ReadOnlySpan) {
+	p.spans = append(p.spans, s)
+}
+
+func (p *replayedSpans) Shutdown(context.Context) error {
+	return nil
+}
+
+func (p *replayedSpans) ForceFlush(context.Context) error {
+	return nil
+}
@@ -0,0 +1,105 @@
+package observers_test
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	kubetracer "github.com/kubetracer/kubetracer-go/pkg/client"
+	"github.com/kubetracer/kubetracer-go/pkg/constants"
+	"github.com/kubetracer/kubetracer-go/pkg/observers"
+	"github.com/kubetracer/kubetracer-go/pkg/query"
+	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// backend is a query.Client serving the spans of traces from memory
+type backend map[string][]*query.Span
+
+func (b backend) Trace(_ context.Context, traceID string) ([]*query.Span, error) {
+	roots, ok := b[traceID]
+	if !ok {
+		return nil, query.ErrTraceNotFound
+	}
+	return roots, nil
+}
+
+func TestLastOpsReplayer(t *testing.T) {
+	const traceID = "f620f5cad0af940c294f980c5366a6a1"
+	ops := []kubetracer.LastOp{
+		// exported before the exporter went down
+		{Verb: "create", Controller: "pod-controller", Time: "2026-10-16T10:00:00Z", TraceID: traceID, SpanID: "45f359cdc1c8ab06"},
+		// lost while the exporter was down
+		{Verb: "update", Controller: "pod-controller", Time: "2026-10-16T10:01:00Z", TraceID: traceID, SpanID: "b7ad6b7169203331", ParentSpanID: "00f067aa0ba902b7"},
+		// recorded before trace IDs were
+		{Verb: "patch", Controller: "pod-controller", Time: "2026-10-16T10:02:00Z", SpanID: "53995c3f42cd8ad8"},
+	}
+	value, err := json.Marshal(ops)
+	assert.NoError(t, err)
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        "test-pod",
+			Namespace:   "default",
+			UID:         "pod-uid",
+			Annotations: map[string]string{constants.LastOpsAnnotation: string(value)},
+		},
+	}
+
+	exporter := tracetest.NewInMemoryExporter()
+	r := observers.NewLastOpsReplayer(exporter, backend{
+		traceID: {{TraceID: traceID, SpanID: "00f067aa0ba902b7", Children: []*query.Span{{TraceID: traceID, SpanID: "45f359cdc1c8ab06"}}}},
+	})
+	ctx := context.Background()
+
+	assert.NoError(t, r.Replay(ctx, pod))
+	spans := exporter.GetSpans()
+	assert.Len(t, spans, 1)
+	span := spans[0]
+	assert.Equal(t, "Update Pod test-pod", span.Name)
+	assert.Equal(t, traceID, span.SpanContext.TraceID().String())
+	assert.Equal(t, "b7ad6b7169203331", span.SpanContext.SpanID().String())
+	assert.Equal(t, "00f067aa0ba902b7", span.Parent.SpanID().String())
+	assert.Equal(t, "2026-10-16T10:01:00Z", span.StartTime.UTC().Format("2006-01-02T15:04:05Z"))
+	assert.Contains(t, span.Attributes, observers.ReplayedKey.Bool(true))
+	assert.Contains(t, span.Attributes, observers.ReplayedControllerKey.String("pod-controller"))
+
+	// the same annotation is not replayed twice
+	exporter.Reset()
+	assert.NoError(t, r.Replay(ctx, pod))
+	assert.Empty(t, exporter.GetSpans())
+
+	// a trace unknown to the backend is replayed entirely
+	unknown := pod.DeepCopy()
+	unknown.Name = "other-pod"
+	unknown.Annotations[constants.LastOpsAnnotation] = `[{"v":"create","c":"pod-controller","t":"2026-10-16T10:00:00Z","tr":"0af7651916cd43dd8448eb211c80319c","s":"45f359cdc1c8ab06"}]`
+	assert.NoError(t, r.Replay(ctx, unknown))
+	assert.Len(t, exporter.GetSpans(), 1)
+	assert.False(t, exporter.GetSpans()[0].Parent.IsValid())
+	assert.Equal(t, "0af7651916cd43dd8448eb211c80319c", exporter.GetSpans()[0].SpanContext.TraceID().String())
+	assert.True(t, exporter.GetSpans()[0].SpanContext.IsSampled())
+}
+
+func TestLastOpsReplayerAnnotation(t *testing.T) {
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "test-pod",
+			Namespace: "default",
+			Annotations: map[string]string{
+				"example.com/last-ops": `[{"v":"create","c":"pod-controller","t":"2026-10-16T10:00:00Z","tr":"0af7651916cd43dd8448eb211c80319c","s":"45f359cdc1c8ab06"}]`,
+			},
+		},
+	}
+
+	exporter := tracetest.NewInMemoryExporter()
+	r := observers.NewLastOpsReplayer(exporter, backend{})
+	assert.NoError(t, r.Replay(context.Background(), pod))
+	assert.Empty(t, exporter.GetSpans())
+
+	r.Annotation = "example.com/last-ops"
+	assert.NoError(t, r.Replay(context.Background(), pod))
+	spans := exporter.GetSpans()
+	assert.Len(t, spans, 1)
+	assert.Equal(t, "Create Pod test-pod", spans[0].Name)
+}
@@ -0,0 +1,266 @@
+package observers
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	kubetracer "github.com/kubetracer/kubetracer-go/pkg/client"
+	"go.opentelemetry.io/otel/trace"
+	appsv1 "k8s.io/api/apps/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+// Phases of a rollout, in the order they are reached
+const (
+	PhaseRevisionObserved     = "revision observed"
+	PhaseNewReplicaSetCreated = "new ReplicaSet created"
+	PhaseReplicasUpdated      = "replicas updated"
+	PhaseReplicasReady        = "replicas ready"
+	PhaseRolloutComplete      = "rollout complete"
+)
+
+// revisionAnnotation is the revision of a Deployment and of its ReplicaSets, set by the Deployment controller
+const revisionAnnotation = "deployment.kubernetes.io/revision"
+
+var _ reconcile.Reconciler = &RolloutReconciler{}
+
+// RolloutReconciler follows the rollout of traced Deployments or StatefulSets and emits a span under the
+// trace of the workload for each phase reached: the new revision observed by the workload controller,
+// the ReplicaSet of the revision created for Deployments, the replicas updated, ready and finally
+// available.  Each span starts when the rollout was first seen and ends when the phase was reached,
+// giving "operator changed spec -> rollout complete" visibility.
+//
+// The progress of the rollouts is kept in memory, a restart emits the phases already reached again.
+type RolloutReconciler struct {
+	// Reader reads the workloads
+	Reader client.Reader
+
+	// Tracer creates the phase spans
+	Tracer trace.Tracer
+
+	// Object is the workload type to follow, *appsv1.Deployment or *appsv1.StatefulSet
+	Object client.Object
+
+	mu       sync.Mutex
+	rollouts map[types.NamespacedName]*rolloutProgress
+}
+
+// rolloutProgress tracks the phases emitted for the trace of a workload
+type rolloutProgress struct {
+	traceID string
+	start   time.Time
+	emitted map[string]bool
+}
+
+// rolloutStatus is the part of the status of a workload describing a rollout
+type rolloutStatus struct {
+	kind               string
+	generation         int64
+	observedGeneration int64
+	replicas           int32
+	currentReplicas    int32
+	updatedReplicas    int32
+	readyReplicas      int32
+	availableReplicas  int32
+
+	// newReplicaSet reports whether the ReplicaSet of the current revision of a Deployment exists
+	newReplicaSet bool
+}
+
+// NewRolloutReconciler returns a RolloutReconciler following the workloads of the type of obj
+func NewRolloutReconciler(reader client.Reader, tracer trace.Tracer, obj client.Object) *RolloutReconciler {
+	return &RolloutReconciler{
+		Reader: reader,
+		Tracer: tracer,
+		Object: obj,
+	}
+}
+
+// SetupWithManager registers the RolloutReconciler with the manager
+func (r *RolloutReconciler) SetupWithManager(mgr manager.Manager) error {
+	kind, _ := getRolloutStatus(r.Object)
+	b := builder.ControllerManagedBy(mgr).
+		Named("kubetracer-rollout-" + strings.ToLower(kind.kind)).
+		For(r.Object)
+	if _, ok := r.Object.(*appsv1.Deployment); ok {
+		b = b.Owns(&appsv1.ReplicaSet{})
+	}
+	return b.Complete(r)
+}
+
+// Reconcile emits the spans of the phases the rollout of the workload reached since the last event
+func (r *RolloutReconciler) Reconcile(ctx context.Context, req reconcile.Request) (reconcile.Result, error) {
+	obj := r.Object.DeepCopyObject().(client.Object)
+	if err := r.Reader.Get(ctx, req.NamespacedName, obj); err != nil {
+		if apierrors.IsNotFound(err) {
+			r.forget(req.NamespacedName)
+			return reconcile.Result{}, nil
+		}
+		return reconcile.Result{}, err
+	}
+
+	status, ok := getRolloutStatus(obj)
+	if !ok {
+		return reconcile.Result{}, fmt.Errorf("unsupported workload type %T", obj)
+	}
+
+	spanContext, err := kubetracer.TraceContextFromObject(obj, nil)
+	if err != nil {
+		// the workload is not traced, or its trace ended
+		r.forget(req.NamespacedName)
+		return reconcile.Result{}, nil
+	}
+
+	if deployment, ok := obj.(*appsv1.Deployment); ok {
+		if status.newReplicaSet, err = r.hasNewReplicaSet(ctx, deployment); err != nil {
+			return reconcile.Result{}, err
+		}
+	}
+
+	now := time.Now()
+	progress := r.progress(req.NamespacedName, spanContext.TraceID().String(), now)
+
+	ctx = trace.ContextWithRemoteSpanContext(ctx, spanContext)
+	for _, phase := range status.reachedPhases() {
+		if !r.markEmitted(progress, phase) {
+			continue
+		}
+		_, span := r.Tracer.Start(ctx, fmt.Sprintf("Rollout %s %s: %s", status.kind, req.Name, phase), trace.WithTimestamp(progress.start))
+		span.End(trace.WithTimestamp(now))
+	}
+	return reconcile.Result{}, nil
+}
+
+// reachedPhases returns the phases reached by the rollout, in order
+func (s rolloutStatus) reachedPhases() []string {
+	phases := []string{}
+	if s.observedGeneration < s.generation {
+		return phases
+	}
+	phases = append(phases, PhaseRevisionObserved)
+	if s.kind == "Deployment" {
+		if !s.newReplicaSet {
+			return phases
+		}
+		phases = append(phases, PhaseNewReplicaSetCreated)
+	}
+	if s.updatedReplicas < s.replicas {
+		return phases
+	}
+	phases = append(phases, PhaseReplicasUpdated)
+	if s.readyReplicas < s.replicas {
+		return phases
+	}
+	phases = append(phases, PhaseReplicasReady)
+	// old replicas must be gone for the rollout to be complete
+	if s.availableReplicas < s.replicas || s.currentReplicas > s.updatedReplicas {
+		return phases
+	}
+	return append(phases, PhaseRolloutComplete)
+}
+
+// hasNewReplicaSet reports whether the ReplicaSet owned by the Deployment at its current revision exists
+func (r *RolloutReconciler) hasNewReplicaSet(ctx context.Context, deployment *appsv1.Deployment) (bool, error) {
+	revision := deployment.Annotations[revisionAnnotation]
+	if revision == "" {
+		// the Deployment controller did not create the ReplicaSet of the revision yet
+		return false, nil
+	}
+	selector, err := metav1.LabelSelectorAsSelector(deployment.Spec.Selector)
+	if err != nil {
+		return false, fmt.Errorf("invalid selector of Deployment %s: %w", deployment.Name, err)
+	}
+
+	replicaSets := &appsv1.ReplicaSetList{}
+	if err := r.Reader.List(ctx, replicaSets, client.InNamespace(deployment.Namespace), client.MatchingLabelsSelector{Selector: selector}); err != nil {
+		return false, err
+	}
+	for _, replicaSet := range replicaSets.Items {
+		owner := metav1.GetControllerOf(&replicaSet)
+		if owner != nil && owner.UID == deployment.UID && replicaSet.Annotations[revisionAnnotation] == revision {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// getRolloutStatus reads the rollout status of a Deployment or a StatefulSet
+func getRolloutStatus(obj client.Object) (rolloutStatus, bool) {
+	switch workload := obj.(type) {
+	case *appsv1.Deployment:
+		return rolloutStatus{
+			kind:               "Deployment",
+			generation:         workload.Generation,
+			observedGeneration: workload.Status.ObservedGeneration,
+			replicas:           desiredReplicas(workload.Spec.Replicas),
+			currentReplicas:    workload.Status.Replicas,
+			updatedReplicas:    workload.Status.UpdatedReplicas,
+			readyReplicas:      workload.Status.ReadyReplicas,
+			availableReplicas:  workload.Status.AvailableReplicas,
+		}, true
+	case *appsv1.StatefulSet:
+		return rolloutStatus{
+			kind:               "StatefulSet",
+			generation:         workload.Generation,
+			observedGeneration: workload.Status.ObservedGeneration,
+			replicas:           desiredReplicas(workload.Spec.Replicas),
+			currentReplicas:    workload.Status.Replicas,
+			updatedReplicas:    workload.Status.UpdatedReplicas,
+			readyReplicas:      workload.Status.ReadyReplicas,
+			availableReplicas:  workload.Status.AvailableReplicas,
+		}, true
+	default:
+		return rolloutStatus{}, false
+	}
+}
+
+// desiredReplicas defaults the replicas of a workload spec to 1
+func desiredReplicas(replicas *int32) int32 {
+	if replicas == nil {
+		return 1
+	}
+	return *replicas
+}
+
+// progress returns the progress of the rollout for traceID, starting over when the trace changed
+func (r *RolloutReconciler) progress(key types.NamespacedName, traceID string, now time.Time) *rolloutProgress {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.rollouts == nil {
+		r.rollouts = map[types.NamespacedName]*rolloutProgress{}
+	}
+	progress, ok := r.rollouts[key]
+	if !ok || progress.traceID != traceID {
+		progress = &rolloutProgress{traceID: traceID, start: now, emitted: map[string]bool{}}
+		r.rollouts[key] = progress
+	}
+	return progress
+}
+
+// markEmitted records phase as emitted and reports whether it was not already
+func (r *RolloutReconciler) markEmitted(progress *rolloutProgress, phase string) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if progress.emitted[phase] {
+		return false
+	}
+	progress.emitted[phase] = true
+	return true
+}
+
+func (r *RolloutReconciler) forget(key types.NamespacedName) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(r.rollouts, key)
+}
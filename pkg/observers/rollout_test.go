@@ -0,0 +1,118 @@
+package observers_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/kubetracer/kubetracer-go/pkg/constants"
+	"github.com/kubetracer/kubetracer-go/pkg/observers"
+	"github.com/stretchr/testify/assert"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	appsv1 "k8s.io/api/apps/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+func newRecorder() (*tracetest.SpanRecorder, *sdktrace.TracerProvider) {
+	recorder := tracetest.NewSpanRecorder()
+	return recorder, sdktrace.NewTracerProvider(sdktrace.WithSampler(sdktrace.AlwaysSample()), sdktrace.WithSpanProcessor(recorder))
+}
+
+func spanNames(recorder *tracetest.SpanRecorder) []string {
+	names := []string{}
+	for _, span := range recorder.Ended() {
+		names = append(names, span.Name())
+	}
+	return names
+}
+
+func TestRolloutReconciler(t *testing.T) {
+	replicas := int32(2)
+	deployment := &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:       "test-deployment",
+			Namespace:  "default",
+			UID:        "deployment-uid",
+			Generation: 2,
+			Annotations: map[string]string{
+				constants.TraceIDAnnotation:         "f620f5cad0af940c294f980c5366a6a1",
+				constants.SpanIDAnnotation:          "45f359cdc1c8ab06",
+				"deployment.kubernetes.io/revision": "2",
+			},
+		},
+		Spec: appsv1.DeploymentSpec{
+			Replicas: &replicas,
+			Selector: &metav1.LabelSelector{MatchLabels: map[string]string{"app": "test"}},
+		},
+		Status: appsv1.DeploymentStatus{
+			ObservedGeneration: 2,
+			Replicas:           3,
+			UpdatedReplicas:    2,
+			ReadyReplicas:      2,
+		},
+	}
+	isController := true
+	replicaSet := func(name, revision string) *appsv1.ReplicaSet {
+		return &appsv1.ReplicaSet{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:        name,
+				Namespace:   "default",
+				Labels:      map[string]string{"app": "test"},
+				Annotations: map[string]string{"deployment.kubernetes.io/revision": revision},
+				OwnerReferences: []metav1.OwnerReference{{
+					APIVersion: "apps/v1",
+					Kind:       "Deployment",
+					Name:       "test-deployment",
+					UID:        "deployment-uid",
+					Controller: &isController,
+				}},
+			},
+		}
+	}
+	k8sClient := fake.NewClientBuilder().WithObjects(deployment, replicaSet("test-deployment-old", "1")).WithStatusSubresource(deployment).Build()
+
+	recorder, tp := newRecorder()
+	r := observers.NewRolloutReconciler(k8sClient, tp.Tracer("rollout"), &appsv1.Deployment{})
+	req := reconcile.Request{NamespacedName: types.NamespacedName{Name: "test-deployment", Namespace: "default"}}
+
+	// The ReplicaSet of the revision is not there yet
+	_, err := r.Reconcile(context.Background(), req)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{
+		"Rollout Deployment test-deployment: revision observed",
+	}, spanNames(recorder))
+
+	// The old replica is still there
+	assert.NoError(t, k8sClient.Create(context.Background(), replicaSet("test-deployment-new", "2")))
+	_, err = r.Reconcile(context.Background(), req)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{
+		"Rollout Deployment test-deployment: revision observed",
+		"Rollout Deployment test-deployment: new ReplicaSet created",
+		"Rollout Deployment test-deployment: replicas updated",
+		"Rollout Deployment test-deployment: replicas ready",
+	}, spanNames(recorder))
+
+	for _, span := range recorder.Ended() {
+		assert.Equal(t, "f620f5cad0af940c294f980c5366a6a1", span.SpanContext().TraceID().String())
+		assert.Equal(t, "45f359cdc1c8ab06", span.Parent().SpanID().String())
+	}
+
+	// The old replica is gone, only the last phase is emitted
+	deployment.Status.Replicas = 2
+	deployment.Status.AvailableReplicas = 2
+	assert.NoError(t, k8sClient.Status().Update(context.Background(), deployment))
+
+	_, err = r.Reconcile(context.Background(), req)
+	assert.NoError(t, err)
+	assert.Len(t, recorder.Ended(), 5)
+	assert.Equal(t, "Rollout Deployment test-deployment: rollout complete", recorder.Ended()[4].Name())
+
+	// Nothing new happened
+	_, err = r.Reconcile(context.Background(), req)
+	assert.NoError(t, err)
+	assert.Len(t, recorder.Ended(), 5)
+}
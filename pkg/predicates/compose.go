@@ -0,0 +1,68 @@
+package predicates
+
+import (
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+)
+
+// And returns a predicate that passes an event if all of predicates pass it.
+// Unlike predicate.And, updates where only the trace annotations, trace conditions or
+// resource version changed are never passed, whatever the combined predicates return.
+func And(predicates ...predicate.Predicate) predicate.Predicate {
+	return traceAware{predicate: predicate.And(predicates...)}
+}
+
+// Or returns a predicate that passes an event if any of predicates pass it.
+// Unlike predicate.Or, updates where only the trace annotations, trace conditions or
+// resource version changed are never passed, whatever the combined predicates return.
+func Or(predicates ...predicate.Predicate) predicate.Predicate {
+	return traceAware{predicate: predicate.Or(predicates...)}
+}
+
+// Not returns a predicate that passes an event if p does not pass it.
+// Unlike predicate.Not, updates where only the trace annotations, trace conditions or
+// resource version changed are never passed, even though p rejects them.
+func Not(p predicate.Predicate) predicate.Predicate {
+	return traceAware{predicate: predicate.Not(p)}
+}
+
+// TracedGenerationChangedPredicate passes updates that change the generation of the object,
+// i.e. its spec, and ignores updates that only change the trace, the status or the metadata.
+func TracedGenerationChangedPredicate() predicate.Predicate {
+	return And(IgnoreTraceAnnotationUpdatePredicate{}, predicate.GenerationChangedPredicate{})
+}
+
+// TracedAnnotationChangedPredicate passes updates that change the annotations of the object,
+// other than the annotations managed by kubetracer.
+func TracedAnnotationChangedPredicate() predicate.Predicate {
+	return And(IgnoreTraceAnnotationUpdatePredicate{}, predicate.AnnotationChangedPredicate{})
+}
+
+// traceAware suppresses the updates ignored by IgnoreTraceAnnotationUpdatePredicate before
+// delegating to the wrapped predicate.
+type traceAware struct {
+	predicate predicate.Predicate
+}
+
+// Create implements the create event check for the predicate.
+func (t traceAware) Create(e event.CreateEvent) bool {
+	return t.predicate.Create(e)
+}
+
+// Update implements the update event check for the predicate.
+func (t traceAware) Update(e event.UpdateEvent) bool {
+	if !(IgnoreTraceAnnotationUpdatePredicate{}).Update(e) {
+		return false
+	}
+	return t.predicate.Update(e)
+}
+
+// Delete implements the delete event check for the predicate.
+func (t traceAware) Delete(e event.DeleteEvent) bool {
+	return t.predicate.Delete(e)
+}
+
+// Generic implements the generic event check for the predicate.
+func (t traceAware) Generic(e event.GenericEvent) bool {
+	return t.predicate.Generic(e)
+}
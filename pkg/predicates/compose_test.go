@@ -0,0 +1,93 @@
+package predicates_test
+
+import (
+	"testing"
+
+	"github.com/kubetracer/kubetracer-go/pkg/constants"
+	"github.com/kubetracer/kubetracer-go/pkg/predicates"
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+)
+
+func TestComposedPredicates(t *testing.T) {
+	traceOnlyUpdate := event.UpdateEvent{
+		ObjectOld: &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{
+				Annotations: map[string]string{
+					constants.TraceIDAnnotation: "old-trace-id",
+					constants.SpanIDAnnotation:  "old-span-id",
+				},
+				ResourceVersion: "1",
+			},
+		},
+		ObjectNew: &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{
+				Annotations: map[string]string{
+					constants.TraceIDAnnotation: "new-trace-id",
+					constants.SpanIDAnnotation:  "new-span-id",
+				},
+				ResourceVersion: "2",
+			},
+		},
+	}
+
+	statusOnlyUpdate := event.UpdateEvent{
+		ObjectOld: &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Generation: 1},
+			Status:     corev1.PodStatus{Phase: corev1.PodPending},
+		},
+		ObjectNew: &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Generation: 1},
+			Status:     corev1.PodStatus{Phase: corev1.PodRunning},
+		},
+	}
+
+	specUpdate := event.UpdateEvent{
+		ObjectOld: &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Generation: 1},
+			Spec:       corev1.PodSpec{NodeName: "node-1"},
+		},
+		ObjectNew: &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Generation: 2},
+			Spec:       corev1.PodSpec{NodeName: "node-2"},
+		},
+	}
+
+	t.Run("not never passes trace only updates", func(t *testing.T) {
+		assert.True(t, predicate.Not(predicate.GenerationChangedPredicate{}).Update(traceOnlyUpdate))
+		assert.False(t, predicates.Not(predicate.GenerationChangedPredicate{}).Update(traceOnlyUpdate))
+		assert.True(t, predicates.Not(predicate.GenerationChangedPredicate{}).Update(statusOnlyUpdate))
+	})
+
+	t.Run("or never passes trace only updates", func(t *testing.T) {
+		pred := predicates.Or(predicate.GenerationChangedPredicate{}, predicate.AnnotationChangedPredicate{})
+		assert.False(t, pred.Update(traceOnlyUpdate))
+		assert.True(t, pred.Update(specUpdate))
+	})
+
+	t.Run("and passes create events", func(t *testing.T) {
+		pred := predicates.And(predicates.IgnoreTraceAnnotationUpdatePredicate{}, predicate.GenerationChangedPredicate{})
+		assert.True(t, pred.Create(event.CreateEvent{Object: &corev1.Pod{}}))
+	})
+
+	t.Run("traced generation changed", func(t *testing.T) {
+		pred := predicates.TracedGenerationChangedPredicate()
+		assert.False(t, pred.Update(traceOnlyUpdate))
+		assert.False(t, pred.Update(statusOnlyUpdate))
+		assert.True(t, pred.Update(specUpdate))
+	})
+
+	t.Run("traced annotation changed", func(t *testing.T) {
+		pred := predicates.TracedAnnotationChangedPredicate()
+		assert.False(t, pred.Update(traceOnlyUpdate))
+
+		annotationUpdate := event.UpdateEvent{
+			ObjectOld: &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Annotations: map[string]string{"key1": "value1"}}},
+			ObjectNew: &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Annotations: map[string]string{"key1": "value2"}}},
+		}
+		assert.True(t, pred.Update(annotationUpdate))
+	})
+}
@@ -1,7 +1,13 @@
 package predicates
 
 import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/go-logr/logr"
 	"github.com/kubetracer/kubetracer-go/pkg/constants"
+	"go.opentelemetry.io/otel"
 	"k8s.io/apimachinery/pkg/api/equality"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime"
@@ -10,40 +16,145 @@ import (
 )
 
 // IgnoreTraceAnnotationUpdatePredicate implements a predicate that ignores updates
-// where only the trace ID and span ID annotations, or resource version changes.
+// where only the trace ID, span ID and triggered-by annotations, or resource version changes.
 type IgnoreTraceAnnotationUpdatePredicate struct {
 	predicate.Funcs
+
+	// Logger if set logs at V(2) why each update was suppressed or allowed.
+	Logger logr.Logger
+
+	// OnDecision if set is called for each update with the decision and why it was made,
+	// e.g. "annotations changed: key1" or "status changed".
+	OnDecision func(e event.UpdateEvent, allowed bool, reason string)
+
+	// IgnoredStatusFields lists dot separated status paths, e.g. status.lastSyncTime, that are
+	// ignored like status.observedGeneration when comparing the status. Periodically updated
+	// fields such as heartbeats should be listed here so they do not defeat the suppression.
+	IgnoredStatusFields []string
+
+	// IgnoreStatusChanges if set treats updates that only change the status as non-events,
+	// for controllers that only act on the spec.
+	IgnoreStatusChanges bool
+
+	// AnnotationPrefix, TraceIDAnnotation and SpanIDAnnotation if set are the annotations the trace is
+	// stored in, matching the WithAnnotationPrefix, WithTraceIDAnnotation and WithSpanIDAnnotation
+	// options of the TracingClient. The kubetracer.io annotations are used otherwise.
+	AnnotationPrefix  string
+	TraceIDAnnotation string
+	SpanIDAnnotation  string
+}
+
+// traceKeys are the annotations the trace is stored in
+type traceKeys struct {
+	prefix  string
+	traceID string
+	spanID  string
+}
+
+// newTraceKeys returns the annotations under prefix, kubetracer.io/ when empty, with the trace ID and
+// span ID annotations replaced by traceID and spanID when set.
+func newTraceKeys(prefix, traceID, spanID string) traceKeys {
+	if prefix == "" {
+		prefix = constants.AnnotationPrefix
+	}
+	if traceID == "" {
+		traceID = prefix + "trace-id"
+	}
+	if spanID == "" {
+		spanID = prefix + "span-id"
+	}
+	return traceKeys{prefix: prefix, traceID: traceID, spanID: spanID}
+}
+
+// traceTime returns the annotation holding when the trace was attached
+func (k traceKeys) traceTime() string {
+	return k.prefix + "trace-time"
+}
+
+// annotations returns the annotations written by the TracingClient, changes to them alone are ignored.
+// The fields of the globally configured OTel propagator are included as the binary may set it after start up.
+func (k traceKeys) annotations() []string {
+	annotations := []string{
+		k.traceID,
+		k.spanID,
+		k.prefix + "triggered-by",
+		k.prefix + "trace-root",
+		k.prefix + "trace-root-kind",
+		k.prefix + "trace-root-name",
+		k.prefix + "actor",
+		k.prefix + "last-ops",
+		k.traceTime(),
+	}
+	for _, field := range otel.GetTextMapPropagator().Fields() {
+		annotations = append(annotations, "otel."+k.prefix+field)
+	}
+	return annotations
 }
 
 // Update implements the update event check for the predicate.
-func (IgnoreTraceAnnotationUpdatePredicate) Update(e event.UpdateEvent) bool {
+func (p IgnoreTraceAnnotationUpdatePredicate) Update(e event.UpdateEvent) bool {
+	// Only build the reason when someone is listening, this is called for every update
+	explain := p.OnDecision != nil || p.Logger.V(2).Enabled()
+
 	if e.ObjectOld == nil || e.ObjectNew == nil {
-		return true
+		return p.decide(e, true, explain, "object missing from update event")
 	}
 
 	oldAnnotations := e.ObjectOld.GetAnnotations()
 	newAnnotations := e.ObjectNew.GetAnnotations()
 
-	traceIDChanged := oldAnnotations[constants.TraceIDAnnotation] != newAnnotations[constants.TraceIDAnnotation]
-	spanIDChanged := oldAnnotations[constants.SpanIDAnnotation] != newAnnotations[constants.SpanIDAnnotation]
+	keys := newTraceKeys(p.AnnotationPrefix, p.TraceIDAnnotation, p.SpanIDAnnotation)
+	traceIDChanged := oldAnnotations[keys.traceID] != newAnnotations[keys.traceID]
+	spanIDChanged := oldAnnotations[keys.spanID] != newAnnotations[keys.spanID]
 	resourceGenerationChanged := e.ObjectOld.GetGeneration() != e.ObjectNew.GetGeneration()
 	resourceVersionChanged := e.ObjectOld.GetResourceVersion() != e.ObjectNew.GetResourceVersion()
-	otherAnnotationsChanged := !equalExcept(oldAnnotations, newAnnotations, constants.TraceIDAnnotation, constants.SpanIDAnnotation)
+	ignoredAnnotations := keys.annotations()
+	otherAnnotationsChanged := !equalExcept(oldAnnotations, newAnnotations, ignoredAnnotations...)
 
 	// Check if the spec or status fields have changed
-	specOrStatusChanged := hasSpecOrStatusChanged(e.ObjectOld, e.ObjectNew)
+	changedField := p.changedSpecOrStatusField(e.ObjectOld, e.ObjectNew)
+	specOrStatusChanged := changedField != ""
 
 	// If only trace ID, span ID, or resource version changed, and no other annotations, spec or status changed, ignore the update
 	if (traceIDChanged || spanIDChanged || resourceVersionChanged || resourceGenerationChanged) && !otherAnnotationsChanged && !specOrStatusChanged {
-		return false
+		return p.decide(e, false, explain, "only trace annotations, resource version or generation changed")
 	}
 
 	// Otherwise, indicate the update should be processed
-	return true
+	if !explain {
+		return true
+	}
+	switch {
+	case otherAnnotationsChanged:
+		changedKeys := changedKeysExcept(oldAnnotations, newAnnotations, ignoredAnnotations...)
+		return p.decide(e, true, explain, fmt.Sprintf("annotations changed: %s", strings.Join(changedKeys, ", ")))
+	case specOrStatusChanged:
+		return p.decide(e, true, explain, fmt.Sprintf("%s changed", changedField))
+	default:
+		return p.decide(e, true, explain, "no trace annotations, resource version or generation changed")
+	}
+}
+
+// decide reports the decision to the Logger and OnDecision callback and returns it.
+func (p IgnoreTraceAnnotationUpdatePredicate) decide(e event.UpdateEvent, allowed bool, explain bool, reason string) bool {
+	if !explain {
+		return allowed
+	}
+	if p.OnDecision != nil {
+		p.OnDecision(e, allowed, reason)
+	}
+
+	name, namespace := "", ""
+	if e.ObjectNew != nil {
+		name, namespace = e.ObjectNew.GetName(), e.ObjectNew.GetNamespace()
+	}
+	p.Logger.V(2).Info("Update event decision", "name", name, "namespace", namespace, "allowed", allowed, "reason", reason)
+	return allowed
 }
 
-// hasSpecOrStatusChanged checks if the spec or status fields have changed.
-func hasSpecOrStatusChanged(oldObj, newObj runtime.Object) bool {
+// changedSpecOrStatusField checks if the spec or status fields have changed and returns the first one that did,
+// or an empty string if neither changed.
+func (p IgnoreTraceAnnotationUpdatePredicate) changedSpecOrStatusField(oldObj, newObj runtime.Object) string {
 	oldUnstructured := objToUnstructured(oldObj)
 	newUnstructured := objToUnstructured(newObj)
 
@@ -51,10 +162,22 @@ func hasSpecOrStatusChanged(oldObj, newObj runtime.Object) bool {
 	replaceEmptyStructsAndSlicesWithNil(oldUnstructured)
 	replaceEmptyStructsAndSlicesWithNil(newUnstructured)
 
+	if hasFieldChanged(oldUnstructured, newUnstructured, "spec") {
+		return "spec"
+	}
+	if p.IgnoreStatusChanges {
+		return ""
+	}
+
+	removeIgnoredStatusFields(oldUnstructured, p.IgnoredStatusFields)
+	removeIgnoredStatusFields(newUnstructured, p.IgnoredStatusFields)
+
 	oldStatus := getFieldExcludingObservedGeneration(oldUnstructured, "status")
 	newStatus := getFieldExcludingObservedGeneration(newUnstructured, "status")
-
-	return hasFieldChanged(oldUnstructured, newUnstructured, "spec") || !equality.Semantic.DeepEqual(oldStatus, newStatus)
+	if !equality.Semantic.DeepEqual(oldStatus, newStatus) {
+		return "status"
+	}
+	return ""
 }
 
 // getFieldExcludingObservedGeneration retrieves the field and excludes the observedGeneration.
@@ -71,6 +194,17 @@ func getFieldExcludingObservedGeneration(obj map[string]interface{}, field strin
 	return status
 }
 
+// removeIgnoredStatusFields removes the ignored status paths from the object. Paths outside of the status are skipped.
+func removeIgnoredStatusFields(obj map[string]interface{}, ignoredStatusFields []string) {
+	for _, path := range ignoredStatusFields {
+		fields := strings.Split(path, ".")
+		if len(fields) < 2 || fields[0] != "status" {
+			continue
+		}
+		unstructured.RemoveNestedField(obj, fields...)
+	}
+}
+
 // hasFieldChanged checks if a specific field has changed between old and new unstructured objects.
 func hasFieldChanged(oldUnstructured, newUnstructured map[string]interface{}, field string) bool {
 	oldField, foundOld, errOld := unstructuredNestedFieldNoCopy(oldUnstructured, field)
@@ -109,6 +243,32 @@ func equalExcept(a, b map[string]string, keysToIgnore ...string) bool {
 	return true
 }
 
+// Returns the sorted keys whose values differ between two maps, ignoring certain keys.
+func changedKeysExcept(a, b map[string]string, keysToIgnore ...string) []string {
+	ignored := make(map[string]struct{})
+	for _, key := range keysToIgnore {
+		ignored[key] = struct{}{}
+	}
+
+	changed := []string{}
+	for key, aValue := range a {
+		if _, isIgnored := ignored[key]; !isIgnored {
+			if bValue, exists := b[key]; !exists || aValue != bValue {
+				changed = append(changed, key)
+			}
+		}
+	}
+	for key := range b {
+		if _, exists := a[key]; !exists {
+			if _, isIgnored := ignored[key]; !isIgnored {
+				changed = append(changed, key)
+			}
+		}
+	}
+	sort.Strings(changed)
+	return changed
+}
+
 // Recursively replaces empty structs or slices in the map with nil.
 func replaceEmptyStructsAndSlicesWithNil(m map[string]interface{}) {
 	for k, v := range m {
@@ -155,7 +315,8 @@ func unstructuredNestedFieldNoCopy(obj map[string]interface{}, fields ...string)
 	return val, true, nil
 }
 
-// removeTraceAndSpanConditions removes conditions with Type 'TraceID' or 'SpanID' from the status.
+// removeTraceAndSpanConditions removes conditions with Type 'TraceID', 'SpanID' or kubetracer.io/TraceContext from
+// the status.
 func removeTraceAndSpanConditions(statusMap map[string]interface{}) {
 	conditions, found, err := unstructured.NestedSlice(statusMap, "conditions")
 	if err != nil || !found {
@@ -165,7 +326,7 @@ func removeTraceAndSpanConditions(statusMap map[string]interface{}) {
 	for _, condition := range conditions {
 		if conditionMap, ok := condition.(map[string]interface{}); ok {
 			conditionType, _, _ := unstructured.NestedString(conditionMap, "type")
-			if conditionType != "TraceID" && conditionType != "SpanID" {
+			if conditionType != "TraceID" && conditionType != "SpanID" && conditionType != constants.TraceContextCondition {
 				filteredConditions = append(filteredConditions, condition)
 			}
 		}
@@ -184,4 +184,100 @@ func TestIgnoreTraceAnnotationUpdatePredicate(t *testing.T) {
 		result := pred.Update(updateEvent)
 		assert.False(t, result, "Expected update to be ignored when only the resource generation or traceid changes")
 	})
+
+	t.Run("decision is explained", func(t *testing.T) {
+		var allowed []bool
+		var reasons []string
+		explainedPred := predicates.IgnoreTraceAnnotationUpdatePredicate{
+			OnDecision: func(e event.UpdateEvent, a bool, reason string) {
+				allowed = append(allowed, a)
+				reasons = append(reasons, reason)
+			},
+		}
+
+		oldPod := &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{
+				Annotations:     map[string]string{constants.TraceIDAnnotation: "old-trace-id", "key1": "value1"},
+				ResourceVersion: "old-resource-version",
+			},
+		}
+		tracedPod := &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{
+				Annotations:     map[string]string{constants.TraceIDAnnotation: "new-trace-id", "key1": "value1"},
+				ResourceVersion: "new-resource-version",
+			},
+		}
+		annotatedPod := &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{
+				Annotations:     map[string]string{constants.TraceIDAnnotation: "new-trace-id", "key1": "value2", "key2": "value2"},
+				ResourceVersion: "new-resource-version",
+			},
+		}
+
+		assert.False(t, explainedPred.Update(event.UpdateEvent{ObjectOld: oldPod, ObjectNew: tracedPod}))
+		assert.True(t, explainedPred.Update(event.UpdateEvent{ObjectOld: oldPod, ObjectNew: annotatedPod}))
+
+		assert.Equal(t, []bool{false, true}, allowed)
+		assert.Equal(t, "only trace annotations, resource version or generation changed", reasons[0])
+		assert.Equal(t, "annotations changed: key1, key2", reasons[1])
+	})
+
+	t.Run("ignored status field changed", func(t *testing.T) {
+		oldDeployment := &appsv1.Deployment{
+			ObjectMeta: metav1.ObjectMeta{
+				Annotations:     map[string]string{constants.TraceIDAnnotation: "old-trace-id"},
+				ResourceVersion: "old-resource-version",
+			},
+			Status: appsv1.DeploymentStatus{
+				Replicas:      1,
+				ReadyReplicas: 0,
+			},
+		}
+		newDeployment := &appsv1.Deployment{
+			ObjectMeta: metav1.ObjectMeta{
+				Annotations:     map[string]string{constants.TraceIDAnnotation: "new-trace-id"},
+				ResourceVersion: "new-resource-version",
+			},
+			Status: appsv1.DeploymentStatus{
+				Replicas:      1,
+				ReadyReplicas: 1,
+			},
+		}
+		updateEvent := event.UpdateEvent{
+			ObjectOld: oldDeployment,
+			ObjectNew: newDeployment,
+		}
+
+		assert.True(t, pred.Update(updateEvent), "Expected update to be processed when a status field changes")
+
+		ignoringPred := predicates.IgnoreTraceAnnotationUpdatePredicate{IgnoredStatusFields: []string{"status.readyReplicas"}}
+		assert.False(t, ignoringPred.Update(updateEvent), "Expected update to be ignored when only an ignored status field changes")
+	})
+
+	t.Run("status changed with status changes ignored", func(t *testing.T) {
+		oldPod := &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{
+				ResourceVersion: "old-resource-version",
+			},
+			Status: corev1.PodStatus{
+				Phase: corev1.PodPending,
+			},
+		}
+
+		newPod := &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{
+				ResourceVersion: "new-resource-version",
+			},
+			Status: corev1.PodStatus{
+				Phase: corev1.PodRunning,
+			},
+		}
+
+		specPod := newPod.DeepCopy()
+		specPod.Spec.NodeName = "node-1"
+
+		specOnlyPred := predicates.IgnoreTraceAnnotationUpdatePredicate{IgnoreStatusChanges: true}
+		assert.False(t, specOnlyPred.Update(event.UpdateEvent{ObjectOld: oldPod, ObjectNew: newPod}), "Expected update to be ignored when only the status changes")
+		assert.True(t, specOnlyPred.Update(event.UpdateEvent{ObjectOld: oldPod, ObjectNew: specPod}), "Expected update to be processed when the spec changes")
+	})
 }
@@ -0,0 +1,68 @@
+package predicates
+
+import (
+	"time"
+
+	"go.opentelemetry.io/otel/trace"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+)
+
+var _ predicate.Predicate = TraceOnlyPredicate{}
+
+// TraceOnlyPredicate implements a predicate that only passes events for objects
+// currently carrying a valid trace ID and span ID annotation. It is meant for auxiliary
+// controllers that should only react to traced chains and ignore all other churn.
+type TraceOnlyPredicate struct {
+	// TTL if positive also drops the events of objects whose trace was attached more than TTL ago,
+	// according to the kubetracer.io/trace-time annotation, matching the WithTraceTTL of the TracingClient.
+	TTL time.Duration
+
+	// AnnotationPrefix, TraceIDAnnotation and SpanIDAnnotation if set are the annotations the trace is
+	// read from, see IgnoreTraceAnnotationUpdatePredicate.
+	AnnotationPrefix  string
+	TraceIDAnnotation string
+	SpanIDAnnotation  string
+}
+
+// Create implements the create event check for the predicate.
+func (p TraceOnlyPredicate) Create(e event.CreateEvent) bool {
+	return p.hasTrace(e.Object)
+}
+
+// Update implements the update event check for the predicate.
+func (p TraceOnlyPredicate) Update(e event.UpdateEvent) bool {
+	return p.hasTrace(e.ObjectNew)
+}
+
+// Delete implements the delete event check for the predicate.
+func (p TraceOnlyPredicate) Delete(e event.DeleteEvent) bool {
+	return p.hasTrace(e.Object)
+}
+
+// Generic implements the generic event check for the predicate.
+func (p TraceOnlyPredicate) Generic(e event.GenericEvent) bool {
+	return p.hasTrace(e.Object)
+}
+
+// hasTrace checks if the object carries a valid trace ID and span ID annotation, attached within the TTL if set.
+func (p TraceOnlyPredicate) hasTrace(obj client.Object) bool {
+	if obj == nil {
+		return false
+	}
+	annotations := obj.GetAnnotations()
+	keys := newTraceKeys(p.AnnotationPrefix, p.TraceIDAnnotation, p.SpanIDAnnotation)
+	if _, err := trace.TraceIDFromHex(annotations[keys.traceID]); err != nil {
+		return false
+	}
+	if _, err := trace.SpanIDFromHex(annotations[keys.spanID]); err != nil {
+		return false
+	}
+	if p.TTL > 0 {
+		if attached, err := time.Parse(time.RFC3339, annotations[keys.traceTime()]); err == nil && time.Since(attached) > p.TTL {
+			return false
+		}
+	}
+	return true
+}
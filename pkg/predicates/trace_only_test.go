@@ -0,0 +1,69 @@
+package predicates_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/kubetracer/kubetracer-go/pkg/constants"
+	"github.com/kubetracer/kubetracer-go/pkg/predicates"
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+)
+
+func TestTraceOnlyPredicate(t *testing.T) {
+	pred := predicates.TraceOnlyPredicate{}
+
+	tracedPod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Annotations: map[string]string{
+				constants.TraceIDAnnotation: "f620f5cad0af940c294f980c5366a6a1",
+				constants.SpanIDAnnotation:  "45f359cdc1c8ab06",
+			},
+		},
+	}
+
+	t.Run("object with trace", func(t *testing.T) {
+		assert.True(t, pred.Create(event.CreateEvent{Object: tracedPod}))
+		assert.True(t, pred.Delete(event.DeleteEvent{Object: tracedPod}))
+		assert.True(t, pred.Generic(event.GenericEvent{Object: tracedPod}))
+	})
+
+	t.Run("object without trace", func(t *testing.T) {
+		pod := &corev1.Pod{}
+		assert.False(t, pred.Create(event.CreateEvent{Object: pod}))
+		assert.False(t, pred.Delete(event.DeleteEvent{Object: pod}))
+		assert.False(t, pred.Generic(event.GenericEvent{Object: pod}))
+	})
+
+	t.Run("object with invalid trace", func(t *testing.T) {
+		pod := &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{
+				Annotations: map[string]string{
+					constants.TraceIDAnnotation: "1234",
+					constants.SpanIDAnnotation:  "5678",
+				},
+			},
+		}
+		assert.False(t, pred.Create(event.CreateEvent{Object: pod}))
+	})
+
+	t.Run("update uses the new object", func(t *testing.T) {
+		assert.True(t, pred.Update(event.UpdateEvent{ObjectOld: &corev1.Pod{}, ObjectNew: tracedPod}))
+		assert.False(t, pred.Update(event.UpdateEvent{ObjectOld: tracedPod, ObjectNew: &corev1.Pod{}}))
+	})
+
+	t.Run("stale trace with TTL", func(t *testing.T) {
+		withTime := func(attached time.Time) *corev1.Pod {
+			pod := tracedPod.DeepCopy()
+			pod.Annotations[constants.TraceTimeAnnotation] = attached.UTC().Format(time.RFC3339)
+			return pod
+		}
+		ttlPred := predicates.TraceOnlyPredicate{TTL: time.Hour}
+		assert.True(t, ttlPred.Create(event.CreateEvent{Object: withTime(time.Now())}))
+		assert.False(t, ttlPred.Create(event.CreateEvent{Object: withTime(time.Now().Add(-7 * 24 * time.Hour))}))
+		assert.True(t, ttlPred.Create(event.CreateEvent{Object: tracedPod}))
+		assert.True(t, pred.Create(event.CreateEvent{Object: withTime(time.Now().Add(-7 * 24 * time.Hour))}))
+	})
+}
@@ -0,0 +1,79 @@
+package query
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// JaegerClient fetches traces from the HTTP API of Jaeger query
+type JaegerClient struct {
+	url        string
+	httpClient *http.Client
+}
+
+var _ Client = &JaegerClient{}
+
+// NewJaegerClient returns a JaegerClient for the Jaeger query service at url, e.g. http://jaeger-query:16686
+func NewJaegerClient(url string, httpClient *http.Client) *JaegerClient {
+	return &JaegerClient{url: strings.TrimSuffix(url, "/"), httpClient: httpClient}
+}
+
+// jaegerResponse is the response of /api/traces/{traceID}
+type jaegerResponse struct {
+	Data []struct {
+		Spans []struct {
+			TraceID       string `json:"traceID"`
+			SpanID        string `json:"spanID"`
+			OperationName string `json:"operationName"`
+			References    []struct {
+				RefType string `json:"refType"`
+				SpanID  string `json:"spanID"`
+			} `json:"references"`
+			// StartTime and Duration are in microseconds
+			StartTime int64 `json:"startTime"`
+			Duration  int64 `json:"duration"`
+			Tags      []struct {
+				Key   string      `json:"key"`
+				Value interface{} `json:"value"`
+			} `json:"tags"`
+		} `json:"spans"`
+	} `json:"data"`
+}
+
+// Trace implements Client
+func (c *JaegerClient) Trace(ctx context.Context, traceID string) ([]*Span, error) {
+	var resp jaegerResponse
+	if err := getJSON(ctx, c.httpClient, fmt.Sprintf("%s/api/traces/%s", c.url, traceID), &resp); err != nil {
+		return nil, err
+	}
+	if len(resp.Data) == 0 {
+		return nil, ErrTraceNotFound
+	}
+
+	var spans []*Span
+	for _, s := range resp.Data[0].Spans {
+		span := &Span{
+			TraceID:  s.TraceID,
+			SpanID:   s.SpanID,
+			Name:     s.OperationName,
+			Start:    time.UnixMicro(s.StartTime),
+			Duration: time.Duration(s.Duration) * time.Microsecond,
+		}
+		for _, ref := range s.References {
+			if ref.RefType == "CHILD_OF" {
+				span.ParentSpanID = ref.SpanID
+				break
+			}
+		}
+		for _, tag := range s.Tags {
+			if tag.Key == "error" && tag.Value == true {
+				span.Error = true
+			}
+		}
+		spans = append(spans, span)
+	}
+	return buildTree(spans), nil
+}
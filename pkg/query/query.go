@@ -0,0 +1,154 @@
+package query
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Environment variables configuring NewClientFromEnv
+const (
+	// BackendEnv selects the backend, "jaeger" or "tempo"
+	BackendEnv = "KUBETRACER_QUERY_BACKEND"
+	// URLEnv is the base URL of the query API of the backend, e.g. http://jaeger-query:16686
+	URLEnv = "KUBETRACER_QUERY_URL"
+)
+
+// ErrTraceNotFound is returned when the backend does not know the trace
+var ErrTraceNotFound = errors.New("trace not found")
+
+// Span is a span of a fetched trace
+type Span struct {
+	TraceID      string
+	SpanID       string
+	ParentSpanID string
+	Name         string
+	Start        time.Time
+	Duration     time.Duration
+	Error        bool
+
+	// Children are the child spans, ordered by start time
+	Children []*Span
+}
+
+// Client fetches traces from a tracing backend
+type Client interface {
+	// Trace returns the root spans of the trace with the hex encoded traceID, ordered by start time
+	Trace(ctx context.Context, traceID string) ([]*Span, error)
+}
+
+// NewClientFromEnv returns the Client configured by BackendEnv and URLEnv, or nil if URLEnv is not set
+func NewClientFromEnv() (Client, error) {
+	url := os.Getenv(URLEnv)
+	if url == "" {
+		return nil, nil
+	}
+	return NewClient(os.Getenv(BackendEnv), url)
+}
+
+// NewClient returns the Client of backend, "jaeger" or "tempo", at url
+func NewClient(backend, url string) (Client, error) {
+	switch strings.ToLower(backend) {
+	case "", "jaeger":
+		return NewJaegerClient(url, http.DefaultClient), nil
+	case "tempo":
+		return NewTempoClient(url, http.DefaultClient), nil
+	default:
+		return nil, fmt.Errorf("unsupported tracing backend %q", backend)
+	}
+}
+
+// getJSON decodes the JSON response of a GET of url into v
+func getJSON(ctx context.Context, httpClient *http.Client, url string, v interface{}) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return fmt.Errorf("problem building the request: %w", err)
+	}
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("problem querying %s: %w", url, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return ErrTraceNotFound
+	}
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("querying %s returned %s: %s", url, resp.Status, strings.TrimSpace(string(body)))
+	}
+
+	if err := json.NewDecoder(resp.Body).Decode(v); err != nil {
+		return fmt.Errorf("problem decoding the response of %s: %w", url, err)
+	}
+	return nil
+}
+
+// buildTree links spans to their parents and returns the roots, spans whose parent is not part of the
+// trace, ordered by start time
+func buildTree(spans []*Span) []*Span {
+	bySpanID := make(map[string]*Span, len(spans))
+	for _, span := range spans {
+		bySpanID[span.SpanID] = span
+	}
+
+	var roots []*Span
+	for _, span := range spans {
+		if parent, ok := bySpanID[span.ParentSpanID]; ok && span.ParentSpanID != "" {
+			parent.Children = append(parent.Children, span)
+			continue
+		}
+		roots = append(roots, span)
+	}
+
+	sortByStart(roots)
+	for _, span := range spans {
+		sortByStart(span.Children)
+	}
+	return roots
+}
+
+func sortByStart(spans []*Span) {
+	sort.SliceStable(spans, func(i, j int) bool {
+		return spans[i].Start.Before(spans[j].Start)
+	})
+}
+
+// Render writes the span tree of roots to w, one span per line with its duration and errors flagged:
+//
+//	StartTrace Deployment deploy01 (12ms)
+//	  Update Deployment deploy01 (4ms)
+//	  Create Pod pod01 (3ms) ERROR
+func Render(w io.Writer, roots []*Span) error {
+	for _, root := range roots {
+		if err := render(w, root, 0); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func render(w io.Writer, span *Span, depth int) error {
+	line := fmt.Sprintf("%s%s (%s)", strings.Repeat("  ", depth), span.Name, span.Duration)
+	if span.Error {
+		line += " ERROR"
+	}
+	if _, err := fmt.Fprintln(w, line); err != nil {
+		return err
+	}
+	for _, child := range span.Children {
+		if err := render(w, child, depth+1); err != nil {
+			return err
+		}
+	}
+	return nil
+}
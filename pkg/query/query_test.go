@@ -0,0 +1,85 @@
+package query_test
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/kubetracer/kubetracer-go/pkg/query"
+	"github.com/stretchr/testify/assert"
+)
+
+const jaegerTrace = `{"data":[{"traceID":"f620f5cad0af940c294f980c5366a6a1","spans":[
+	{"traceID":"f620f5cad0af940c294f980c5366a6a1","spanID":"0000000000000002","operationName":"Create Pod pod01",
+	 "references":[{"refType":"CHILD_OF","spanID":"0000000000000001"}],"startTime":1000200,"duration":3000,
+	 "tags":[{"key":"error","type":"bool","value":true}]},
+	{"traceID":"f620f5cad0af940c294f980c5366a6a1","spanID":"0000000000000001","operationName":"StartTrace Deployment deploy01",
+	 "references":[],"startTime":1000000,"duration":12000,"tags":[]},
+	{"traceID":"f620f5cad0af940c294f980c5366a6a1","spanID":"0000000000000003","operationName":"Update Deployment deploy01",
+	 "references":[{"refType":"CHILD_OF","spanID":"0000000000000001"}],"startTime":1000100,"duration":4000,"tags":[]}
+]}]}`
+
+// the IDs are base64 encoded, as returned by Tempo
+const tempoTrace = `{"batches":[{"scopeSpans":[{"spans":[
+	{"traceId":"9iD1ytCvlAwpT5gMU2amoQ==","spanId":"AAAAAAAAAAE=","name":"StartTrace Deployment deploy01",
+	 "startTimeUnixNano":"1000000000","endTimeUnixNano":"1012000000","status":{}},
+	{"traceId":"9iD1ytCvlAwpT5gMU2amoQ==","spanId":"AAAAAAAAAAI=","parentSpanId":"AAAAAAAAAAE=","name":"Create Pod pod01",
+	 "startTimeUnixNano":"1000200000","endTimeUnixNano":"1003200000","status":{"code":"STATUS_CODE_ERROR"}},
+	{"traceId":"9iD1ytCvlAwpT5gMU2amoQ==","spanId":"AAAAAAAAAAM=","parentSpanId":"AAAAAAAAAAE=","name":"Update Deployment deploy01",
+	 "startTimeUnixNano":"1000100000","endTimeUnixNano":"1004100000","status":{}}
+]}]}]}`
+
+const rendered = `StartTrace Deployment deploy01 (12ms)
+  Update Deployment deploy01 (4ms)
+  Create Pod pod01 (3ms) ERROR
+`
+
+func newBackend(t *testing.T, body string) *httptest.Server {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/traces/f620f5cad0af940c294f980c5366a6a1" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Write([]byte(body))
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestTraceQuery(t *testing.T) {
+	for backend, body := range map[string]string{"jaeger": jaegerTrace, "tempo": tempoTrace} {
+		t.Run(backend, func(t *testing.T) {
+			server := newBackend(t, body)
+			t.Setenv(query.BackendEnv, backend)
+			t.Setenv(query.URLEnv, server.URL)
+
+			client, err := query.NewClientFromEnv()
+			assert.NoError(t, err)
+
+			roots, err := client.Trace(context.Background(), "f620f5cad0af940c294f980c5366a6a1")
+			assert.NoError(t, err)
+			assert.Len(t, roots, 1)
+			assert.Equal(t, "0000000000000001", roots[0].SpanID)
+			assert.Equal(t, "f620f5cad0af940c294f980c5366a6a1", roots[0].TraceID)
+
+			out := &bytes.Buffer{}
+			assert.NoError(t, query.Render(out, roots))
+			assert.Equal(t, rendered, out.String())
+
+			_, err = client.Trace(context.Background(), "0af7651916cd43dd8448eb211c80319c")
+			assert.ErrorIs(t, err, query.ErrTraceNotFound)
+		})
+	}
+}
+
+func TestNewClientFromEnv(t *testing.T) {
+	t.Setenv(query.URLEnv, "")
+	client, err := query.NewClientFromEnv()
+	assert.NoError(t, err)
+	assert.Nil(t, client)
+
+	_, err = query.NewClient("zipkin", "http://zipkin:9411")
+	assert.Error(t, err)
+}
@@ -0,0 +1,87 @@
+package query
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// TempoClient fetches traces from the HTTP API of Grafana Tempo
+type TempoClient struct {
+	url        string
+	httpClient *http.Client
+}
+
+var _ Client = &TempoClient{}
+
+// NewTempoClient returns a TempoClient for the Tempo query frontend at url, e.g. http://tempo:3200
+func NewTempoClient(url string, httpClient *http.Client) *TempoClient {
+	return &TempoClient{url: strings.TrimSuffix(url, "/"), httpClient: httpClient}
+}
+
+// tempoResponse is the OTLP JSON response of /api/traces/{traceID}
+type tempoResponse struct {
+	Batches []struct {
+		ScopeSpans []struct {
+			Spans []struct {
+				TraceID           string `json:"traceId"`
+				SpanID            string `json:"spanId"`
+				ParentSpanID      string `json:"parentSpanId"`
+				Name              string `json:"name"`
+				StartTimeUnixNano string `json:"startTimeUnixNano"`
+				EndTimeUnixNano   string `json:"endTimeUnixNano"`
+				Status            struct {
+					Code interface{} `json:"code"`
+				} `json:"status"`
+			} `json:"spans"`
+		} `json:"scopeSpans"`
+	} `json:"batches"`
+}
+
+// Trace implements Client
+func (c *TempoClient) Trace(ctx context.Context, traceID string) ([]*Span, error) {
+	var resp tempoResponse
+	if err := getJSON(ctx, c.httpClient, fmt.Sprintf("%s/api/traces/%s", c.url, traceID), &resp); err != nil {
+		return nil, err
+	}
+
+	var spans []*Span
+	for _, batch := range resp.Batches {
+		for _, scopeSpans := range batch.ScopeSpans {
+			for _, s := range scopeSpans.Spans {
+				start, _ := strconv.ParseInt(s.StartTimeUnixNano, 10, 64)
+				end, _ := strconv.ParseInt(s.EndTimeUnixNano, 10, 64)
+				spans = append(spans, &Span{
+					TraceID:      otlpID(s.TraceID),
+					SpanID:       otlpID(s.SpanID),
+					ParentSpanID: otlpID(s.ParentSpanID),
+					Name:         s.Name,
+					Start:        time.Unix(0, start),
+					Duration:     time.Duration(end - start),
+					Error:        s.Status.Code == "STATUS_CODE_ERROR" || s.Status.Code == float64(2),
+				})
+			}
+		}
+	}
+	if len(spans) == 0 {
+		return nil, ErrTraceNotFound
+	}
+	return buildTree(spans), nil
+}
+
+// otlpID returns the hex encoding of an ID of OTLP JSON, which Tempo encodes in base64
+func otlpID(id string) string {
+	if _, err := hex.DecodeString(id); err == nil && (len(id) == 16 || len(id) == 32) {
+		return id
+	}
+	decoded, err := base64.StdEncoding.DecodeString(id)
+	if err != nil {
+		return id
+	}
+	return hex.EncodeToString(decoded)
+}
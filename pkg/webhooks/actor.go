@@ -0,0 +1,107 @@
+package webhook
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+
+	admissionv1 "k8s.io/api/admission/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+)
+
+var _ admission.Handler = &actorAnnotator{}
+
+// NewActorAnnotator returns a mutating admission handler that records the user or service account of the request
+// in the kubetracer.io/actor annotation of objects whose trace is started or changed by the request, or which
+// carry a trace without an actor.  The actor of an unchanged trace is never overwritten.  The TracingClient
+// attributes the spans of the trace to the actor, and carries it over to the objects it writes, so traces show
+// the human or system that kicked things off.  It works on objects of any kind:
+//
+//	mgr.GetWebhookServer().Register("/mutate-kubetracer-actor", &webhook.Admission{
+//		Handler: kubetracerwebhook.NewActorAnnotator(mgr.GetScheme()),
+//	})
+//
+// Pass WithAnnotationPrefix or WithTraceAnnotations when the TracingClients store the trace in other annotations.
+func NewActorAnnotator(scheme *runtime.Scheme, opts ...Option) admission.Handler {
+	return &actorAnnotator{
+		decoder: admission.NewDecoder(scheme),
+		options: newOptions(opts),
+	}
+}
+
+type actorAnnotator struct {
+	// decoder decodes the objects of the admission request
+	decoder admission.Decoder
+
+	// options are the annotations the trace is read from and the actor recorded in
+	options *options
+}
+
+// Handle implements admission.Handler.
+func (a *actorAnnotator) Handle(_ context.Context, req admission.Request) admission.Response {
+	if req.Operation != admissionv1.Create && req.Operation != admissionv1.Update {
+		return admission.Allowed("")
+	}
+
+	obj := &unstructured.Unstructured{}
+	if err := a.decoder.DecodeRaw(req.Object, obj); err != nil {
+		return admission.Errored(http.StatusBadRequest, err)
+	}
+	var oldObj *unstructured.Unstructured
+	if len(req.OldObject.Raw) > 0 {
+		oldObj = &unstructured.Unstructured{}
+		if err := a.decoder.DecodeRaw(req.OldObject, oldObj); err != nil {
+			return admission.Errored(http.StatusBadRequest, err)
+		}
+	}
+
+	if !a.options.recordActor(obj, oldObj, req.UserInfo.Username) {
+		return admission.Allowed("")
+	}
+
+	marshaledObj, err := json.Marshal(obj)
+	if err != nil {
+		return admission.Errored(http.StatusInternalServerError, err)
+	}
+	return admission.PatchResponseFromRaw(req.Object.Raw, marshaledObj)
+}
+
+// recordActor writes actor in the actor annotation of obj if it carries a trace and has no actor, or if its trace
+// was started or changed by the request, oldObj being the object before an update, and reports whether it did.
+// An actor written by the request along with the trace, e.g. carried over by a TracingClient, is kept, and so is
+// the actor of an unchanged trace.
+func (o *options) recordActor(obj, oldObj client.Object, actor string) bool {
+	if actor == "" {
+		return false
+	}
+	spanContext := o.spanContext(obj)
+	if !spanContext.IsValid() {
+		return false
+	}
+
+	annotations := obj.GetAnnotations()
+	if current := annotations[o.actorKey]; current != "" {
+		if oldObj == nil {
+			// written by the request creating the object
+			return false
+		}
+		if o.spanContext(oldObj).TraceID() == spanContext.TraceID() {
+			// the trace is unchanged, so is its actor
+			return false
+		}
+		if current != oldObj.GetAnnotations()[o.actorKey] {
+			// written by the request along with the new trace
+			return false
+		}
+		if current == actor {
+			return false
+		}
+	}
+
+	annotations[o.actorKey] = actor
+	obj.SetAnnotations(annotations)
+	return true
+}
@@ -0,0 +1,127 @@
+package webhook_test
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/kubetracer/kubetracer-go/pkg/constants"
+	webhook "github.com/kubetracer/kubetracer-go/pkg/webhooks"
+	"github.com/stretchr/testify/assert"
+	admissionv1 "k8s.io/api/admission/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+)
+
+func configMapWithTrace(traceID, actor string) *corev1.ConfigMap {
+	configMap := &corev1.ConfigMap{
+		TypeMeta:   metav1.TypeMeta{APIVersion: "v1", Kind: "ConfigMap"},
+		ObjectMeta: metav1.ObjectMeta{Name: "test-configmap", Namespace: "default", Annotations: map[string]string{}},
+	}
+	if traceID != "" {
+		configMap.Annotations[constants.TraceIDAnnotation] = traceID
+		configMap.Annotations[constants.SpanIDAnnotation] = "45f359cdc1c8ab06"
+	}
+	if actor != "" {
+		configMap.Annotations[constants.ActorAnnotation] = actor
+	}
+	return configMap
+}
+
+func actorRequest(t *testing.T, obj, oldObj runtime.Object) admission.Request {
+	req := admission.Request{
+		AdmissionRequest: admissionv1.AdmissionRequest{
+			Operation: admissionv1.Create,
+			Namespace: "default",
+		},
+	}
+	req.UserInfo.Username = "alice"
+	raw, err := json.Marshal(obj)
+	assert.NoError(t, err)
+	req.Object = runtime.RawExtension{Raw: raw}
+	if oldObj != nil {
+		req.Operation = admissionv1.Update
+		raw, err := json.Marshal(oldObj)
+		assert.NoError(t, err)
+		req.OldObject = runtime.RawExtension{Raw: raw}
+	}
+	return req
+}
+
+func TestActorAnnotator(t *testing.T) {
+	h := webhook.NewActorAnnotator(clientgoscheme.Scheme)
+	ctx := context.Background()
+
+	t.Run("trace started", func(t *testing.T) {
+		resp := h.Handle(ctx, actorRequest(t, configMapWithTrace("f620f5cad0af940c294f980c5366a6a1", ""), nil))
+
+		assert.True(t, resp.Allowed)
+		assert.Len(t, resp.Patches, 1)
+		assert.Equal(t, "/metadata/annotations/kubetracer.io~1actor", resp.Patches[0].Path)
+		assert.Equal(t, "alice", resp.Patches[0].Value)
+	})
+
+	t.Run("trace changed", func(t *testing.T) {
+		oldConfigMap := configMapWithTrace("f620f5cad0af940c294f980c5366a6a1", "bob")
+		resp := h.Handle(ctx, actorRequest(t, configMapWithTrace("0af7651916cd43dd8448eb211c80319c", "bob"), oldConfigMap))
+
+		assert.True(t, resp.Allowed)
+		assert.Len(t, resp.Patches, 1)
+		assert.Equal(t, "alice", resp.Patches[0].Value)
+	})
+
+	t.Run("actor carried over by the request", func(t *testing.T) {
+		resp := h.Handle(ctx, actorRequest(t, configMapWithTrace("f620f5cad0af940c294f980c5366a6a1", "bob"), nil))
+
+		assert.True(t, resp.Allowed)
+		assert.Empty(t, resp.Patches)
+	})
+
+	t.Run("trace unchanged without actor", func(t *testing.T) {
+		configMap := configMapWithTrace("f620f5cad0af940c294f980c5366a6a1", "")
+		resp := h.Handle(ctx, actorRequest(t, configMap, configMap))
+
+		assert.True(t, resp.Allowed)
+		assert.Len(t, resp.Patches, 1)
+		assert.Equal(t, "alice", resp.Patches[0].Value)
+	})
+
+	t.Run("trace unchanged", func(t *testing.T) {
+		configMap := configMapWithTrace("f620f5cad0af940c294f980c5366a6a1", "bob")
+		resp := h.Handle(ctx, actorRequest(t, configMap, configMap))
+
+		assert.True(t, resp.Allowed)
+		assert.Empty(t, resp.Patches)
+	})
+
+	t.Run("object without trace", func(t *testing.T) {
+		resp := h.Handle(ctx, actorRequest(t, configMapWithTrace("", ""), nil))
+
+		assert.True(t, resp.Allowed)
+		assert.Empty(t, resp.Patches)
+	})
+}
+
+func TestActorAnnotatorAnnotationPrefix(t *testing.T) {
+	h := webhook.NewActorAnnotator(clientgoscheme.Scheme, webhook.WithAnnotationPrefix("example.com/"))
+	configMap := &corev1.ConfigMap{
+		TypeMeta: metav1.TypeMeta{APIVersion: "v1", Kind: "ConfigMap"},
+		ObjectMeta: metav1.ObjectMeta{Name: "test-configmap", Namespace: "default", Annotations: map[string]string{
+			"example.com/trace-id": "f620f5cad0af940c294f980c5366a6a1",
+			"example.com/span-id":  "45f359cdc1c8ab06",
+		}},
+	}
+	resp := h.Handle(context.Background(), actorRequest(t, configMap, nil))
+
+	assert.True(t, resp.Allowed)
+	assert.Len(t, resp.Patches, 1)
+	assert.Equal(t, "/metadata/annotations/example.com~1actor", resp.Patches[0].Path)
+	assert.Equal(t, "alice", resp.Patches[0].Value)
+
+	// the kubetracer.io annotations are not read
+	resp = h.Handle(context.Background(), actorRequest(t, configMapWithTrace("f620f5cad0af940c294f980c5366a6a1", ""), nil))
+	assert.Empty(t, resp.Patches)
+}
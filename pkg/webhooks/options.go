@@ -0,0 +1,57 @@
+package webhook
+
+import (
+	kubetracer "github.com/kubetracer/kubetracer-go/pkg/client"
+	"github.com/kubetracer/kubetracer-go/pkg/constants"
+	"go.opentelemetry.io/otel/trace"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// Option configures the admission handlers of this package
+type Option func(*options)
+
+type options struct {
+	// traceIDKey and spanIDKey are the annotations the trace is read from
+	traceIDKey string
+	spanIDKey  string
+
+	// actorKey is the annotation the actor is recorded in
+	actorKey string
+}
+
+// newOptions returns the options of the kubetracer.io annotations with opts applied
+func newOptions(opts []Option) *options {
+	o := &options{
+		traceIDKey: constants.TraceIDAnnotation,
+		spanIDKey:  constants.SpanIDAnnotation,
+		actorKey:   constants.ActorAnnotation,
+	}
+	for _, opt := range opts {
+		opt(o)
+	}
+	return o
+}
+
+// WithAnnotationPrefix makes the handlers use the annotations with prefix in place of kubetracer.io/, for
+// TracingClients configured with the same WithAnnotationPrefix.
+func WithAnnotationPrefix(prefix string) Option {
+	return func(o *options) {
+		o.traceIDKey = prefix + "trace-id"
+		o.spanIDKey = prefix + "span-id"
+		o.actorKey = prefix + "actor"
+	}
+}
+
+// WithTraceAnnotations makes the handlers read the trace from the traceIDKey and spanIDKey annotations, for
+// TracingClients configured with WithTraceIDAnnotation or WithSpanIDAnnotation.
+func WithTraceAnnotations(traceIDKey, spanIDKey string) Option {
+	return func(o *options) {
+		o.traceIDKey = traceIDKey
+		o.spanIDKey = spanIDKey
+	}
+}
+
+// spanContext returns the trace of obj, invalid if it carries none
+func (o *options) spanContext(obj client.Object) trace.SpanContext {
+	return kubetracer.SpanContextFromObject(obj, kubetracer.WithTraceAnnotations(o.traceIDKey, o.spanIDKey))
+}
@@ -0,0 +1,345 @@
+package webhook
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	kubetracer "github.com/kubetracer/kubetracer-go/pkg/client"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/util/workqueue"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+)
+
+const (
+	// ownerLookupTimeout bounds the read of the owner of a Pod, well within the default webhook timeout of 10s
+	ownerLookupTimeout = 2 * time.Second
+	// responseMargin is kept from the admission deadline to build and send the response
+	responseMargin = 100 * time.Millisecond
+	// repairDelay is the delay before the first lookup of the Pods admitted without their trace, doubled on
+	// each of the repairAttempts lookups
+	repairDelay    = time.Second
+	repairAttempts = 5
+
+	// TraceparentEnv is the W3C trace context environment variable read by the OTel SDKs
+	TraceparentEnv = "TRACEPARENT"
+	// TracestateEnv is the W3C trace state environment variable read by the OTel SDKs
+	TracestateEnv = "TRACESTATE"
+)
+
+var _ admission.Handler = &PodTraceparentInjector{}
+var _ manager.Runnable = &PodTraceparentInjector{}
+
+// NewPodTraceparentInjector returns a mutating admission handler for Pods that injects the TRACEPARENT and
+// TRACESTATE environment variables into the containers of Pods carrying a trace, so application processes
+// join the operator trace even when the Pod was created by a native controller rather than a TracingClient.
+//
+// The trace is read from the annotations of the Pod.  If the Pod has none and reader is not nil, the
+// annotations of its controller owner, e.g. the ReplicaSet, are used.  Containers which already set
+// TRACEPARENT are left untouched.  The Pod is also given the kubetracer.io/actor annotation of the object the
+// trace was read from, or else the user of the request, unless it has one.  Pass WithAnnotationPrefix or
+// WithTraceAnnotations when the TracingClients store the trace in other annotations.
+//
+// The read of the owner is bounded so the injector never makes Pod writes time out: it takes at most 2s, and
+// ends before the deadline of the admission context if it has one.  When it runs out, the Pod is allowed with
+// only the actor of the request.  If the injector is set up with the manager, the owner is read again in the
+// background and the trace annotations and actor of its Pods admitted meanwhile are patched.  The environment of
+// a created Pod cannot be changed, so their processes do not join the trace.  Register it with the manager's
+// webhook server:
+//
+//	injector := kubetracerwebhook.NewPodTraceparentInjector(mgr.GetScheme(), mgr.GetAPIReader())
+//	mgr.GetWebhookServer().Register("/mutate-v1-pod-traceparent", &webhook.Admission{Handler: injector})
+//	injector.SetupWithManager(mgr)
+func NewPodTraceparentInjector(scheme *runtime.Scheme, reader client.Reader, opts ...Option) *PodTraceparentInjector {
+	return &PodTraceparentInjector{
+		decoder: admission.NewDecoder(scheme),
+		reader:  reader,
+		options: newOptions(opts),
+		queue: workqueue.NewTypedRateLimitingQueueWithConfig(
+			workqueue.NewTypedItemExponentialFailureRateLimiter[pendingPods](repairDelay, repairDelay<<repairAttempts),
+			workqueue.TypedRateLimitingQueueConfig[pendingPods]{Name: "kubetracer-pod-traceparent"},
+		),
+	}
+}
+
+// PodTraceparentInjector is the admission handler returned by NewPodTraceparentInjector
+type PodTraceparentInjector struct {
+	// Writer if set patches the Pods admitted before the trace of their owner could be read.  The injector must
+	// then be started, SetupWithManager sets it to the client of the manager.
+	Writer client.Writer
+
+	// decoder decodes the Pod of the admission request
+	decoder admission.Decoder
+
+	// reader is used to read the owner of Pods without a trace, and to list the Pods to repair
+	reader client.Reader
+
+	// options are the annotations the trace is read from and the actor recorded in
+	options *options
+
+	// queue holds the owners whose Pods were admitted without their trace
+	queue workqueue.TypedRateLimitingInterface[pendingPods]
+}
+
+// pendingPods are the Pods of an owner admitted without its trace
+type pendingPods struct {
+	namespace  string
+	apiVersion string
+	kind       string
+	name       string
+	uid        types.UID
+
+	// username is the user of the admission request, the actor given to the Pods
+	username string
+
+	// admitted is when the Pods were admitted, truncated to the precision of the creation timestamps
+	admitted time.Time
+}
+
+// SetupWithManager adds the injector to the manager to repair the Pods admitted before the trace of their owner
+// could be read
+func (p *PodTraceparentInjector) SetupWithManager(mgr manager.Manager) error {
+	if p.Writer == nil {
+		p.Writer = mgr.GetClient()
+	}
+	return mgr.Add(p)
+}
+
+// Handle implements admission.Handler.
+func (p *PodTraceparentInjector) Handle(ctx context.Context, req admission.Request) admission.Response {
+	pod := &corev1.Pod{}
+	if err := p.decoder.Decode(req, pod); err != nil {
+		return admission.Errored(http.StatusBadRequest, err)
+	}
+
+	spanContext, source, err := p.spanContextForPod(ctx, req.Namespace, pod)
+	if errors.Is(err, context.DeadlineExceeded) {
+		p.enqueueRepair(req, pod)
+		p.options.addActorAnnotation(pod, nil, req.UserInfo.Username)
+		return patchResponse(req, pod)
+	}
+	if err != nil {
+		return admission.Allowed("no trace context found")
+	}
+	p.options.addActorAnnotation(pod, source, req.UserInfo.Username)
+
+	// the annotations do not carry the sampling decision, the operator records its spans so its
+	// children should be recorded as well
+	ctx = trace.ContextWithRemoteSpanContext(ctx, spanContext.WithTraceFlags(trace.FlagsSampled))
+	carrier := propagation.MapCarrier{}
+	propagation.TraceContext{}.Inject(ctx, carrier)
+
+	env := []corev1.EnvVar{{Name: TraceparentEnv, Value: carrier.Get("traceparent")}}
+	if tracestate := carrier.Get("tracestate"); tracestate != "" {
+		env = append(env, corev1.EnvVar{Name: TracestateEnv, Value: tracestate})
+	}
+	for i := range pod.Spec.InitContainers {
+		injectEnv(&pod.Spec.InitContainers[i], env)
+	}
+	for i := range pod.Spec.Containers {
+		injectEnv(&pod.Spec.Containers[i], env)
+	}
+	return patchResponse(req, pod)
+}
+
+// patchResponse returns the response allowing the Pod of req with the changes made to pod
+func patchResponse(req admission.Request, pod *corev1.Pod) admission.Response {
+	marshaledPod, err := json.Marshal(pod)
+	if err != nil {
+		return admission.Errored(http.StatusInternalServerError, err)
+	}
+	return admission.PatchResponseFromRaw(req.Object.Raw, marshaledPod)
+}
+
+// enqueueRepair queues the lookup of the Pods of the controller owner of pod, admitted without its trace, if
+// the injector can repair them
+func (p *PodTraceparentInjector) enqueueRepair(req admission.Request, pod *corev1.Pod) {
+	ownerRef := metav1.GetControllerOf(pod)
+	if p.Writer == nil || ownerRef == nil {
+		return
+	}
+	p.queue.AddRateLimited(pendingPods{
+		namespace:  req.Namespace,
+		apiVersion: ownerRef.APIVersion,
+		kind:       ownerRef.Kind,
+		name:       ownerRef.Name,
+		uid:        ownerRef.UID,
+		username:   req.UserInfo.Username,
+		admitted:   time.Now().Truncate(time.Second),
+	})
+}
+
+// Start implements manager.Runnable.  It repairs the Pods admitted without their trace until ctx is done.
+func (p *PodTraceparentInjector) Start(ctx context.Context) error {
+	go func() {
+		<-ctx.Done()
+		p.queue.ShutDown()
+	}()
+	for p.processNextRepair(ctx) {
+	}
+	return nil
+}
+
+// NeedLeaderElection implements manager.LeaderElectionRunnable.  Every replica admits Pods.
+func (p *PodTraceparentInjector) NeedLeaderElection() bool {
+	return false
+}
+
+// processNextRepair repairs the next pending Pods, and requeues them until they are found or the attempts are
+// exhausted.  It reports false when the queue is shut down.
+func (p *PodTraceparentInjector) processNextRepair(ctx context.Context) bool {
+	pending, shutdown := p.queue.Get()
+	if shutdown {
+		return false
+	}
+	defer p.queue.Done(pending)
+
+	repaired, err := p.repair(ctx, pending)
+	if (err != nil || !repaired) && p.queue.NumRequeues(pending) < repairAttempts {
+		p.queue.AddRateLimited(pending)
+		return true
+	}
+	p.queue.Forget(pending)
+	return true
+}
+
+// repair patches the trace of the owner of pending into its Pods admitted without it, and reports whether it is
+// done: the Pods were found, or the owner is gone or not traced.
+func (p *PodTraceparentInjector) repair(ctx context.Context, pending pendingPods) (bool, error) {
+	groupVersion, err := schema.ParseGroupVersion(pending.apiVersion)
+	if err != nil {
+		return true, err
+	}
+	owner := &metav1.PartialObjectMetadata{}
+	owner.SetGroupVersionKind(groupVersion.WithKind(pending.kind))
+	if err := p.reader.Get(ctx, client.ObjectKey{Namespace: pending.namespace, Name: pending.name}, owner); err != nil {
+		return apierrors.IsNotFound(err), client.IgnoreNotFound(err)
+	}
+	spanContext := p.options.spanContext(owner)
+	if !spanContext.IsValid() {
+		return true, nil
+	}
+
+	pods := &corev1.PodList{}
+	if err := p.reader.List(ctx, pods, client.InNamespace(pending.namespace)); err != nil {
+		return false, err
+	}
+	repaired := false
+	var errs []error
+	for i := range pods.Items {
+		pod := &pods.Items[i]
+		ownerRef := metav1.GetControllerOf(pod)
+		if ownerRef == nil || ownerRef.UID != pending.uid || ownerRef.Name != pending.name ||
+			pod.CreationTimestamp.Time.Before(pending.admitted) || p.options.spanContext(pod).IsValid() {
+			continue
+		}
+		repaired = true
+
+		patch := client.MergeFrom(pod.DeepCopy())
+		if pod.Annotations == nil {
+			pod.Annotations = map[string]string{}
+		}
+		pod.Annotations[p.options.traceIDKey] = spanContext.TraceID().String()
+		pod.Annotations[p.options.spanIDKey] = spanContext.SpanID().String()
+		// the actor of the request was only a stand-in for the one of the owner
+		if pod.Annotations[p.options.actorKey] == pending.username {
+			delete(pod.Annotations, p.options.actorKey)
+		}
+		p.options.addActorAnnotation(pod, owner, pending.username)
+		if err := p.Writer.Patch(ctx, pod, patch); err != nil {
+			errs = append(errs, fmt.Errorf("problem patching the trace of %s: %w", pod.Name, err))
+		}
+	}
+	return repaired && len(errs) == 0, errors.Join(errs...)
+}
+
+// spanContextForPod reads the trace of the Pod, or of its controller owner if the Pod has none, and returns the
+// object it was read from.  The read of the owner is bounded by ownerLookupTimeout and the deadline of ctx.
+func (p *PodTraceparentInjector) spanContextForPod(ctx context.Context, namespace string, pod *corev1.Pod) (trace.SpanContext, metav1.Object, error) {
+	if spanContext := p.options.spanContext(pod); spanContext.IsValid() {
+		return spanContext, pod, nil
+	}
+	err := kubetracer.ErrNoTraceContext
+	if p.reader == nil {
+		return trace.SpanContext{}, nil, err
+	}
+
+	ownerRef := metav1.GetControllerOf(pod)
+	if ownerRef == nil {
+		return trace.SpanContext{}, nil, err
+	}
+	groupVersion, parseErr := schema.ParseGroupVersion(ownerRef.APIVersion)
+	if parseErr != nil {
+		return trace.SpanContext{}, nil, parseErr
+	}
+
+	timeout := ownerLookupTimeout
+	if deadline, ok := ctx.Deadline(); ok {
+		timeout = min(timeout, time.Until(deadline)-responseMargin)
+	}
+	if timeout <= 0 {
+		return trace.SpanContext{}, nil, context.DeadlineExceeded
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	// the Pod namespace may not be set yet at admission
+	owner := &metav1.PartialObjectMetadata{}
+	owner.SetGroupVersionKind(groupVersion.WithKind(ownerRef.Kind))
+	if getErr := p.reader.Get(ctx, client.ObjectKey{Namespace: namespace, Name: ownerRef.Name}, owner); getErr != nil {
+		if ctx.Err() != nil {
+			// the error of the reader may not wrap the one of the context
+			return trace.SpanContext{}, nil, ctx.Err()
+		}
+		return trace.SpanContext{}, nil, getErr
+	}
+	spanContext := p.options.spanContext(owner)
+	if !spanContext.IsValid() {
+		return spanContext, nil, err
+	}
+	return spanContext, owner, nil
+}
+
+// addActorAnnotation gives the Pod the actor of source, the object its trace was read from if any, or else
+// username, unless the Pod has an actor already
+func (o *options) addActorAnnotation(pod *corev1.Pod, source metav1.Object, username string) {
+	if pod.Annotations[o.actorKey] != "" {
+		return
+	}
+	actor := ""
+	if source != nil {
+		actor = source.GetAnnotations()[o.actorKey]
+	}
+	if actor == "" {
+		actor = username
+	}
+	if actor == "" {
+		return
+	}
+	if pod.Annotations == nil {
+		pod.Annotations = map[string]string{}
+	}
+	pod.Annotations[o.actorKey] = actor
+}
+
+// injectEnv appends env to the container unless it already sets TRACEPARENT
+func injectEnv(container *corev1.Container, env []corev1.EnvVar) {
+	for _, envVar := range container.Env {
+		if envVar.Name == TraceparentEnv {
+			return
+		}
+	}
+	container.Env = append(container.Env, env...)
+}
@@ -0,0 +1,314 @@
+package webhook_test
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/kubetracer/kubetracer-go/pkg/constants"
+	webhook "github.com/kubetracer/kubetracer-go/pkg/webhooks"
+	"github.com/stretchr/testify/assert"
+	admissionv1 "k8s.io/api/admission/v1"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+)
+
+func admissionRequest(t *testing.T, pod *corev1.Pod) admission.Request {
+	raw, err := json.Marshal(pod)
+	assert.NoError(t, err)
+	return admission.Request{
+		AdmissionRequest: admissionv1.AdmissionRequest{
+			Operation: admissionv1.Create,
+			Namespace: "default",
+			Object:    runtime.RawExtension{Raw: raw},
+		},
+	}
+}
+
+// slowReader is a client.Reader which answers when its context is done
+type slowReader struct {
+	client.Reader
+}
+
+func (r slowReader) Get(ctx context.Context, _ client.ObjectKey, _ client.Object, _ ...client.GetOption) error {
+	<-ctx.Done()
+	return ctx.Err()
+}
+
+// slowOnceReader is a client.Reader whose first Get answers when its context is done
+type slowOnceReader struct {
+	client.Reader
+	slowed bool
+}
+
+func (r *slowOnceReader) Get(ctx context.Context, key client.ObjectKey, obj client.Object, opts ...client.GetOption) error {
+	if !r.slowed {
+		r.slowed = true
+		<-ctx.Done()
+		return ctx.Err()
+	}
+	return r.Reader.Get(ctx, key, obj, opts...)
+}
+
+func TestPodTraceparentInjector(t *testing.T) {
+	isController := true
+
+	t.Run("pod with trace", func(t *testing.T) {
+		pod := &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: "test-pod",
+				Annotations: map[string]string{
+					constants.TraceIDAnnotation: "f620f5cad0af940c294f980c5366a6a1",
+					constants.SpanIDAnnotation:  "45f359cdc1c8ab06",
+				},
+			},
+			Spec: corev1.PodSpec{
+				Containers: []corev1.Container{
+					{Name: "app"},
+					{Name: "sidecar", Env: []corev1.EnvVar{{Name: webhook.TraceparentEnv, Value: "custom"}}},
+				},
+			},
+		}
+
+		h := webhook.NewPodTraceparentInjector(clientgoscheme.Scheme, nil)
+		resp := h.Handle(context.Background(), admissionRequest(t, pod))
+
+		assert.True(t, resp.Allowed)
+		assert.Len(t, resp.Patches, 1)
+		assert.Equal(t, "/spec/containers/0/env", resp.Patches[0].Path)
+		assert.Equal(t, []interface{}{map[string]interface{}{
+			"name":  webhook.TraceparentEnv,
+			"value": "00-f620f5cad0af940c294f980c5366a6a1-45f359cdc1c8ab06-01",
+		}}, resp.Patches[0].Value)
+	})
+
+	t.Run("trace from owner", func(t *testing.T) {
+		replicaSet := &appsv1.ReplicaSet{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "test-replicaset",
+				Namespace: "default",
+				Annotations: map[string]string{
+					constants.TraceIDAnnotation: "f620f5cad0af940c294f980c5366a6a1",
+					constants.SpanIDAnnotation:  "45f359cdc1c8ab06",
+				},
+			},
+		}
+		reader := fake.NewClientBuilder().WithObjects(replicaSet).Build()
+
+		pod := &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{
+				GenerateName: "test-replicaset-",
+				OwnerReferences: []metav1.OwnerReference{{
+					APIVersion: "apps/v1",
+					Kind:       "ReplicaSet",
+					Name:       "test-replicaset",
+					Controller: &isController,
+				}},
+			},
+			Spec: corev1.PodSpec{Containers: []corev1.Container{{Name: "app"}}},
+		}
+
+		h := webhook.NewPodTraceparentInjector(clientgoscheme.Scheme, reader)
+		resp := h.Handle(context.Background(), admissionRequest(t, pod))
+
+		assert.True(t, resp.Allowed)
+		assert.Len(t, resp.Patches, 1)
+	})
+
+	t.Run("actor of the request", func(t *testing.T) {
+		pod := &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: "test-pod",
+				Annotations: map[string]string{
+					constants.TraceIDAnnotation: "f620f5cad0af940c294f980c5366a6a1",
+					constants.SpanIDAnnotation:  "45f359cdc1c8ab06",
+				},
+			},
+			Spec: corev1.PodSpec{Containers: []corev1.Container{{Name: "app"}}},
+		}
+		req := admissionRequest(t, pod)
+		req.UserInfo.Username = "alice"
+
+		h := webhook.NewPodTraceparentInjector(clientgoscheme.Scheme, nil)
+		resp := h.Handle(context.Background(), req)
+
+		assert.True(t, resp.Allowed)
+		assert.Len(t, resp.Patches, 2)
+		actors := map[string]interface{}{}
+		for _, patch := range resp.Patches {
+			if patch.Path == "/metadata/annotations/kubetracer.io~1actor" {
+				actors[patch.Operation] = patch.Value
+			}
+		}
+		assert.Equal(t, map[string]interface{}{"add": "alice"}, actors)
+	})
+
+	t.Run("custom annotations", func(t *testing.T) {
+		pod := &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: "test-pod",
+				Annotations: map[string]string{
+					"example.com/trace-id": "f620f5cad0af940c294f980c5366a6a1",
+					"example.com/span-id":  "45f359cdc1c8ab06",
+				},
+			},
+			Spec: corev1.PodSpec{Containers: []corev1.Container{{Name: "app"}}},
+		}
+		req := admissionRequest(t, pod)
+		req.UserInfo.Username = "alice"
+
+		h := webhook.NewPodTraceparentInjector(clientgoscheme.Scheme, nil, webhook.WithAnnotationPrefix("example.com/"))
+		resp := h.Handle(context.Background(), req)
+
+		assert.True(t, resp.Allowed)
+		paths := []string{}
+		for _, patch := range resp.Patches {
+			paths = append(paths, patch.Path)
+		}
+		assert.ElementsMatch(t, []string{"/spec/containers/0/env", "/metadata/annotations/example.com~1actor"}, paths)
+	})
+
+	t.Run("pod without trace", func(t *testing.T) {
+		pod := &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: "test-pod"},
+			Spec:       corev1.PodSpec{Containers: []corev1.Container{{Name: "app"}}},
+		}
+
+		h := webhook.NewPodTraceparentInjector(clientgoscheme.Scheme, fake.NewClientBuilder().Build())
+		resp := h.Handle(context.Background(), admissionRequest(t, pod))
+
+		assert.True(t, resp.Allowed)
+		assert.Empty(t, resp.Patches)
+	})
+	t.Run("owner lookup bounded by the admission deadline", func(t *testing.T) {
+		pod := &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{
+				GenerateName: "test-replicaset-",
+				OwnerReferences: []metav1.OwnerReference{{
+					APIVersion: "apps/v1",
+					Kind:       "ReplicaSet",
+					Name:       "test-replicaset",
+					Controller: &isController,
+				}},
+			},
+			Spec: corev1.PodSpec{Containers: []corev1.Container{{Name: "app"}}},
+		}
+		h := webhook.NewPodTraceparentInjector(clientgoscheme.Scheme, slowReader{})
+
+		ctx, cancel := context.WithTimeout(context.Background(), 300*time.Millisecond)
+		defer cancel()
+		start := time.Now()
+		resp := h.Handle(ctx, admissionRequest(t, pod))
+
+		assert.Less(t, time.Since(start), 300*time.Millisecond)
+		assert.True(t, resp.Allowed)
+		assert.Empty(t, resp.Patches)
+
+		// no time is left for the lookup
+		ctx, cancel = context.WithTimeout(context.Background(), 50*time.Millisecond)
+		defer cancel()
+		resp = h.Handle(ctx, admissionRequest(t, pod))
+		assert.True(t, resp.Allowed)
+		assert.Empty(t, resp.Patches)
+	})
+
+	t.Run("actor kept at the admission deadline", func(t *testing.T) {
+		pod := &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{
+				GenerateName: "test-replicaset-",
+				OwnerReferences: []metav1.OwnerReference{{
+					APIVersion: "apps/v1",
+					Kind:       "ReplicaSet",
+					Name:       "test-replicaset",
+					Controller: &isController,
+				}},
+			},
+			Spec: corev1.PodSpec{Containers: []corev1.Container{{Name: "app"}}},
+		}
+		req := admissionRequest(t, pod)
+		req.UserInfo.Username = "alice"
+		h := webhook.NewPodTraceparentInjector(clientgoscheme.Scheme, slowReader{})
+
+		ctx, cancel := context.WithTimeout(context.Background(), 300*time.Millisecond)
+		defer cancel()
+		resp := h.Handle(ctx, req)
+
+		assert.True(t, resp.Allowed)
+		assert.Len(t, resp.Patches, 1)
+		assert.Equal(t, "/metadata/annotations", resp.Patches[0].Path)
+		assert.Equal(t, map[string]interface{}{constants.ActorAnnotation: "alice"}, resp.Patches[0].Value)
+	})
+}
+
+func TestPodTraceparentInjectorRepair(t *testing.T) {
+	isController := true
+	replicaSet := &appsv1.ReplicaSet{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "test-replicaset",
+			Namespace: "default",
+			UID:       "replicaset-uid",
+			Annotations: map[string]string{
+				constants.TraceIDAnnotation: "f620f5cad0af940c294f980c5366a6a1",
+				constants.SpanIDAnnotation:  "45f359cdc1c8ab06",
+				constants.ActorAnnotation:   "bob",
+			},
+		},
+	}
+	ownerReferences := []metav1.OwnerReference{{
+		APIVersion: "apps/v1",
+		Kind:       "ReplicaSet",
+		Name:       "test-replicaset",
+		UID:        "replicaset-uid",
+		Controller: &isController,
+	}}
+	admitted := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{GenerateName: "test-replicaset-", OwnerReferences: ownerReferences},
+		Spec:       corev1.PodSpec{Containers: []corev1.Container{{Name: "app"}}},
+	}
+	req := admissionRequest(t, admitted)
+	req.UserInfo.Username = "alice"
+
+	k8sClient := fake.NewClientBuilder().WithObjects(replicaSet).Build()
+	h := webhook.NewPodTraceparentInjector(clientgoscheme.Scheme, &slowOnceReader{Reader: k8sClient})
+	h.Writer = k8sClient
+
+	ctx, cancel := context.WithTimeout(context.Background(), 300*time.Millisecond)
+	defer cancel()
+	resp := h.Handle(ctx, req)
+	assert.True(t, resp.Allowed)
+
+	// the Pod is created with the actor of the request only
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:              "test-replicaset-abcde",
+			Namespace:         "default",
+			OwnerReferences:   ownerReferences,
+			CreationTimestamp: metav1.Now(),
+			Annotations:       map[string]string{constants.ActorAnnotation: "alice"},
+		},
+		Spec: corev1.PodSpec{Containers: []corev1.Container{{Name: "app"}}},
+	}
+	assert.NoError(t, k8sClient.Create(context.Background(), pod))
+
+	runCtx, stop := context.WithCancel(context.Background())
+	defer stop()
+	go h.Start(runCtx)
+
+	assert.Eventually(t, func() bool {
+		repaired := &corev1.Pod{}
+		if err := k8sClient.Get(context.Background(), client.ObjectKeyFromObject(pod), repaired); err != nil {
+			return false
+		}
+		return repaired.Annotations[constants.TraceIDAnnotation] == "f620f5cad0af940c294f980c5366a6a1" &&
+			repaired.Annotations[constants.SpanIDAnnotation] == "45f359cdc1c8ab06" &&
+			repaired.Annotations[constants.ActorAnnotation] == "bob"
+	}, 5*time.Second, 50*time.Millisecond)
+	assert.False(t, h.NeedLeaderElection())
+}